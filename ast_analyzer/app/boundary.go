@@ -0,0 +1,50 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+)
+
+// Boundary は再帰的に解析する範囲を表す
+// 範囲外の型はInputNodeとして報告され、解析はそこで止まる
+type Boundary struct {
+	FollowStdlib     bool     // 標準ライブラリの型も再帰的に解析するか
+	FollowThirdParty bool     // サードパーティの型も再帰的に解析するか
+	LocalPrefixes    []string // メインモジュール外でもモジュール内として扱うパッケージパスのプレフィックス
+}
+
+// DefaultBoundary はメインモジュール内の型のみを再帰的に解析する範囲を返す
+func DefaultBoundary() Boundary {
+	return Boundary{}
+}
+
+// Follows は型を再帰的に解析するかどうかを判定する
+func (b Boundary) Follows(origin packages.TypeOrigin, packagePath string) bool {
+	switch origin {
+	case packages.OriginModuleLocal:
+		return true
+	case packages.OriginStdlib:
+		return b.FollowStdlib
+	case packages.OriginThirdParty:
+		if b.FollowThirdParty {
+			return true
+		}
+		for _, prefix := range b.LocalPrefixes {
+			if packagePath == prefix || strings.HasPrefix(packagePath, strings.TrimSuffix(prefix, "/")+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Option はWireAnalyzerの設定を変更する
+type Option func(*WireAnalyzer)
+
+// WithBoundary は再帰的に解析する範囲を設定する
+func WithBoundary(boundary Boundary) Option {
+	return func(wa *WireAnalyzer) {
+		wa.boundary = boundary
+	}
+}
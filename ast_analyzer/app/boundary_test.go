@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+)
+
+func TestBoundary_Follows(t *testing.T) {
+	tests := []struct {
+		name        string
+		boundary    Boundary
+		origin      packages.TypeOrigin
+		packagePath string
+		want        bool
+	}{
+		{"モジュール内は常に解析", DefaultBoundary(), packages.OriginModuleLocal, "example.com/app", true},
+		{"基本型は解析しない", Boundary{FollowStdlib: true, FollowThirdParty: true}, packages.OriginBasic, "", false},
+		{"デフォルトでは標準ライブラリで止まる", DefaultBoundary(), packages.OriginStdlib, "net/http", false},
+		{"標準ライブラリを解析", Boundary{FollowStdlib: true}, packages.OriginStdlib, "net/http", true},
+		{"デフォルトではサードパーティで止まる", DefaultBoundary(), packages.OriginThirdParty, "example.com/lib", false},
+		{"サードパーティを解析", Boundary{FollowThirdParty: true}, packages.OriginThirdParty, "example.com/lib", true},
+		{"プレフィックスに一致", Boundary{LocalPrefixes: []string{"example.com/lib"}}, packages.OriginThirdParty, "example.com/lib/sub", true},
+		{"プレフィックスの途中までの一致は対象外", Boundary{LocalPrefixes: []string{"example.com/lib"}}, packages.OriginThirdParty, "example.com/library", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.boundary.Follows(tt.origin, tt.packagePath); got != tt.want {
+				t.Errorf("Follows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWireAnalyzer_AnalyzeStruct_ExternalInputs(t *testing.T) {
+	analyzer := NewWireAnalyzer("../../testdata/externals", "./...")
+
+	result, err := analyzer.analyzeStruct(context.Background(), "example.com/externals", "Service")
+	if err != nil {
+		t.Fatalf("analyzeStruct failed: %v", err)
+	}
+
+	printStructAnalysis(t, result, 0)
+
+	fields := make(map[string]FieldNode)
+	for _, field := range result.Fields {
+		fields[field.GetFieldName()] = field
+	}
+
+	tests := []struct {
+		fieldName  string
+		wantOrigin packages.TypeOrigin
+		wantInits  []string
+	}{
+		{"ctx", packages.OriginStdlib, nil},
+		{"db", packages.OriginStdlib, []string{"NewDB"}},
+		{"client", packages.OriginStdlib, []string{"NewHTTPClient"}},
+		{"out", packages.OriginStdlib, nil},
+		{"timeout", packages.OriginStdlib, nil},
+		{"name", packages.OriginBasic, nil},
+		{"err", packages.OriginBasic, nil},
+		{"dsn", packages.OriginModuleLocal, nil},
+		{"vendor", packages.OriginThirdParty, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fieldName, func(t *testing.T) {
+			input, ok := fields[tt.fieldName].(*InputNode)
+			if !ok {
+				t.Fatalf("field %s is not an InputNode: %#v", tt.fieldName, fields[tt.fieldName])
+			}
+			if input.Origin != tt.wantOrigin {
+				t.Errorf("Origin = %v, want %v", input.Origin, tt.wantOrigin)
+			}
+			if len(input.InitFunctions) != len(tt.wantInits) {
+				t.Fatalf("InitFunctions = %+v, want %v", input.InitFunctions, tt.wantInits)
+			}
+			for i, initFunc := range input.InitFunctions {
+				if initFunc.Name != tt.wantInits[i] {
+					t.Errorf("InitFunctions[%d] = %s, want %s", i, initFunc.Name, tt.wantInits[i])
+				}
+			}
+		})
+	}
+
+	// モジュール内の構造体は再帰的に解析される
+	if _, ok := fields["settings"].(*StructNode); !ok {
+		t.Errorf("settings should be analyzed as StructNode: %#v", fields["settings"])
+	}
+}
+
+func TestWireAnalyzer_AnalyzeStruct_LocalPrefixes(t *testing.T) {
+	analyzer := NewWireAnalyzer("../../testdata/externals", "./...", WithBoundary(Boundary{
+		LocalPrefixes: []string{"example.com/vendorlib"},
+	}))
+
+	result, err := analyzer.analyzeStruct(context.Background(), "example.com/externals", "Service")
+	if err != nil {
+		t.Fatalf("analyzeStruct failed: %v", err)
+	}
+
+	for _, field := range result.Fields {
+		if field.GetFieldName() != "vendor" {
+			continue
+		}
+		vendor, ok := field.(*StructNode)
+		if !ok {
+			t.Fatalf("vendor should be analyzed as StructNode: %#v", field)
+		}
+		if vendor.PackagePath != "example.com/vendorlib" {
+			t.Errorf("PackagePath = %s", vendor.PackagePath)
+		}
+		return
+	}
+
+	t.Fatal("vendor field not found")
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/token"
+)
+
+// ErrCancelled は解析がctxの取り消しやタイムアウトで中断されたことを表す
+// Contextを受け取るメソッドは、中断されるまでに解析した部分的な結果と一緒にこのエラーを返す
+// errors.Isでcontext.Canceledやcontext.DeadlineExceededとも比較できる
+var ErrCancelled = errors.New("analysis cancelled")
+
+// cancelledReason は中断されたため解析していないノードのSkipReason
+const cancelledReason = "cancelled"
+
+// newCancelledDiagnostic は中断されたため解析していないことを表す診断を作成する
+func newCancelledDiagnostic(pos token.Position, types ...TypeRef) *Diagnostic {
+	return newDiagnostic(CodeCancelled, pos, cancelledReason, types...)
+}
+
+// wrapCancelled はctxが取り消されている場合に、errの代わりにErrCancelledを返す
+// go/packagesは取り消しのエラーを文字列として含めるだけで、errors.Isで判定できないため
+func wrapCancelled(ctx context.Context, err error) error {
+	if ctx.Err() == nil {
+		return err
+	}
+	return cancelledError(ctx)
+}
+
+// cancelledError はctxのエラーを包んだErrCancelledを返す
+func cancelledError(ctx context.Context) error {
+	return fmt.Errorf("%w: %w", ErrCancelled, ctx.Err())
+}
+
+// checkCancelled はctxが取り消されている場合にErrCancelledを返す（treeMuを確保して呼ぶ）
+// 組み立て途中のノードを次の解析で使わないよう、解析済みの構造体のキャッシュを捨てる
+func (wa *WireAnalyzer) checkCancelled(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	wa.analyzed = make(map[string]*StructNode)
+	wa.analyzing = make(map[string][]*StructNode)
+	return cancelledError(ctx)
+}
+
+// newCancelledStruct は中断されたため解析していない構造体のノードを作成する
+func newCancelledStruct(fieldName, structName, packagePath string, pos token.Position) *StructNode {
+	ref := TypeRef{TypeName: structName, PackagePath: packagePath, IsPointer: true}
+	node := newSkippedStruct(fieldName, structName, packagePath, newCancelledDiagnostic(pos, ref))
+	node.Cancelled = true
+	return node
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+)
+
+func TestWireAnalyzer_AnalyzeInjectorsContext_Cancelled(t *testing.T) {
+	workDir := "../../sample/basic"
+	wireFilePath := "../../sample/basic/wire.go"
+
+	want, err := NewWireAnalyzer(workDir, "./...").AnalyzeInjectors(wireFilePath)
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+
+	analyzer := NewWireAnalyzer(workDir, "./...")
+
+	// 取り消し済みのctxではErrCancelledとctxのエラーの両方として判定できる
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := analyzer.AnalyzeInjectorsContext(ctx, wireFilePath); !errors.Is(err, ErrCancelled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("AnalyzeInjectorsContext with cancelled ctx error = %v, want ErrCancelled and context.Canceled", err)
+	}
+
+	// 途中でタイムアウトしても、中断されなかった部分の結果は変わらず、後の解析にも影響しない
+	for _, timeout := range []time.Duration{time.Millisecond, 50 * time.Millisecond, 500 * time.Millisecond} {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		injectors, err := analyzer.AnalyzeInjectorsContext(ctx, wireFilePath)
+		cancel()
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("AnalyzeInjectorsContext with timeout %v error = %v, want context.DeadlineExceeded", timeout, err)
+		}
+		for _, injector := range injectors {
+			if injector.Cancelled && injector.Graph != nil {
+				t.Errorf("cancelled injector %s has a graph", injector.Name)
+			}
+		}
+	}
+
+	got, err := analyzer.AnalyzeInjectorsContext(context.Background(), wireFilePath)
+	if err != nil {
+		t.Fatalf("AnalyzeInjectorsContext after cancellation failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AnalyzeInjectorsContext after cancellation differs from a fresh analysis")
+	}
+}
+
+func TestWireAnalyzer_AnalyzeField_Cancelled(t *testing.T) {
+	workDir := "../../sample/basic"
+	analyzer := NewWireAnalyzer(workDir, "./...")
+
+	fieldsInfo, err := packages.ExtractStructFields(workDir, "github.com/rmocchy/convinient_wire/sample/basic/handler", "UserHandler")
+	if err != nil {
+		t.Fatalf("ExtractStructFields failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var interfaces int
+	for _, field := range fieldsInfo.Fields {
+		if !field.IsInterface {
+			continue
+		}
+		interfaces++
+
+		// 中断されたフィールドは理由を失敗と区別できるようにする
+		node, ok := analyzer.analyzeField(ctx, field).(*InterfaceNode)
+		if !ok {
+			t.Fatalf("analyzeField(%s) is not an interface node", field.Name)
+		}
+		if !node.Cancelled || !node.Skipped || node.SkipReason != cancelledReason || node.Diagnostic == nil || node.Diagnostic.Code != CodeCancelled {
+			t.Errorf("analyzeField(%s) = %+v, want cancelled", field.Name, node)
+		}
+	}
+	if interfaces == 0 {
+		t.Fatal("UserHandler has no interface fields")
+	}
+
+	node, err := analyzer.analyzeStruct(ctx, "github.com/rmocchy/convinient_wire/sample/basic/handler", "UserHandler")
+	if node != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("analyzeStruct with cancelled ctx = %v, %v, want nil, context.Canceled", node, err)
+	}
+}
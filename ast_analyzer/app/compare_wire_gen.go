@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+
+	file "github.com/rmocchy/convinient_wire/ast_analyzer/files"
+)
+
+// MismatchKind はwire_gen.goと解析結果の食い違いの種類
+type MismatchKind string
+
+const (
+	MismatchNotConstructed MismatchKind = "not-constructed" // ツリー上の型を作る呼び出しがwire_gen.goにない
+	MismatchNotInTree      MismatchKind = "not-in-tree"     // wire_gen.goの呼び出しがツリー上のどの型にも対応しない
+	MismatchUnlinked       MismatchKind = "unlinked"        // フィールドの値が親の提供関数に渡されていない
+)
+
+// GraphMismatch はwire_gen.goの呼び出しグラフとStructNodeのツリーの食い違いを表す
+type GraphMismatch struct {
+	Kind   MismatchKind // 食い違いの種類
+	Path   string       // ツリー上のパス（例: "ControllerSet.handler.service"）
+	Func   string       // 対象の提供関数
+	Detail string       // 補足情報
+}
+
+// String は食い違いを1行の文字列にする
+func (m GraphMismatch) String() string {
+	s := string(m.Kind)
+	if m.Path != "" {
+		s += " " + m.Path
+	}
+	if m.Func != "" {
+		s += " " + path.Base(m.Func)
+	}
+	if m.Detail != "" {
+		s += " (" + m.Detail + ")"
+	}
+	return s
+}
+
+// CompareWireGen はwire_gen.goから読み取った注入関数の呼び出しグラフと、解析したStructNodeのツリーを比較する
+// ツリー上の各型がいずれかの提供関数（または構造体リテラル）で作られ、その値が親の提供関数に渡されているかを確認する
+func (wa *WireAnalyzer) CompareWireGen(injector *InjectorInfo, body file.InjectorBody) []GraphMismatch {
+	c := &wireGenComparer{
+		wa:       wa,
+		pkgPath:  injector.PackagePath,
+		calls:    make(map[string]*file.ProviderCall, len(body.Calls)),
+		used:     make(map[string]bool),
+		visiting: make(map[FieldNode]bool),
+	}
+	for i := range body.Calls {
+		c.calls[body.Calls[i].Func] = &body.Calls[i]
+	}
+
+	c.walk(injector.Root, injector.Root.StructName, nil)
+
+	// ツリーのどの型にも対応しない呼び出し
+	for _, call := range body.Calls {
+		if !c.used[call.Func] {
+			c.mismatches = append(c.mismatches, GraphMismatch{Kind: MismatchNotInTree, Func: call.Func})
+		}
+	}
+
+	return c.mismatches
+}
+
+// wireGenComparer はCompareWireGenの状態を保持する
+type wireGenComparer struct {
+	wa         *WireAnalyzer
+	pkgPath    string                        // wire.goのパッケージパス
+	calls      map[string]*file.ProviderCall // 関数名 -> 呼び出し
+	used       map[string]bool               // ツリー上の型に対応した呼び出し
+	visiting   map[FieldNode]bool            // 辿っている途中のノード（循環防止）
+	mismatches []GraphMismatch
+}
+
+// walk はツリーを辿り、ノードを作る呼び出しと親の呼び出しへの受け渡しを確認する
+func (c *wireGenComparer) walk(node FieldNode, nodePath string, parent *file.ProviderCall) {
+	if c.visiting[node] {
+		return
+	}
+	c.visiting[node] = true
+	defer delete(c.visiting, node)
+
+	var call *file.ProviderCall
+	var fields []FieldNode
+
+	switch n := node.(type) {
+	case *StructNode:
+		if n.Skipped {
+			return
+		}
+		call = c.findCall(n.InitFunctions)
+		if call == nil {
+			// 提供関数がない構造体はwire.Structの構造体リテラルで作られる
+			call = c.findLiteral(n.PackagePath, n.StructName)
+		}
+		fields = n.Fields
+
+	case *InterfaceNode:
+		if providers, err := c.wa.findTypeProviders(context.Background(), n.PackagePath, n.TypeName); err == nil {
+			call = c.findCall(providers)
+		}
+		if n.ResolvedStruct != nil {
+			if call == nil {
+				call = c.findCall(n.ResolvedStruct.InitFunctions)
+			}
+			fields = n.ResolvedStruct.Fields
+		} else if call == nil {
+			// 実装型が解決できないインターフェースはツリーから判断できない
+			return
+		}
+
+	default:
+		// 入力やコレクションは注入関数の引数などから渡されるため比較しない
+		return
+	}
+
+	if call == nil {
+		c.mismatches = append(c.mismatches, GraphMismatch{Kind: MismatchNotConstructed, Path: nodePath})
+	} else {
+		c.used[call.Func] = true
+		if parent != nil && !slices.Contains(parent.ArgVars, call.Var) {
+			c.mismatches = append(c.mismatches, GraphMismatch{
+				Kind:   MismatchUnlinked,
+				Path:   nodePath,
+				Func:   call.Func,
+				Detail: fmt.Sprintf("%s is not passed to %s", call.Var, path.Base(parent.Func)),
+			})
+		}
+	}
+
+	for _, field := range fields {
+		c.walk(field, nodePath+"."+field.GetFieldName(), call)
+	}
+}
+
+// findCall は初期化関数のうちwire_gen.goで呼び出されているものを探す
+func (c *wireGenComparer) findCall(initFuncs []InitFunctionInfo) *file.ProviderCall {
+	for _, fn := range initFuncs {
+		if call, ok := c.calls[c.qualify(fn.PackagePath, fn.Name)]; ok && !call.IsStruct {
+			return call
+		}
+	}
+	return nil
+}
+
+// findLiteral は構造体リテラルで作られている場合にその呼び出しを返す
+func (c *wireGenComparer) findLiteral(pkgPath, structName string) *file.ProviderCall {
+	if call, ok := c.calls[c.qualify(pkgPath, structName)]; ok && call.IsStruct {
+		return call
+	}
+	return nil
+}
+
+// qualify はwire_gen.goから参照する場合の名前を返す（同じパッケージの場合は名前のみ）
+func (c *wireGenComparer) qualify(pkgPath, name string) string {
+	if pkgPath == c.pkgPath {
+		return name
+	}
+	return pkgPath + "." + name
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	file "github.com/rmocchy/convinient_wire/ast_analyzer/files"
+)
+
+func TestWireAnalyzer_CompareWireGen(t *testing.T) {
+	analyzer := NewWireAnalyzer("../../sample/basic", "./...")
+	injectors, err := analyzer.AnalyzeInjectors("../../sample/basic/wire.go")
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+
+	src, err := os.ReadFile("../../sample/basic/wire_gen.go")
+	if err != nil {
+		t.Fatalf("failed to read wire_gen.go: %v", err)
+	}
+
+	const base = "github.com/rmocchy/convinient_wire/sample/basic/"
+
+	tests := []struct {
+		name     string
+		replacer *strings.Replacer
+		want     []GraphMismatch
+	}{
+		{
+			name:     "wireが生成したグラフはツリーと一致する",
+			replacer: strings.NewReplacer(),
+			want:     nil,
+		},
+		{
+			name:     "フィールドの値が親の提供関数に渡されていない",
+			replacer: strings.NewReplacer("NewUserHandler(userService)", "NewUserHandler(nil)"),
+			want: []GraphMismatch{
+				{
+					Kind:   MismatchUnlinked,
+					Path:   "ControllerSet.handler.service",
+					Func:   base + "service.NewUserService",
+					Detail: "userService is not passed to handler.NewUserHandler",
+				},
+			},
+		},
+		{
+			name:     "ツリー上の型が作られていない",
+			replacer: strings.NewReplacer("config := repository.NewConfig()", "config := newConfig()"),
+			want: []GraphMismatch{
+				{Kind: MismatchNotConstructed, Path: "ControllerSet.handler.service.repo.config"},
+				{Kind: MismatchNotInTree, Func: "newConfig"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			genFile, err := file.ParseWireGenFile("wire_gen.go", tt.replacer.Replace(string(src)))
+			if err != nil {
+				t.Fatalf("ParseWireGenFile failed: %v", err)
+			}
+
+			got := analyzer.CompareWireGen(injectors[0], genFile.Injectors[0])
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareWireGen() = %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
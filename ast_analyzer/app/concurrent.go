@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	file "github.com/rmocchy/convinient_wire/ast_analyzer/files"
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+)
+
+// WithConcurrency は型の情報の検索と注入関数ごとの依存グラフの組み立てを並行するワーカーの数を設定する（1未満の場合は1）
+// ツリーのノードは注入関数の間で共有するため、ツリーの組み立てだけはtreeMuで直列にする。既定値はGOMAXPROCS
+func WithConcurrency(workers int) Option {
+	return func(wa *WireAnalyzer) {
+		wa.workers = max(workers, 1)
+	}
+}
+
+// defaultWorkers はワーカーの数の既定値を返す
+func defaultWorkers() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// acquire はワーカーの枠を1つ確保し、解放する関数を返す（枠を待つ間にctxが取り消された場合はエラー）
+// 枠を確保したまま別の枠を待たないよう、枠を確保した処理の中ではacquireを呼ばない
+// パッケージの読み込みや実装型の検索の準備は枠を確保したまま行うため、その間は他の検索が待つことがある
+func (wa *WireAnalyzer) acquire(ctx context.Context) (release func(), err error) {
+	select {
+	case wa.sem <- struct{}{}:
+		return func() { <-wa.sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// prefetcher は注入関数のルートから辿れる型の情報を並行して調べる
+// 調べる処理はキューに積み、wa.workers個のワーカーだけが実行する（処理の中で積んだ処理も同じワーカーが実行する）
+// 調べた結果はWireAnalyzerのmemoに残り、ツリーはその結果から決まった順序で組み立てる
+type prefetcher struct {
+	ctx  context.Context
+	wa   *WireAnalyzer
+	seen sync.Map // 調べ始めた構造体のキー
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []func()
+	pending int // キューにある処理と実行中の処理の数
+}
+
+// prefetchRoots はwire.goの注入関数の返り値の構造体から辿れる型の情報を並行して調べ、全て終わるまで待つ
+// ctxが取り消されると新しい型を調べずに終わる
+func (wa *WireAnalyzer) prefetchRoots(ctx context.Context, functions []file.FunctionInfo, wirePkgPath string, targets map[string]bool) {
+	p := newPrefetcher(ctx, wa)
+	for _, funcInfo := range functions {
+		if targets != nil && !targets[funcInfo.Name] {
+			continue
+		}
+		for _, structInfo := range funcInfo.ReturnTypes {
+			packagePath := structInfo.PackagePath
+			if packagePath == "" {
+				packagePath = wirePkgPath
+			}
+			p.visitStruct(packagePath, structInfo.Name)
+		}
+	}
+	p.run(wa.workers)
+}
+
+// newPrefetcher はprefetcherを作成する
+func newPrefetcher(ctx context.Context, wa *WireAnalyzer) *prefetcher {
+	p := &prefetcher{ctx: ctx, wa: wa}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// submit は処理をキューに積む
+func (p *prefetcher) submit(task func()) {
+	p.mu.Lock()
+	p.queue = append(p.queue, task)
+	p.pending++
+	p.mu.Unlock()
+	p.cond.Signal()
+}
+
+// run はワーカーを起動し、キューが空になり全ての処理が終わるまで待つ
+func (p *prefetcher) run(workers int) {
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				task, ok := p.next()
+				if !ok {
+					return
+				}
+				task()
+				p.done()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// next はキューから処理を取り出す（全ての処理が終わった場合はokがfalse）
+func (p *prefetcher) next() (task func(), ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.queue) == 0 && p.pending > 0 {
+		p.cond.Wait()
+	}
+	if len(p.queue) == 0 {
+		return nil, false
+	}
+	task = p.queue[0]
+	p.queue = p.queue[1:]
+	return task, true
+}
+
+// done は処理が終わったことを記録し、最後の処理であれば待っているワーカーを終わらせる
+func (p *prefetcher) done() {
+	p.mu.Lock()
+	p.pending--
+	finished := p.pending == 0
+	p.mu.Unlock()
+	if finished {
+		p.cond.Broadcast()
+	}
+}
+
+// visitStruct は構造体のフィールドと初期化関数を調べ、フィールドの型を辿る
+func (p *prefetcher) visitStruct(packagePath, structName string) {
+	if p.ctx.Err() != nil {
+		return
+	}
+	if _, loaded := p.seen.LoadOrStore(structKey(packagePath, structName), true); loaded {
+		return
+	}
+
+	p.submit(func() {
+		_, _ = p.wa.findInitFunctions(p.ctx, packagePath, structName)
+		fieldsInfo, err := p.wa.extractStructFields(p.ctx, packagePath, structName)
+		if err != nil {
+			return
+		}
+		for _, field := range fieldsInfo.Fields {
+			p.visitField(field)
+		}
+	})
+}
+
+// visitField はanalyzeFieldと同じ条件でフィールドの型を辿る
+func (p *prefetcher) visitField(field packages.FieldInfo) {
+	if p.ctx.Err() != nil {
+		return
+	}
+	switch field.Kind {
+	case packages.FieldKindSlice, packages.FieldKindArray, packages.FieldKindMap,
+		packages.FieldKindFunc, packages.FieldKindChan:
+		return
+	}
+	if !p.wa.boundary.Follows(field.Origin, field.PackagePath) {
+		return
+	}
+
+	if field.IsInterface {
+		if _, ok := p.wa.lookupValue(typeRefFromField(field)); ok {
+			return
+		}
+		p.submit(func() {
+			refs, err := p.wa.findInterfaceReferences(p.ctx, field.TypeName, field.PackagePath)
+			if err == nil && len(refs) == 1 {
+				p.visitStruct(refs[0].ImplementingPkgPath, refs[0].ImplementingType)
+			}
+		})
+		return
+	}
+
+	if field.Kind == packages.FieldKindStruct {
+		p.visitStruct(field.PackagePath, field.TypeName)
+	}
+}
+
+// structKey は構造体を識別するキーを返す
+func structKey(packagePath, structName string) string {
+	if packagePath == "" {
+		return structName
+	}
+	return packagePath + "." + structName
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWireAnalyzer_Concurrency(t *testing.T) {
+	// 結果が実行順によらないことを確かめるには、複数のパッケージとインターフェースを含む小さいフィクスチャで足りる
+	tests := []struct {
+		name    string
+		workDir string
+	}{
+		{name: "multi", workDir: "../../testdata/multi"},
+		{name: "lsp", workDir: "../../testdata/lsp"},
+		{name: "pkgsets", workDir: "../../testdata/pkgsets"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wireFilePath := filepath.Join(tt.workDir, "wire.go")
+			want, err := NewWireAnalyzer(tt.workDir, "./...", WithConcurrency(1)).AnalyzeInjectors(wireFilePath)
+			if err != nil {
+				t.Fatalf("AnalyzeInjectors with 1 worker failed: %v", err)
+			}
+
+			// ワーカーの数や同時に呼び出した順序によらず結果は同じになる
+			analyzer := NewWireAnalyzer(tt.workDir, "./...", WithConcurrency(8))
+			var wg sync.WaitGroup
+			results := make([][]*InjectorInfo, 4)
+			errs := make([]error, len(results))
+			for i := range results {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					results[i], errs[i] = analyzer.AnalyzeInjectors(wireFilePath)
+				}()
+			}
+			wg.Wait()
+
+			for i, got := range results {
+				if errs[i] != nil {
+					t.Fatalf("AnalyzeInjectors with 8 workers failed: %v", errs[i])
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("AnalyzeInjectors with 8 workers (call %d) differs from 1 worker", i)
+				}
+			}
+		})
+	}
+}
+
+func TestPrefetcher_Run(t *testing.T) {
+	// 処理の中で積んだ処理も含めて、同時に実行するのはワーカーの数まで
+	const workers = 2
+	p := newPrefetcher(context.Background(), nil)
+	var running, peak, finished atomic.Int32
+	var task func(depth int) func()
+	task = func(depth int) func() {
+		return func() {
+			n := running.Add(1)
+			for {
+				old := peak.Load()
+				if n <= old || peak.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			if depth < 3 {
+				p.submit(task(depth + 1))
+				p.submit(task(depth + 1))
+			}
+			running.Add(-1)
+			finished.Add(1)
+		}
+	}
+	for range 4 {
+		p.submit(task(0))
+	}
+	p.run(workers)
+
+	if got, want := finished.Load(), int32(4*15); got != want {
+		t.Errorf("finished %d tasks, want %d", got, want)
+	}
+	if got := peak.Load(); got > workers {
+		t.Errorf("%d tasks ran at once, want at most %d", got, workers)
+	}
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TypeConflict は依存グラフが同じ名前のない型（例: string）の値を複数の意味で必要としている箇所を表す
+// wireは型で値を区別するため、名前付きの型を導入しないと正しく注入できない
+type TypeConflict struct {
+	Type        TypeRef               // 区別できない型
+	Uses        []TypeUse             // その型を必要とする引数
+	Suggestions []NamedTypeSuggestion // 導入を提案する名前付きの型
+}
+
+// TypeUse は提供関数の引数、またはwire.Structで埋める構造体のフィールドでの型の使用箇所を表す
+type TypeUse struct {
+	Function InitFunctionInfo // 提供関数（構造体のフィールドの場合はゼロ値）
+	Param    string           // 引数名（構造体のフィールドの場合はフィールド名）
+	Index    int              // 引数の位置（構造体のフィールドの場合はフィールドの位置）
+	Struct   TypeRef          // フィールドを持つ構造体（提供関数の引数の場合はゼロ値）
+}
+
+// IsField は構造体のフィールドでの使用箇所かどうかを判定する
+func (u TypeUse) IsField() bool {
+	return u.Struct.TypeName != ""
+}
+
+// PackagePath は使用箇所を宣言しているパッケージのパスを返す
+func (u TypeUse) PackagePath() string {
+	if u.IsField() {
+		return u.Struct.PackagePath
+	}
+	return u.Function.PackagePath
+}
+
+// NamedTypeSuggestion は衝突を解消するために導入する名前付きの型を表す
+type NamedTypeSuggestion struct {
+	Name        string    // 型名（例: DSN）
+	PackagePath string    // 型を宣言するパッケージ（引数を使う提供関数や、フィールドを持つ構造体のパッケージ）
+	Underlying  string    // 元の型（表示用にパッケージ名で修飾する。例: string）
+	Uses        []TypeUse // この型に置き換える引数
+}
+
+// Declaration は型宣言のソースを返す（例: "type DSN string"）
+func (s NamedTypeSuggestion) Declaration() string {
+	return "type " + s.Name + " " + s.Underlying
+}
+
+// detectConflicts は名前のない型を異なる名前の引数やフィールドで必要としている箇所を探す
+// wire.Structで埋めるルートのフィールド（例: DSN stringとAPIKey string）も、提供関数の引数と同じく1つの値を共有してしまう
+func (r *providerResolver) detectConflicts(root *StructNode) {
+	uses := make(map[string][]TypeUse)
+	refs := make(map[string]TypeRef)
+	var keys []string
+	addUse := func(ref TypeRef, use TypeUse) {
+		if ref.PackagePath != "" {
+			return
+		}
+		key := ref.Key()
+		if _, ok := uses[key]; !ok {
+			keys = append(keys, key)
+			refs[key] = ref
+		}
+		uses[key] = append(uses[key], use)
+	}
+
+	for _, provider := range r.graph.Providers {
+		for i, param := range provider.Function.Params {
+			addUse(typeRefFromField(param), TypeUse{Function: provider.Function, Param: param.Name, Index: i})
+		}
+	}
+	rootRef := typeRefFromNode(root)
+	for i, field := range r.graph.StructFields {
+		addUse(field.Type, TypeUse{Param: field.Name, Index: i, Struct: rootRef})
+	}
+
+	for _, key := range keys {
+		suggestions := suggestNamedTypes(refs[key], uses[key])
+		if len(suggestions) < 2 {
+			// 全ての引数が同じ意味であれば同じ値を渡してよい
+			continue
+		}
+		r.graph.Conflicts = append(r.graph.Conflicts, TypeConflict{
+			Type:        refs[key],
+			Uses:        uses[key],
+			Suggestions: suggestions,
+		})
+	}
+}
+
+// suggestNamedTypes は引数名ごとに名前付きの型を提案する（同じ名前の引数は同じ型にまとめる）
+func suggestNamedTypes(ref TypeRef, uses []TypeUse) []NamedTypeSuggestion {
+	var suggestions []NamedTypeSuggestion
+	for _, use := range uses {
+		name := namedTypeFor(use.Param)
+		if name == "" {
+			continue
+		}
+
+		found := false
+		for i := range suggestions {
+			if suggestions[i].Name == name && suggestions[i].PackagePath == use.PackagePath() {
+				suggestions[i].Uses = append(suggestions[i].Uses, use)
+				found = true
+				break
+			}
+		}
+		if !found {
+			suggestions = append(suggestions, NamedTypeSuggestion{
+				Name:        name,
+				PackagePath: use.PackagePath(),
+				Underlying:  ref.TypeString,
+				Uses:        []TypeUse{use},
+			})
+		}
+	}
+
+	// 異なる名前の型が1つしかなければ衝突ではない
+	names := make(map[string]bool)
+	for _, suggestion := range suggestions {
+		names[suggestion.Name] = true
+	}
+	if len(names) < 2 {
+		return nil
+	}
+	return suggestions
+}
+
+// commonInitialisms は型名で全て大文字にする略語
+var commonInitialisms = map[string]bool{
+	"API": true, "DB": true, "DNS": true, "DSN": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "SQL": true, "TCP": true, "TLS": true, "TTL": true,
+	"UDP": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// namedTypeFor は引数名から型名を作る（例: "dsn" -> "DSN", "apiKey" -> "APIKey"）
+func namedTypeFor(param string) string {
+	if param == "" || param == "_" {
+		return ""
+	}
+
+	// キャメルケースを単語に分け、略語は全て大文字にする
+	var words []string
+	start := 0
+	runes := []rune(param)
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || (unicode.IsUpper(runes[i]) && !unicode.IsUpper(runes[i-1])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	var b strings.Builder
+	for _, word := range words {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			b.WriteString(upper)
+		} else {
+			b.WriteString(exportedName(word))
+		}
+	}
+	return b.String()
+}
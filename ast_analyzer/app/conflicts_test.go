@@ -0,0 +1,111 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWireAnalyzer_DetectConflicts(t *testing.T) {
+	analyzer := NewWireAnalyzer("../../testdata/conflicts", "./...")
+	injectors, err := analyzer.AnalyzeInjectors("../../testdata/conflicts/wire.go")
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+
+	conflicts := injectors[0].Graph.Conflicts
+	if len(conflicts) != 1 {
+		t.Fatalf("Conflicts = %+v, want 1", conflicts)
+	}
+
+	conflict := conflicts[0]
+	if conflict.Type.TypeString != "string" || len(conflict.Uses) != 2 {
+		t.Errorf("conflict = %+v", conflict)
+	}
+
+	want := []string{
+		"example.com/conflicts/db: type DSN string",
+		"example.com/conflicts/client: type APIKey string",
+	}
+	if len(conflict.Suggestions) != len(want) {
+		t.Fatalf("Suggestions = %+v", conflict.Suggestions)
+	}
+	for i, suggestion := range conflict.Suggestions {
+		if got := suggestion.PackagePath + ": " + suggestion.Declaration(); got != want[i] {
+			t.Errorf("Suggestions[%d] = %s, want %s", i, got, want[i])
+		}
+	}
+}
+
+func TestSuggestNamedTypes(t *testing.T) {
+	ref := TypeRef{TypeName: "string", TypeString: "string"}
+	use := func(pkgPath, fn, param string) TypeUse {
+		return TypeUse{Function: InitFunctionInfo{Name: fn, PackagePath: pkgPath}, Param: param}
+	}
+
+	// 同じ名前の引数は同じ値を表すので衝突ではない
+	if got := suggestNamedTypes(ref, []TypeUse{use("a", "NewA", "dsn"), use("b", "NewB", "dsn")}); got != nil {
+		t.Errorf("same names: got %+v, want nil", got)
+	}
+
+	// 同じパッケージで同じ名前の引数は1つの型にまとめる
+	got := suggestNamedTypes(ref, []TypeUse{use("a", "NewA", "dsn"), use("a", "NewB", "dsn"), use("a", "NewC", "apiKey")})
+	if len(got) != 2 || len(got[0].Uses) != 2 || got[1].Name != "APIKey" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestNamedTypeFor(t *testing.T) {
+	tests := []struct {
+		param    string
+		expected string
+	}{
+		{"dsn", "DSN"},
+		{"apiKey", "APIKey"},
+		{"maxPoolSize", "MaxPoolSize"},
+		{"userID", "UserID"},
+		{"_", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.param, func(t *testing.T) {
+			if got := namedTypeFor(tt.param); got != tt.expected {
+				t.Errorf("namedTypeFor(%q) = %s, want %s", tt.param, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWireAnalyzer_DetectConflicts_StructFields(t *testing.T) {
+	analyzer := NewWireAnalyzer("../../testdata/structfields", "./...")
+	injectors, err := analyzer.AnalyzeInjectors("../../testdata/structfields/wire.go")
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+
+	// wire.Structで埋めるフィールドも同じ型の値を共有するため衝突になる
+	conflicts := injectors[0].Graph.Conflicts
+	if len(conflicts) != 2 {
+		t.Fatalf("Conflicts = %+v, want 2", conflicts)
+	}
+
+	var got []string
+	for _, conflict := range conflicts {
+		for _, suggestion := range conflict.Suggestions {
+			for _, use := range suggestion.Uses {
+				if !use.IsField() || use.Struct.TypeName != "Config" {
+					t.Errorf("use = %+v, want a field of Config", use)
+				}
+			}
+			got = append(got, suggestion.PackagePath+": "+suggestion.Name)
+		}
+	}
+	want := []string{
+		"example.com/structfields/app: DSN",
+		"example.com/structfields/app: APIKey",
+		"example.com/structfields/app: AdminRoutes",
+		"example.com/structfields/app: UserRoutes",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Suggestions =\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
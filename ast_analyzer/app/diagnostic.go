@@ -0,0 +1,161 @@
+package app
+
+import (
+	"fmt"
+	"go/token"
+)
+
+// DiagnosticCode は診断の種類を表すコード（例: "CW001"）
+type DiagnosticCode string
+
+const (
+	CodeNoImplementation        DiagnosticCode = "CW001" // インターフェースの実装型が見つからない
+	CodeAmbiguousImplementation DiagnosticCode = "CW002" // インターフェースの実装型が複数ある
+	CodeLoadError               DiagnosticCode = "CW003" // パッケージや構造体を読み込めない
+	CodeCancelled               DiagnosticCode = "CW004" // 解析が中断された
+	CodeAmbiguousProvider       DiagnosticCode = "CW005" // 同じ型を返す提供関数が複数ある
+)
+
+// diagnosticNames はコードごとの短い名前
+var diagnosticNames = map[DiagnosticCode]string{
+	CodeNoImplementation:        "no-implementation",
+	CodeAmbiguousImplementation: "ambiguous-implementation",
+	CodeLoadError:               "load-error",
+	CodeCancelled:               "cancelled",
+	CodeAmbiguousProvider:       "ambiguous-provider",
+}
+
+// diagnosticDescriptions はコードごとの説明
+var diagnosticDescriptions = map[DiagnosticCode]string{
+	CodeNoImplementation:        "No type implementing the interface was found, so wire cannot provide the field.",
+	CodeAmbiguousImplementation: "Several types implement the interface; add a wire.Bind to choose one.",
+	CodeLoadError:               "A package or struct reachable from the injector could not be loaded or analyzed.",
+	CodeCancelled:               "The analysis was cancelled before the dependency could be resolved.",
+	CodeAmbiguousProvider:       "Several functions return the same type, so wire cannot choose the provider; remove or rename all but one.",
+}
+
+// DiagnosticCodes は全ての診断コードをコード順に返す
+func DiagnosticCodes() []DiagnosticCode {
+	return []DiagnosticCode{CodeNoImplementation, CodeAmbiguousImplementation, CodeLoadError, CodeCancelled, CodeAmbiguousProvider}
+}
+
+// Name はコードの短い名前を返す（例: "no-implementation"）
+func (c DiagnosticCode) Name() string {
+	return diagnosticNames[c]
+}
+
+// Description はコードの説明を返す
+func (c DiagnosticCode) Description() string {
+	return diagnosticDescriptions[c]
+}
+
+// DefaultSeverity はコードの既定の重要度を返す
+func (c DiagnosticCode) DefaultSeverity() Severity {
+	return defaultSeverities[c]
+}
+
+// Severity は診断の重要度を表す
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // 注入関数を生成できない
+	SeverityWarning Severity = "warning" // wire.Bindなどの指定があれば生成できる
+	SeverityInfo    Severity = "info"    // 解析結果が不完全であることの通知
+)
+
+// Diagnostic は解析中に見つかった問題
+// ノードがスキップされた場合はノードに付けられ、SkipReasonにはMessageが入る
+type Diagnostic struct {
+	Code       DiagnosticCode // 診断の種類
+	Severity   Severity       // 重要度
+	Message    string         // 人が読むためのメッセージ（SkipReasonと同じ）
+	Position   token.Position // 問題のある箇所（フィールドの宣言や注入関数。不明な場合はゼロ値）
+	Types      []TypeRef      // 関係する型（解決できなかったインターフェースや構造体）
+	Candidates []Candidate    // 実装型の候補
+}
+
+// Candidate はインターフェースの実装型の候補（解決に使った実装型や、同じ型を返す提供関数にも使う）
+type Candidate struct {
+	Type                TypeRef        // 実装型
+	Function            string         // 実装型を返す関数の名前（メソッドセットだけで見つかった場合は空）
+	FunctionPackagePath string         // 関数が定義されているパッケージパス（Functionが空の場合は空）
+	Position            token.Position // 関数の宣言の位置（Functionが空の場合は実装型の宣言の位置）
+	ReturnPosition      token.Position // 実装型を返すreturn文の位置（Functionが空の場合はゼロ値）
+}
+
+// String はコード・名前・メッセージをまとめた文字列を返す
+// 例: "CW002 ambiguous-implementation: multiple implementing types found (2)"
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%s %s: %s", d.Code, d.Code.Name(), d.Message)
+}
+
+// defaultSeverities はコードごとの重要度
+var defaultSeverities = map[DiagnosticCode]Severity{
+	CodeNoImplementation:        SeverityError,
+	CodeAmbiguousImplementation: SeverityWarning,
+	CodeLoadError:               SeverityError,
+	CodeCancelled:               SeverityInfo,
+	CodeAmbiguousProvider:       SeverityError,
+}
+
+// newDiagnostic はコードに応じた重要度で診断を作成する
+func newDiagnostic(code DiagnosticCode, pos token.Position, message string, types ...TypeRef) *Diagnostic {
+	return &Diagnostic{
+		Code:     code,
+		Severity: defaultSeverities[code],
+		Message:  message,
+		Position: pos,
+		Types:    types,
+	}
+}
+
+// newSkippedStruct は診断の理由で解析をスキップした構造体のノードを作成する
+func newSkippedStruct(fieldName, structName, packagePath string, diagnostic *Diagnostic) *StructNode {
+	return &StructNode{
+		FieldName:   fieldName,
+		StructName:  structName,
+		PackagePath: packagePath,
+		Skipped:     true,
+		SkipReason:  diagnostic.Message,
+		Diagnostic:  diagnostic,
+	}
+}
+
+// CollectDiagnostics はツリーのノードに付けられた診断を、ルートから辿った順に集める
+// 複数の箇所から参照されているノードの診断は1度だけ含める
+func CollectDiagnostics(roots ...*StructNode) []Diagnostic {
+	var diagnostics []Diagnostic
+	seen := make(map[FieldNode]bool)
+
+	var walk func(node FieldNode)
+	walk = func(node FieldNode) {
+		if node == nil || seen[node] {
+			return
+		}
+		seen[node] = true
+
+		switch n := node.(type) {
+		case *StructNode:
+			if n.Diagnostic != nil {
+				diagnostics = append(diagnostics, *n.Diagnostic)
+			}
+			for _, field := range n.Fields {
+				walk(field)
+			}
+		case *InterfaceNode:
+			if n.Diagnostic != nil {
+				diagnostics = append(diagnostics, *n.Diagnostic)
+			}
+			if n.ResolvedStruct != nil {
+				walk(n.ResolvedStruct)
+			}
+		}
+	}
+
+	for _, root := range roots {
+		if root != nil {
+			walk(root)
+		}
+	}
+	return diagnostics
+}
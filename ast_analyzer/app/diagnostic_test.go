@@ -0,0 +1,138 @@
+package app
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+)
+
+func TestWireAnalyzer_AnalyzeInjectors_Diagnostics(t *testing.T) {
+	workDir := "../../testdata/wirecheck"
+	wireFilePath := "../../testdata/wirecheck/wire.go"
+
+	injectors, err := NewWireAnalyzer(workDir, "./...").AnalyzeInjectors(wireFilePath)
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+	if len(injectors) != 1 {
+		t.Fatalf("len(injectors) = %d, want 1", len(injectors))
+	}
+
+	byCode := make(map[DiagnosticCode][]Diagnostic)
+	for _, diagnostic := range injectors[0].Diagnostics {
+		byCode[diagnostic.Code] = append(byCode[diagnostic.Code], diagnostic)
+	}
+
+	tests := []struct {
+		name           string
+		code           DiagnosticCode
+		wantSeverity   Severity
+		wantType       string
+		wantMessage    string
+		wantString     string
+		wantCandidates []string
+		wantLine       int
+	}{
+		{
+			name:         "実装型がないインターフェース",
+			code:         CodeNoImplementation,
+			wantSeverity: SeverityError,
+			wantType:     "example.com/wirecheck/cache.Cache",
+			wantMessage:  "no implementing types found",
+			wantString:   "CW001 no-implementation: no implementing types found",
+			wantLine:     14,
+		},
+		{
+			name:           "実装型が複数あるインターフェース",
+			code:           CodeAmbiguousImplementation,
+			wantSeverity:   SeverityWarning,
+			wantType:       "example.com/wirecheck/notify.Notifier",
+			wantMessage:    "multiple implementing types found (2)",
+			wantString:     "CW002 ambiguous-implementation: multiple implementing types found (2)",
+			wantCandidates: []string{"example.com/wirecheck/notify.EmailNotifier", "example.com/wirecheck/notify.SlackNotifier"},
+			wantLine:       13,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diagnostic *Diagnostic
+			for i, d := range byCode[tt.code] {
+				if len(d.Types) > 0 && d.Types[0].Key() == tt.wantType {
+					diagnostic = &byCode[tt.code][i]
+				}
+			}
+			if diagnostic == nil {
+				t.Fatalf("no %s diagnostic for %s in %+v", tt.code, tt.wantType, injectors[0].Diagnostics)
+			}
+
+			if diagnostic.Severity != tt.wantSeverity {
+				t.Errorf("Severity = %s, want %s", diagnostic.Severity, tt.wantSeverity)
+			}
+			if diagnostic.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", diagnostic.Message, tt.wantMessage)
+			}
+			if got := diagnostic.String(); got != tt.wantString {
+				t.Errorf("String() = %q, want %q", got, tt.wantString)
+			}
+			if filepath.Base(diagnostic.Position.Filename) != "service.go" || diagnostic.Position.Line != tt.wantLine {
+				t.Errorf("Position = %s, want service.go:%d", diagnostic.Position, tt.wantLine)
+			}
+
+			var candidates []string
+			for _, candidate := range diagnostic.Candidates {
+				candidates = append(candidates, candidate.Type.Key())
+				if filepath.Base(candidate.Position.Filename) != "notify.go" {
+					t.Errorf("candidate %s Position = %s, want notify.go", candidate.Type.Key(), candidate.Position)
+				}
+			}
+			if len(candidates) != len(tt.wantCandidates) {
+				t.Fatalf("Candidates = %v, want %v", candidates, tt.wantCandidates)
+			}
+			for i := range candidates {
+				if candidates[i] != tt.wantCandidates[i] {
+					t.Errorf("Candidates[%d] = %s, want %s", i, candidates[i], tt.wantCandidates[i])
+				}
+			}
+		})
+	}
+
+	// スキップされたノードのSkipReasonは診断のメッセージと同じ
+	service := injectors[0].Root.Fields[0].(*StructNode)
+	for _, field := range service.Fields {
+		node, ok := field.(*InterfaceNode)
+		if !ok || !node.Skipped {
+			continue
+		}
+		if node.Diagnostic == nil || node.SkipReason != node.Diagnostic.Message {
+			t.Errorf("field %s: SkipReason = %q, Diagnostic = %+v", node.FieldName, node.SkipReason, node.Diagnostic)
+		}
+	}
+}
+
+func TestCollectDiagnostics(t *testing.T) {
+	shared := &StructNode{
+		StructName: "Shared",
+		Fields: []FieldNode{
+			&InterfaceNode{FieldName: "repo", Skipped: true, Diagnostic: newDiagnostic(CodeNoImplementation, token.Position{}, "no implementing types found")},
+		},
+	}
+	root := &StructNode{
+		StructName: "App",
+		Fields: []FieldNode{
+			&InterfaceNode{FieldName: "a", ResolvedStruct: shared},
+			&InterfaceNode{FieldName: "b", ResolvedStruct: shared},
+			newSkippedStruct("c", "Broken", "", newDiagnostic(CodeLoadError, token.Position{}, "failed to analyze")),
+		},
+	}
+
+	// 共有されたノードの診断は1度だけ、ルートから辿った順に集める
+	diagnostics := CollectDiagnostics(root)
+	var codes []DiagnosticCode
+	for _, diagnostic := range diagnostics {
+		codes = append(codes, diagnostic.Code)
+	}
+	if len(codes) != 2 || codes[0] != CodeNoImplementation || codes[1] != CodeLoadError {
+		t.Errorf("CollectDiagnostics() codes = %v, want [CW001 CW003]", codes)
+	}
+}
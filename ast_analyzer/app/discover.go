@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go/types"
+	"path"
+	"sort"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+	gopkgs "golang.org/x/tools/go/packages"
+)
+
+// DiscoverProvidedTypes はパッケージパターンに含まれる型のうち、名前がnameRuleに一致し、提供関数がある型を探す
+// nameRuleはpath.Matchの形式（例: "*Handler"）で、結果はパッケージパスと型名の順に並べる
+// 提供関数がポインタを返す型はIsPointerをtrueにする
+func (wa *WireAnalyzer) DiscoverProvidedTypes(pattern, nameRule string) ([]TypeRef, error) {
+	if _, err := path.Match(nameRule, ""); err != nil {
+		return nil, fmt.Errorf("invalid name rule %q: %w", nameRule, err)
+	}
+
+	cfg := &gopkgs.Config{
+		Mode:       gopkgs.NeedName | gopkgs.NeedImports | gopkgs.NeedDeps | gopkgs.NeedTypes,
+		Dir:        wa.workDir,
+		BuildFlags: packages.BuildFlags(),
+	}
+	pkgs, err := gopkgs.Load(cfg, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	var found []TypeRef
+	for _, pkg := range pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			typeName, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || !typeName.Exported() || typeName.IsAlias() {
+				continue
+			}
+			if matched, _ := path.Match(nameRule, name); !matched {
+				continue
+			}
+
+			// 提供関数がない型は組み立てられないので除く
+			initFuncs, err := wa.findTypeProviders(context.Background(), pkg.PkgPath, name)
+			if err != nil || len(initFuncs) == 0 {
+				continue
+			}
+
+			found = append(found, TypeRef{
+				TypeName:    name,
+				PackagePath: pkg.PkgPath,
+				PackageName: pkg.Name,
+				TypeString:  pkg.Name + "." + name,
+				IsPointer:   initFuncs[0].Result.IsPointer,
+				Origin:      packages.OriginModuleLocal,
+			})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].PackagePath != found[j].PackagePath {
+			return found[i].PackagePath < found[j].PackagePath
+		}
+		return found[i].TypeName < found[j].TypeName
+	})
+
+	return found, nil
+}
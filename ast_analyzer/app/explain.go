@@ -0,0 +1,189 @@
+package app
+
+import (
+	"path"
+	"strings"
+)
+
+// DependencyPath は注入関数のルートから型までの依存関係の経路
+type DependencyPath struct {
+	Injector string     // 注入関数名
+	Steps    []PathStep // ルートから順の経路の段
+}
+
+// PathStep は経路の1段（型と、次の段に進むフィールド）
+type PathStep struct {
+	TypeName       string     // 型名
+	PackagePath    string     // パッケージパス（基本型の場合は空）
+	FieldName      string     // 次の段に進むフィールド名（最後の段は空）
+	Implementation *Candidate // 経路が通ったインターフェースの実装型（それ以外はnil）
+}
+
+// String は経路を "ControllerSet.handler -> UserHandler.service -> UserService (impl userServiceImpl via NewUserService) -> repo -> UserRepository" の形式で返す
+// 実装型を通る段では、実装型のフィールド名を別の段として続ける
+func (p DependencyPath) String() string {
+	var segments []string
+	for _, step := range p.Steps {
+		segment := step.TypeName
+		if impl := step.Implementation; impl != nil {
+			segment += " (impl " + impl.Type.TypeName
+			if impl.Function != "" {
+				segment += " via " + impl.Function
+			}
+			segment += ")"
+			segments = append(segments, segment)
+			if step.FieldName != "" {
+				segments = append(segments, step.FieldName)
+			}
+			continue
+		}
+		if step.FieldName != "" {
+			segment += "." + step.FieldName
+		}
+		segments = append(segments, segment)
+	}
+	return strings.Join(segments, " -> ")
+}
+
+// Explain は各注入関数のルートから、型または提供関数に一致するノードまでの経路を全て返す
+// query: "UserRepository"、"repository.UserRepository"、"example.com/app/repository.UserRepository" の形式の型名
+// または "NewUserRepository" のような提供関数名（型名と同じくパッケージで修飾できる）
+// 一致したノードより先は辿らず、循環している経路は同じ構造体に戻った時点で打ち切る
+func Explain(injectors []*InjectorInfo, query string) []DependencyPath {
+	q := parseQuery(query)
+
+	var paths []DependencyPath
+	for _, injector := range injectors {
+		if injector.Root == nil {
+			continue
+		}
+		e := &explainer{query: q, injector: injector.Name, visiting: make(map[*StructNode]bool)}
+		e.visitStruct(injector.Root, nil)
+		paths = append(paths, e.paths...)
+	}
+	return paths
+}
+
+// explainQuery は型名または提供関数名の検索条件
+type explainQuery struct {
+	pkgSpec string // パッケージパス、その末尾、またはパッケージ名（修飾されていない場合は空）
+	name    string // 型名または関数名
+}
+
+// parseQuery は検索条件を解釈する（先頭の * は無視する）
+func parseQuery(query string) explainQuery {
+	query = strings.TrimPrefix(strings.TrimSpace(query), "*")
+	dot := strings.LastIndex(query, ".")
+	if dot < 0 {
+		return explainQuery{name: query}
+	}
+	return explainQuery{pkgSpec: query[:dot], name: query[dot+1:]}
+}
+
+// matches はパッケージパスと名前が検索条件に一致するかを判定する
+func (q explainQuery) matches(pkgPath, name string) bool {
+	if name != q.name {
+		return false
+	}
+	if q.pkgSpec == "" {
+		return true
+	}
+	return pkgPath == q.pkgSpec || path.Base(pkgPath) == q.pkgSpec || strings.HasSuffix(pkgPath, "/"+q.pkgSpec)
+}
+
+// matchesProvider は提供関数のいずれかが検索条件に一致するかを判定する
+func (q explainQuery) matchesProvider(functions []InitFunctionInfo) bool {
+	for _, fn := range functions {
+		if q.matches(fn.PackagePath, fn.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// explainer は1つの注入関数のツリーを辿り、検索条件に一致するノードまでの経路を集める
+type explainer struct {
+	query    explainQuery
+	injector string
+	visiting map[*StructNode]bool // 辿っている経路上の構造体（循環の検出）
+	paths    []DependencyPath
+}
+
+// found は経路を記録する（段は後で変更されないようコピーする）
+func (e *explainer) found(steps []PathStep) {
+	e.paths = append(e.paths, DependencyPath{Injector: e.injector, Steps: append([]PathStep(nil), steps...)})
+}
+
+// visitStruct は構造体が一致すれば経路を記録し、一致しなければフィールドを辿る
+// prefix はこの構造体に至るまでの段で、この構造体がインターフェースの実装型の場合はその段にフィールド名を加える
+func (e *explainer) visitStruct(node *StructNode, prefix []PathStep) {
+	if node == nil || e.visiting[node] {
+		return
+	}
+	// インターフェースの段がまだフィールドを持たなければ、この構造体はその実装型
+	throughImpl := len(prefix) > 0 && prefix[len(prefix)-1].Implementation != nil && prefix[len(prefix)-1].FieldName == ""
+
+	if !throughImpl && (e.query.matches(node.PackagePath, node.StructName) || e.query.matchesProvider(node.InitFunctions)) {
+		e.found(append(prefix, PathStep{TypeName: node.StructName, PackagePath: node.PackagePath}))
+		return
+	}
+	if node.Skipped {
+		return
+	}
+
+	e.visiting[node] = true
+	defer delete(e.visiting, node)
+
+	for _, field := range node.Fields {
+		var steps []PathStep
+		if throughImpl {
+			// 実装型はインターフェースの段で示したため、フィールド名だけを加える
+			steps = append([]PathStep(nil), prefix...)
+			steps[len(steps)-1].FieldName = field.GetFieldName()
+		} else {
+			steps = append(append([]PathStep(nil), prefix...), PathStep{
+				TypeName:    node.StructName,
+				PackagePath: node.PackagePath,
+				FieldName:   field.GetFieldName(),
+			})
+		}
+		e.visitField(field, steps)
+	}
+}
+
+// visitField はフィールドのノードを辿る
+func (e *explainer) visitField(field FieldNode, prefix []PathStep) {
+	switch f := field.(type) {
+	case *StructNode:
+		e.visitStruct(f, prefix)
+	case *InterfaceNode:
+		e.visitInterface(f, prefix)
+	case *InputNode:
+		if e.query.matches(f.PackagePath, f.TypeName) || e.query.matchesProvider(f.InitFunctions) {
+			e.found(append(prefix, PathStep{TypeName: f.TypeName, PackagePath: f.PackagePath}))
+		}
+	}
+}
+
+// visitInterface はインターフェースが一致すれば経路を記録し、一致しなければ実装型を辿る
+// 実装型やその提供関数が一致した場合は、実装型を示したインターフェースの段で経路を終える
+func (e *explainer) visitInterface(node *InterfaceNode, prefix []PathStep) {
+	step := PathStep{TypeName: node.TypeName, PackagePath: node.PackagePath}
+	if e.query.matches(node.PackagePath, node.TypeName) {
+		e.found(append(prefix, step))
+		return
+	}
+
+	impl := node.Implementation
+	resolved := node.ResolvedStruct
+	if impl == nil || resolved == nil {
+		return
+	}
+	step.Implementation = impl
+	if e.query.matches(impl.FunctionPackagePath, impl.Function) ||
+		e.query.matches(resolved.PackagePath, resolved.StructName) || e.query.matchesProvider(resolved.InitFunctions) {
+		e.found(append(prefix, step))
+		return
+	}
+	e.visitStruct(resolved, append(prefix, step))
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	workDir := "../../sample/basic"
+	wireFilePath := "../../sample/basic/wire.go"
+
+	injectors, err := NewWireAnalyzer(workDir, "./...").AnalyzeInjectors(wireFilePath)
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+
+	const toUserService = "ControllerSet.handler -> UserHandler.service -> UserService (impl userServiceImpl via NewUserService)"
+	const toUserRepository = toUserService + " -> repo -> UserRepository"
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "インターフェースの型名",
+			query: "UserRepository",
+			want:  []string{toUserRepository},
+		},
+		{
+			name:  "パッケージで修飾した型名",
+			query: "github.com/rmocchy/convinient_wire/sample/basic/repository.UserRepository",
+			want:  []string{toUserRepository},
+		},
+		{
+			name:  "構造体を返す提供関数",
+			query: "repository.NewConfig",
+			want:  []string{toUserRepository + " (impl userRepositoryImpl via NewUserRepository) -> config -> Config"},
+		},
+		{
+			name:  "実装型を返す提供関数",
+			query: "NewUserService",
+			want:  []string{toUserService},
+		},
+		{
+			name:  "ポインタの構造体",
+			query: "*handler.UserHandler",
+			want:  []string{"ControllerSet.handler -> UserHandler"},
+		},
+		{
+			name:  "ルートの構造体",
+			query: "ControllerSet",
+			want:  []string{"ControllerSet"},
+		},
+		{
+			name:  "パッケージが一致しない型名",
+			query: "service.UserRepository",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range Explain(injectors, tt.query) {
+				if p.Injector != "InitializeUserHandler" {
+					t.Errorf("Injector = %s, want InitializeUserHandler", p.Injector)
+				}
+				got = append(got, p.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Explain(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplain_SharedAndCyclic(t *testing.T) {
+	impl := &Candidate{Type: TypeRef{TypeName: "Repo", PackagePath: "example.com/app/repo"}, Function: "NewRepo"}
+	repo := &StructNode{FieldName: "primary", StructName: "Repo", PackagePath: "example.com/app/repo"}
+	repo.Fields = []FieldNode{
+		&StructNode{FieldName: "db", StructName: "DB", PackagePath: "example.com/app/db"},
+		&InterfaceNode{FieldName: "self", TypeName: "Repository", PackagePath: "example.com/app/repo", ResolvedStruct: repo, Implementation: impl},
+	}
+	root := &StructNode{
+		StructName: "App",
+		Fields: []FieldNode{
+			repo,
+			&InterfaceNode{FieldName: "secondary", TypeName: "Repository", PackagePath: "example.com/app/repo", ResolvedStruct: repo, Implementation: impl},
+		},
+	}
+	injectors := []*InjectorInfo{{Name: "InitializeApp", Root: root}}
+
+	// 2つのフィールドから参照される構造体は経路ごとに報告し、同じ構造体に戻る経路は打ち切る
+	var got []string
+	for _, p := range Explain(injectors, "db.DB") {
+		got = append(got, p.String())
+	}
+	want := []string{
+		"App.primary -> Repo.db -> DB",
+		"App.secondary -> Repository (impl Repo via NewRepo) -> db -> DB",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Explain() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/cache"
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+	gopkgs "golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// factIndex は検索対象のパッケージから抽出した情報（キャッシュを使う場合のみ）
+type factIndex struct {
+	facts  []*packages.PackageFacts // 検索対象のパッケージの順
+	byPath map[string]*packages.PackageFacts
+	hits   int // キャッシュから読み込んだパッケージの数
+	misses int // 読み込み直して抽出したパッケージの数
+}
+
+// WithCache は検索対象のパッケージから抽出した情報をディスクにキャッシュするようにする
+// 内容が変わっていないパッケージは型情報を読み込まずにキャッシュから解析する
+func WithCache(c *cache.Cache) Option {
+	return func(wa *WireAnalyzer) {
+		wa.cache = c
+	}
+}
+
+// loadFacts は検索対象のパッケージの抽出済みの情報を読み込む（一度読み込んだ結果を再利用する）
+// キャッシュを使わない場合やパッケージの一覧を取得できない場合はnilを返し、呼び出し側は型情報から解析する
+// ctxが取り消されて読み込めなかった場合は、次に呼ばれたときに読み込み直す
+func (wa *WireAnalyzer) loadFacts(ctx context.Context) *factIndex {
+	wa.factsMu.Lock()
+	defer wa.factsMu.Unlock()
+
+	if wa.cache == nil || wa.factsErr != nil {
+		return nil
+	}
+	if wa.facts != nil {
+		return wa.facts
+	}
+
+	index, err := wa.buildFactIndex(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			wa.factsErr = err
+		}
+		return nil
+	}
+	wa.facts = index
+	return index
+}
+
+// buildFactIndex はパッケージの一覧とファイルの内容からキーを計算し、キャッシュにないパッケージだけを読み込んで抽出する
+func (wa *WireAnalyzer) buildFactIndex(ctx context.Context) (*factIndex, error) {
+	// パッケージの一覧とファイルだけを取得する（型検査はしない）
+	listCfg := &gopkgs.Config{
+		Mode:       gopkgs.NeedName | gopkgs.NeedFiles | gopkgs.NeedImports | gopkgs.NeedDeps | gopkgs.NeedModule,
+		Dir:        wa.workDir,
+		BuildFlags: packages.BuildFlags(),
+		Context:    ctx,
+	}
+	listed, err := gopkgs.Load(listCfg, wa.searchPattern)
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list packages: %w", err)
+	}
+	keys, err := cache.Keys(listed, packages.BuildFlags())
+	if err != nil {
+		return nil, err
+	}
+
+	index := &factIndex{
+		facts:  make([]*packages.PackageFacts, len(listed)),
+		byPath: make(map[string]*packages.PackageFacts, len(listed)),
+	}
+	positions := make(map[string]int)
+	searched := make(map[string]bool, len(listed))
+	var missing []string
+	for i, pkg := range listed {
+		searched[pkg.PkgPath] = true
+		if facts, ok := wa.cache.Load(keys[pkg.PkgPath]); ok {
+			index.facts[i] = facts
+			index.hits++
+			continue
+		}
+		positions[pkg.PkgPath] = i
+		missing = append(missing, pkg.PkgPath)
+	}
+
+	if len(missing) > 0 {
+		loadCfg := &gopkgs.Config{
+			Mode:       gopkgs.LoadAllSyntax | gopkgs.NeedModule,
+			Dir:        wa.workDir,
+			BuildFlags: packages.BuildFlags(),
+			Context:    ctx,
+		}
+		loaded, err := gopkgs.Load(loadCfg, missing...)
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load packages: %w", err)
+		}
+
+		// 別のパッケージのヘルパー関数を経由するreturnも追跡できるよう、検索対象のパッケージは関数本体を含めてSSAを構築する
+		var ssaPkgs []*gopkgs.Package
+		gopkgs.Visit(loaded, nil, func(pkg *gopkgs.Package) {
+			if searched[pkg.PkgPath] && pkg.Module != nil && pkg.Module.Main {
+				ssaPkgs = append(ssaPkgs, pkg)
+			}
+		})
+		prog, _ := ssautil.Packages(ssaPkgs, 0)
+		prog.Build()
+
+		for _, pkg := range loaded {
+			i, ok := positions[pkg.PkgPath]
+			if !ok {
+				continue
+			}
+			facts := packages.ExtractPackageFacts(pkg, prog, loaded)
+			index.facts[i] = facts
+			index.misses++
+			// エラーのあるパッケージは修正されるまで毎回読み込み直す
+			if !facts.HasErrors {
+				// キャッシュに書き込めなくても解析は続ける
+				_ = wa.cache.Store(keys[pkg.PkgPath], facts)
+			}
+		}
+	}
+
+	for i, facts := range index.facts {
+		if facts == nil {
+			return nil, fmt.Errorf("failed to load package %s", listed[i].PkgPath)
+		}
+		index.byPath[facts.PackagePath] = facts
+	}
+	return index, nil
+}
+
+// packageFacts は検索対象のパッケージの抽出済みの情報を返す（キャッシュを使わない場合や検索対象外の場合はnil）
+func (wa *WireAnalyzer) packageFacts(ctx context.Context, packagePath string) *packages.PackageFacts {
+	index := wa.loadFacts(ctx)
+	if index == nil {
+		return nil
+	}
+	return index.byPath[packagePath]
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/cache"
+	"github.com/rmocchy/convinient_wire/internal/testutil"
+)
+
+func TestWireAnalyzer_WithCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		workDir string
+	}{
+		{name: "sample/basic", workDir: "../../sample/basic"},
+		{name: "multi", workDir: "../../testdata/multi"},
+		{name: "values", workDir: "../../testdata/values"},
+		{name: "fieldsof", workDir: "../../testdata/fieldsof"},
+		{name: "lsp", workDir: "../../testdata/lsp"},
+		{name: "inputs", workDir: "../../testdata/inputs"},
+		{name: "anyparam", workDir: "../../testdata/anyparam"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wireFilePath := filepath.Join(tt.workDir, "wire.go")
+			want, err := NewWireAnalyzer(tt.workDir, "./...").AnalyzeInjectors(wireFilePath)
+			if err != nil {
+				t.Fatalf("AnalyzeInjectors without cache failed: %v", err)
+			}
+
+			c := cache.New(t.TempDir())
+
+			// 1回目はキャッシュがないため全て読み込む
+			first := NewWireAnalyzer(tt.workDir, "./...", WithCache(c))
+			got, err := first.AnalyzeInjectors(wireFilePath)
+			if err != nil {
+				t.Fatalf("AnalyzeInjectors with empty cache failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("AnalyzeInjectors with empty cache differs from analysis without cache")
+			}
+			if first.facts == nil || first.facts.hits != 0 || first.facts.misses == 0 {
+				t.Fatalf("first analysis facts = %+v, want only misses", first.facts)
+			}
+
+			// 2回目は全てキャッシュから読み込む
+			second := NewWireAnalyzer(tt.workDir, "./...", WithCache(c))
+			got, err = second.AnalyzeInjectors(wireFilePath)
+			if err != nil {
+				t.Fatalf("AnalyzeInjectors with warm cache failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("AnalyzeInjectors with warm cache differs from analysis without cache")
+			}
+			if second.facts.misses != 0 || second.facts.hits != first.facts.misses {
+				t.Errorf("second analysis hits = %d, misses = %d, want hits = %d, misses = 0",
+					second.facts.hits, second.facts.misses, first.facts.misses)
+			}
+		})
+	}
+}
+
+func TestWireAnalyzer_WithCache_Invalidation(t *testing.T) {
+	dir := testutil.CopyFixture(t, "multi")
+	wireFilePath := filepath.Join(dir, "wire.go")
+	c := cache.New(t.TempDir())
+
+	if _, err := NewWireAnalyzer(dir, "./...", WithCache(c)).AnalyzeInjectors(wireFilePath); err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+
+	// パッケージを変更すると、そのパッケージと依存しているパッケージだけを読み込み直す
+	workerPath := filepath.Join(dir, "worker", "worker.go")
+	src, err := os.ReadFile(workerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(workerPath, append(src, "\n// changed\n"...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	analyzer := NewWireAnalyzer(dir, "./...", WithCache(c))
+	if _, err := analyzer.AnalyzeInjectors(wireFilePath); err != nil {
+		t.Fatalf("AnalyzeInjectors after change failed: %v", err)
+	}
+	// worker と、worker を読み込む wire.go のパッケージだけを読み込み直す
+	if analyzer.facts.misses != 2 || analyzer.facts.hits != 3 {
+		t.Errorf("hits = %d, misses = %d, want hits = 3, misses = 2", analyzer.facts.hits, analyzer.facts.misses)
+	}
+}
@@ -0,0 +1,263 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"strings"
+	"sync"
+	"unicode"
+
+	file "github.com/rmocchy/convinient_wire/ast_analyzer/files"
+)
+
+// InjectorInput は注入関数の引数として渡す必要がある値を表す
+type InjectorInput struct {
+	Name string  // 引数名（例: "ctx", "dsn"）
+	Type TypeRef // 引数の型
+}
+
+// InjectorInfo は注入関数ごとの解析結果を保持する
+type InjectorInfo struct {
+	Name          string          // 注入関数名
+	PackagePath   string          // wire.goのパッケージパス
+	Position      token.Position  // wire.goの注入関数名の位置
+	Root          *StructNode     // 返り値の構造体の解析結果
+	RootIsPointer bool            // 返り値がポインタかどうか
+	ReturnsError  bool            // errorを返す必要があるかどうか
+	HasCleanup    bool            // クリーンアップ関数を返す必要があるかどうか
+	Graph         *ProviderGraph  // ルートから辿った提供関数の依存グラフ
+	Inputs        []InjectorInput // 引数として渡す必要がある値
+	Cancelled     bool            // 解析が中断されたため、依存グラフを作成していないかどうか
+	Diagnostics   []Diagnostic    // ルートから辿ったツリーと依存グラフで見つかった問題
+}
+
+// AnalyzeInjectors はwire.goの注入関数ごとに依存関係を解析し、引数として渡す必要がある値を求める
+func (wa *WireAnalyzer) AnalyzeInjectors(wireFilePath string) ([]*InjectorInfo, error) {
+	return wa.analyzeInjectors(context.Background(), wireFilePath, nil)
+}
+
+// AnalyzeInjectorsContext はAnalyzeInjectorsと同様に注入関数ごとに依存関係を解析する
+// ctxが取り消されたりタイムアウトした場合は、それまでに解析した部分的な結果とErrCancelledを返す
+// 依存グラフを作成する前に中断された注入関数はCancelledがtrueになる
+func (wa *WireAnalyzer) AnalyzeInjectorsContext(ctx context.Context, wireFilePath string) ([]*InjectorInfo, error) {
+	return wa.analyzeInjectors(ctx, wireFilePath, nil)
+}
+
+// AnalyzeInjectorsByName はwire.goの注入関数のうち、名前を指定したものだけを解析する
+// 変更の影響を受けた注入関数だけを解析し直す場合に使う
+func (wa *WireAnalyzer) AnalyzeInjectorsByName(wireFilePath string, names ...string) ([]*InjectorInfo, error) {
+	targets := make(map[string]bool, len(names))
+	for _, name := range names {
+		targets[name] = true
+	}
+	return wa.analyzeInjectors(context.Background(), wireFilePath, targets)
+}
+
+// AnalyzeInjectorsByNameContext はAnalyzeInjectorsByNameと同様に名前を指定した注入関数だけを解析する
+// 中断された場合の結果はAnalyzeInjectorsContextと同じ
+func (wa *WireAnalyzer) AnalyzeInjectorsByNameContext(ctx context.Context, wireFilePath string, names ...string) ([]*InjectorInfo, error) {
+	targets := make(map[string]bool, len(names))
+	for _, name := range names {
+		targets[name] = true
+	}
+	return wa.analyzeInjectors(ctx, wireFilePath, targets)
+}
+
+// analyzeInjectors は注入関数を解析する（targetsがnilの場合は全ての注入関数）
+func (wa *WireAnalyzer) analyzeInjectors(ctx context.Context, wireFilePath string, targets map[string]bool) ([]*InjectorInfo, error) {
+	functions, err := file.ParseWireFileStructs(wireFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse wire file: %w", err)
+	}
+
+	wirePkgPath, err := resolveWirePackagePath(ctx, wireFilePath)
+	if err != nil {
+		return nil, wrapCancelled(ctx, fmt.Errorf("failed to resolve wire package: %w", err))
+	}
+
+	injectorNames := make([]string, 0, len(functions))
+	for _, funcInfo := range functions {
+		injectorNames = append(injectorNames, funcInfo.Name)
+	}
+
+	// 注入関数の返り値構造体から辿れる型を並行して調べてから、注入関数の順にツリーを組み立てる
+	wa.prefetchRoots(ctx, functions, wirePkgPath, targets)
+	injectors := wa.buildInjectorTrees(ctx, functions, wirePkgPath, targets)
+
+	// 依存グラフは注入関数ごとに別のゴルーチンで組み立てる（状態は注入関数ごとのproviderResolverに持つ）
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, wa.workers)
+	for _, injector := range injectors {
+		if injector.Cancelled || injector.Root.Skipped || injector.loadError() != nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			wa.resolveInjector(ctx, injector, wirePkgPath, injectorNames)
+		}()
+	}
+	wg.Wait()
+
+	wa.treeMu.Lock()
+	defer wa.treeMu.Unlock()
+	if err := wa.checkCancelled(ctx); err != nil {
+		return injectors, err
+	}
+	return injectors, nil
+}
+
+// buildInjectorTrees は注入関数ごとに返り値の構造体のツリーを組み立てる
+// ツリーのノードは注入関数の間で共有するため、treeMuを確保して注入関数の順に組み立てる
+func (wa *WireAnalyzer) buildInjectorTrees(ctx context.Context, functions []file.FunctionInfo, wirePkgPath string, targets map[string]bool) []*InjectorInfo {
+	wa.treeMu.Lock()
+	defer wa.treeMu.Unlock()
+
+	var injectors []*InjectorInfo
+	for _, funcInfo := range functions {
+		// 返り値の構造体がない関数は注入関数として扱わない
+		if len(funcInfo.ReturnTypes) == 0 {
+			continue
+		}
+		if targets != nil && !targets[funcInfo.Name] {
+			continue
+		}
+		rootInfo := funcInfo.ReturnTypes[0]
+
+		injector := &InjectorInfo{
+			Name:          funcInfo.Name,
+			PackagePath:   wirePkgPath,
+			Position:      funcInfo.Position,
+			Root:          wa.analyzeRoot(ctx, funcInfo, rootInfo, wirePkgPath, functions),
+			RootIsPointer: rootInfo.IsPointer,
+			ReturnsError:  funcInfo.ReturnsError,
+		}
+		injector.Diagnostics = CollectDiagnostics(injector.Root)
+
+		// 中断された場合は欠けたツリーから依存グラフを作らない
+		if ctx.Err() != nil {
+			injector.Cancelled = true
+		}
+		injectors = append(injectors, injector)
+	}
+	return injectors
+}
+
+// resolveInjector は注入関数のツリーから依存グラフを組み立て、引数と返り値を決める
+// 読み込めなかったパッケージがある場合は依存が欠けたグラフになるため、呼び出す側で除く
+func (wa *WireAnalyzer) resolveInjector(ctx context.Context, injector *InjectorInfo, wirePkgPath string, injectorNames []string) {
+	resolver := newProviderResolver(ctx, wa, wirePkgPath, injectorNames, injector.Root)
+	injector.Graph = resolver.resolveRoot(injector.Root, injector.RootIsPointer)
+	injector.Inputs = injector.Graph.Inputs
+	for _, ambiguous := range injector.Graph.Ambiguous {
+		injector.Diagnostics = append(injector.Diagnostics, ambiguous.diagnostic(injector.Position))
+	}
+
+	// 提供関数がerrorやクリーンアップ関数を返す場合は注入関数も返す必要がある
+	for _, provider := range injector.Graph.Providers {
+		injector.ReturnsError = injector.ReturnsError || provider.Function.ReturnsError
+		injector.HasCleanup = injector.HasCleanup || provider.Function.HasCleanup
+	}
+}
+
+// SkipReason は依存グラフを作らなかった理由を返す（依存グラフがある場合は空）
+func (inj *InjectorInfo) SkipReason() string {
+	switch {
+	case inj.Graph != nil:
+		return ""
+	case inj.Cancelled:
+		return "analysis was cancelled"
+	case inj.Root.Diagnostic != nil:
+		return inj.Root.Diagnostic.String()
+	case inj.Root.SkipReason != "":
+		return inj.Root.SkipReason
+	}
+	if diagnostic := inj.loadError(); diagnostic != nil {
+		return diagnostic.String()
+	}
+	return ""
+}
+
+// loadError はツリーで見つかった読み込みのエラーを返す（なければnil）
+func (inj *InjectorInfo) loadError() *Diagnostic {
+	for i := range inj.Diagnostics {
+		if inj.Diagnostics[i].Code == CodeLoadError {
+			return &inj.Diagnostics[i]
+		}
+	}
+	return nil
+}
+
+// Signature は引数を含めた注入関数のシグネチャを生成する
+// 例: func InitializeUserHandler(ctx context.Context, dsn string) (*ControllerSet, error)
+func (inj *InjectorInfo) Signature() string {
+	params := make([]string, 0, len(inj.Inputs))
+	for _, input := range inj.Inputs {
+		params = append(params, input.Name+" "+input.Type.Qualified(inj.PackagePath))
+	}
+
+	root := TypeRef{
+		TypeName:    inj.Root.StructName,
+		PackagePath: inj.Root.PackagePath,
+		PackageName: inj.Root.PackageName,
+		IsPointer:   inj.RootIsPointer,
+	}
+	results := []string{root.Qualified(inj.PackagePath)}
+	if inj.HasCleanup {
+		results = append(results, "func()")
+	}
+	if inj.ReturnsError {
+		results = append(results, "error")
+	}
+
+	resultList := results[0]
+	if len(results) > 1 {
+		resultList = "(" + strings.Join(results, ", ") + ")"
+	}
+
+	return fmt.Sprintf("func %s(%s) %s", inj.Name, strings.Join(params, ", "), resultList)
+}
+
+// VariableName は型の値を保持する変数名を型名から作る（例: UserRepository -> userRepository）
+func VariableName(ref TypeRef) string {
+	return inputName(ref, "")
+}
+
+// inputName は引数名を決める（名前のヒントがなければ型名から作る）
+func inputName(ref TypeRef, nameHint string) string {
+	if nameHint != "" && nameHint != "_" {
+		return lowerFirst(nameHint)
+	}
+
+	name := ref.TypeName
+	if ref.PackagePath == "" {
+		// 名前のない型は英字のみを残す（例: "[]string" -> "string"）
+		name = strings.TrimLeftFunc(ref.TypeString, func(r rune) bool { return !unicode.IsLetter(r) })
+		if i := strings.IndexFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }); i >= 0 {
+			name = name[:i]
+		}
+	}
+	if name == "" {
+		return "arg"
+	}
+	return lowerFirst(name)
+}
+
+// lowerFirst は先頭の大文字の並びを小文字にした名前を返す（例: "DSN" -> "dsn", "HTTPClient" -> "httpClient"）
+func lowerFirst(name string) string {
+	runes := []rune(name)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		// 略語の直後に単語が続く場合、単語の先頭は大文字のまま残す
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
@@ -0,0 +1,225 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWireAnalyzer_AnalyzeInjectors(t *testing.T) {
+	tests := []struct {
+		name          string
+		workDir       string
+		wireFilePath  string
+		injectorName  string
+		wantInputs    []string // "名前 型" の形式
+		wantSignature string
+	}{
+		{
+			name:          "全ての依存が提供関数で解決できる場合は引数なし",
+			workDir:       "../../sample/basic",
+			wireFilePath:  "../../sample/basic/wire.go",
+			injectorName:  "InitializeUserHandler",
+			wantInputs:    nil,
+			wantSignature: "func InitializeUserHandler() (*ControllerSet, error)",
+		},
+		{
+			name:         "提供関数で作れない値は引数になる",
+			workDir:      "../../testdata/inputs",
+			wireFilePath: "../../testdata/inputs/wire.go",
+			injectorName: "InitializeApp",
+			wantInputs: []string{
+				"ctx context.Context",
+				"dsn string",
+				"timeout time.Duration",
+				"client *http.Client",
+			},
+			wantSignature: "func InitializeApp(ctx context.Context, dsn string, timeout time.Duration, client *http.Client) (*App, func(), error)",
+		},
+		{
+			name:          "構造体のフィールドで供給できる値は引数にならない",
+			workDir:       "../../testdata/fieldsof",
+			wireFilePath:  "../../testdata/fieldsof/wire.go",
+			injectorName:  "InitializeApp",
+			wantInputs:    nil,
+			wantSignature: "func InitializeApp() *App",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewWireAnalyzer(tt.workDir, "./...")
+			injectors, err := analyzer.AnalyzeInjectors(tt.wireFilePath)
+			if err != nil {
+				t.Fatalf("AnalyzeInjectors failed: %v", err)
+			}
+
+			var injector *InjectorInfo
+			for _, inj := range injectors {
+				if inj.Name == tt.injectorName {
+					injector = inj
+				}
+			}
+			if injector == nil {
+				t.Fatalf("injector %s not found", tt.injectorName)
+			}
+			if injector.Root.Skipped {
+				t.Fatalf("root skipped: %s", injector.Root.SkipReason)
+			}
+
+			var gotInputs []string
+			for _, input := range injector.Inputs {
+				gotInputs = append(gotInputs, input.Name+" "+input.Type.Qualified(injector.PackagePath))
+			}
+			if len(gotInputs) != len(tt.wantInputs) {
+				t.Fatalf("Inputs = %v, want %v", gotInputs, tt.wantInputs)
+			}
+			for i := range gotInputs {
+				if gotInputs[i] != tt.wantInputs[i] {
+					t.Errorf("Inputs[%d] = %s, want %s", i, gotInputs[i], tt.wantInputs[i])
+				}
+			}
+
+			if got := injector.Signature(); got != tt.wantSignature {
+				t.Errorf("Signature() = %s, want %s", got, tt.wantSignature)
+			}
+		})
+	}
+}
+
+func TestWireAnalyzer_AnalyzeInjectors_FieldsOf(t *testing.T) {
+	analyzer := NewWireAnalyzer("../../testdata/fieldsof", "./...")
+	injectors, err := analyzer.AnalyzeInjectors("../../testdata/fieldsof/wire.go")
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+	graph := injectors[0].Graph
+
+	if len(graph.FieldsOf) != 1 {
+		t.Fatalf("FieldsOf = %+v, want 1 struct", graph.FieldsOf)
+	}
+	fieldsOf := graph.FieldsOf[0]
+	if fieldsOf.Struct.Key() != "example.com/fieldsof/config.Config" || !fieldsOf.Struct.IsPointer {
+		t.Errorf("Struct = %+v", fieldsOf.Struct)
+	}
+	var fields []string
+	for _, field := range fieldsOf.Fields {
+		fields = append(fields, field.Name)
+	}
+	if strings.Join(fields, ",") != "DSN,MaxPoolSize" {
+		t.Errorf("Fields = %v, want [DSN MaxPoolSize]", fields)
+	}
+
+	// フィールドを使うNewDBはNewConfigより後に並ぶ
+	var order []string
+	for _, provider := range graph.Providers {
+		order = append(order, provider.Function.Name)
+	}
+	if strings.Join(order, ",") != "NewConfig,NewDB,NewRepository" {
+		t.Errorf("Providers = %v", order)
+	}
+}
+
+func TestWireAnalyzer_AnalyzeInjectors_InputNamesAvoidPackages(t *testing.T) {
+	// NewStore(db *sql.DB)の引数名dbは、注入関数から参照するパッケージdbと重なるため付け直す
+	analyzer := NewWireAnalyzer("../../testdata/shadow", "./...")
+	injectors, err := analyzer.AnalyzeInjectors("../../testdata/shadow/wire.go")
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+
+	want := "func InitializeStore(db2 *sql.DB) *db.Store"
+	if got := injectors[0].Signature(); got != want {
+		t.Errorf("Signature() = %s, want %s", got, want)
+	}
+}
+
+func TestWireAnalyzer_AnalyzeInjectors_PointerMismatch(t *testing.T) {
+	// NewConfigはConfigの値を返すため、*Configを必要とするNewRepoには渡せない
+	analyzer := NewWireAnalyzer("../../testdata/pointers", "./...")
+	injectors, err := analyzer.AnalyzeInjectors("../../testdata/pointers/wire.go")
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+	graph := injectors[0].Graph
+
+	if len(graph.Unsatisfied) != 1 {
+		t.Fatalf("Unsatisfied = %+v, want 1", graph.Unsatisfied)
+	}
+	want := "no provider found for *app.Config, needed by app.NewRepo (app.NewConfig provides app.Config)"
+	if got := graph.Unsatisfied[0].String(); got != want {
+		t.Errorf("Unsatisfied[0] = %s, want %s", got, want)
+	}
+	for _, provider := range graph.Providers {
+		if provider.Function.Name == "NewConfig" {
+			t.Errorf("Providers contains NewConfig for *Config")
+		}
+	}
+}
+
+func TestWireAnalyzer_AnalyzeInjectors_AmbiguousProvider(t *testing.T) {
+	// NewSvcとNewSvcAltの両方が*Svcを返すため、どちらを使うか決められない
+	analyzer := NewWireAnalyzer("../../testdata/ambiguous", "./...")
+	injectors, err := analyzer.AnalyzeInjectors("../../testdata/ambiguous/wire.go")
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+	injector := injectors[0]
+
+	if len(injector.Graph.Ambiguous) != 1 {
+		t.Fatalf("Ambiguous = %+v, want 1", injector.Graph.Ambiguous)
+	}
+	want := "multiple providers found for *app.Svc, needed by app.NewHandler (app.NewSvc, app.NewSvcAlt)"
+	if got := injector.Graph.Ambiguous[0].String(); got != want {
+		t.Errorf("Ambiguous[0] = %s, want %s", got, want)
+	}
+
+	if len(injector.Diagnostics) != 1 {
+		t.Fatalf("Diagnostics = %+v, want 1", injector.Diagnostics)
+	}
+	diagnostic := injector.Diagnostics[0]
+	if diagnostic.Code != CodeAmbiguousProvider || diagnostic.Severity != SeverityError || len(diagnostic.Candidates) != 2 {
+		t.Errorf("diagnostic = %+v", diagnostic)
+	}
+	if diagnostic.Position != injector.Position {
+		t.Errorf("Position = %v, want the injector %v", diagnostic.Position, injector.Position)
+	}
+}
+
+func TestWireAnalyzer_AnalyzeInjectorsByName(t *testing.T) {
+	analyzer := NewWireAnalyzer("../../testdata/multi", "./...")
+	injectors, err := analyzer.AnalyzeInjectorsByName("../../testdata/multi/wire.go", "InitializeWorker")
+	if err != nil {
+		t.Fatalf("AnalyzeInjectorsByName failed: %v", err)
+	}
+
+	// 指定した注入関数だけを解析する
+	if len(injectors) != 1 || injectors[0].Name != "InitializeWorker" {
+		t.Fatalf("injectors = %v, want only InitializeWorker", injectors)
+	}
+	if injectors[0].Graph == nil {
+		t.Errorf("InitializeWorker was not analyzed: %s", injectors[0].Root.SkipReason)
+	}
+}
+
+func TestInputName(t *testing.T) {
+	tests := []struct {
+		name     string
+		ref      TypeRef
+		nameHint string
+		expected string
+	}{
+		{"名前のヒントを使う", TypeRef{TypeName: "string", TypeString: "string"}, "DSN", "dsn"},
+		{"ヒントがなければ型名", TypeRef{TypeName: "Context", PackagePath: "context"}, "", "context"},
+		{"ブランク識別子は無視", TypeRef{TypeName: "Duration", PackagePath: "time"}, "_", "duration"},
+		{"名前のない型", TypeRef{TypeName: "[]string", TypeString: "[]string"}, "", "string"},
+		{"略語で始まる型名", TypeRef{TypeName: "HTTPClient", PackagePath: "example.com/http"}, "", "httpClient"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inputName(tt.ref, tt.nameHint); got != tt.expected {
+				t.Errorf("inputName() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"sync"
+)
+
+// memo はキーごとに一度だけ計算した結果を保持する（複数のgoroutineから安全に使える）
+// 計算中のキーを要求された場合は、同じ計算を重ねて実行せずに結果を待つ
+type memo[V any] struct {
+	mu    sync.Mutex
+	calls map[string]*memoCall[V]
+}
+
+// memoCall は1つのキーの計算
+type memoCall[V any] struct {
+	done      chan struct{} // 計算が終わると閉じる
+	val       V
+	err       error
+	cancelled bool // 計算したgoroutineのctxが取り消されたかどうか（結果は保持しない）
+}
+
+// do はキーの結果を返す（まだ計算されていない場合はfnで計算する）
+// エラーも結果として保持し、同じキーで計算し直さない
+// ただしctxが取り消されて失敗した計算は保持せず、次に要求されたときに計算し直す
+func (m *memo[V]) do(ctx context.Context, key string, fn func() (V, error)) (V, error) {
+	for {
+		m.mu.Lock()
+		if m.calls == nil {
+			m.calls = make(map[string]*memoCall[V])
+		}
+		if call, ok := m.calls[key]; ok {
+			m.mu.Unlock()
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				var zero V
+				return zero, ctx.Err()
+			}
+			if call.cancelled {
+				// 別のgoroutineの計算が中断された場合は自分で計算し直す
+				continue
+			}
+			return call.val, call.err
+		}
+		call := &memoCall[V]{done: make(chan struct{})}
+		m.calls[key] = call
+		m.mu.Unlock()
+
+		call.val, call.err = fn()
+		if call.err != nil && ctx.Err() != nil {
+			call.cancelled = true
+			m.mu.Lock()
+			delete(m.calls, key)
+			m.mu.Unlock()
+		}
+		close(call.done)
+		return call.val, call.err
+	}
+}
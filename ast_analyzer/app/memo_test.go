@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMemo_Do(t *testing.T) {
+	ctx := context.Background()
+	var m memo[int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	// 計算中に同じキーを要求したgoroutineは計算を重ねずに結果を待つ
+	var wg sync.WaitGroup
+	results := make([]int, 8)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = m.do(ctx, "key", func() (int, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			})
+		}()
+	}
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("fn called %d times, want 1", got)
+	}
+	for i, got := range results {
+		if got != 42 {
+			t.Errorf("results[%d] = %d, want 42", i, got)
+		}
+	}
+
+	// エラーも結果として保持する
+	wantErr := errors.New("failed")
+	if _, err := m.do(ctx, "error", func() (int, error) { return 0, wantErr }); err != wantErr {
+		t.Fatalf("do() error = %v, want %v", err, wantErr)
+	}
+	if _, err := m.do(ctx, "error", func() (int, error) { return 1, nil }); err != wantErr {
+		t.Errorf("second do() error = %v, want cached %v", err, wantErr)
+	}
+
+	// ctxが取り消されて失敗した計算は保持しない
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := m.do(cancelled, "cancel", func() (int, error) { return 0, cancelled.Err() }); !errors.Is(err, context.Canceled) {
+		t.Fatalf("do() with cancelled ctx error = %v, want context.Canceled", err)
+	}
+	if got, err := m.do(ctx, "cancel", func() (int, error) { return 7, nil }); err != nil || got != 7 {
+		t.Errorf("do() after cancellation = %d, %v, want 7, nil", got, err)
+	}
+
+	// 計算を待っている間にctxが取り消された場合は待つのをやめる
+	block := make(chan struct{})
+	go m.do(ctx, "slow", func() (int, error) {
+		<-block
+		return 1, nil
+	})
+	for {
+		m.mu.Lock()
+		_, started := m.calls["slow"]
+		m.mu.Unlock()
+		if started {
+			break
+		}
+	}
+	if _, err := m.do(cancelled, "slow", func() (int, error) { return 2, nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("do() waiting with cancelled ctx error = %v, want context.Canceled", err)
+	}
+	close(block)
+}
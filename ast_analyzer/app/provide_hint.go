@@ -0,0 +1,79 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+)
+
+// newCollectionNode はスライス・配列・マップ型のフィールドからノードを作成する
+func newCollectionNode(field packages.FieldInfo) *CollectionNode {
+	return &CollectionNode{
+		FieldName:   field.Name,
+		Position:    field.Position,
+		TypeString:  field.TypeString,
+		TypeName:    namedTypeName(field),
+		PackagePath: field.PackagePath,
+		ElemType:    field.ElemType,
+		KeyType:     field.KeyType,
+		ProvideHint: fmt.Sprintf(
+			"wire cannot aggregate %s automatically: add a provider returning %s that collects the elements, or bind a literal with wire.Value(%s{...})",
+			field.TypeString, field.TypeString, field.TypeString),
+	}
+}
+
+// newFuncNode は関数型のフィールドからノードを作成する
+func newFuncNode(field packages.FieldInfo) *FuncNode {
+	hint := fmt.Sprintf(
+		"add a provider returning %s, or bind an existing function with wire.Value",
+		field.TypeString)
+	if namedTypeName(field) == "" {
+		// 名前のない関数型は他の依存と衝突しやすい
+		hint += fmt.Sprintf("; consider a named type (e.g. type %s %s) so the provider is unambiguous",
+			exportedName(field.Name), field.TypeString)
+	}
+
+	return &FuncNode{
+		FieldName:   field.Name,
+		Position:    field.Position,
+		TypeString:  field.TypeString,
+		TypeName:    namedTypeName(field),
+		PackagePath: field.PackagePath,
+		ProvideHint: hint,
+	}
+}
+
+// newChanNode はチャネル型のフィールドからノードを作成する
+func newChanNode(field packages.FieldInfo) *ChanNode {
+	return &ChanNode{
+		FieldName:   field.Name,
+		Position:    field.Position,
+		TypeString:  field.TypeString,
+		TypeName:    namedTypeName(field),
+		PackagePath: field.PackagePath,
+		ElemType:    field.ElemType,
+		ProvideHint: fmt.Sprintf(
+			"add a provider returning %s that creates the channel with make (wire.Value cannot be used because the channel must be created at runtime)",
+			field.TypeString),
+	}
+}
+
+// namedTypeName はNamed型の場合のみ型名を返す
+func namedTypeName(field packages.FieldInfo) string {
+	if field.PackagePath == "" {
+		return ""
+	}
+	return field.TypeName
+}
+
+// exportedName は先頭を大文字にした名前を返す
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	first := name[0]
+	if first >= 'a' && first <= 'z' {
+		first -= 'a' - 'A'
+	}
+	return string(first) + name[1:]
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWireAnalyzer_AnalyzeStruct_FieldKinds(t *testing.T) {
+	analyzer := NewWireAnalyzer("../../testdata/fieldkinds", "./...")
+
+	result, err := analyzer.analyzeStruct(context.Background(), "example.com/fieldkinds", "App")
+	if err != nil {
+		t.Fatalf("analyzeStruct failed: %v", err)
+	}
+
+	printStructAnalysis(t, result, 0)
+
+	fields := make(map[string]FieldNode)
+	for _, field := range result.Fields {
+		fields[field.GetFieldName()] = field
+	}
+
+	tests := []struct {
+		fieldName      string
+		wantNodeType   NodeType
+		wantTypeString string
+		wantHint       string
+	}{
+		{"plugins", NodeTypeCollection, "[]fieldkinds.Plugin", "provider returning []fieldkinds.Plugin"},
+		{"handlers", NodeTypeCollection, "map[string]fieldkinds.Handler", "wire.Value(map[string]fieldkinds.Handler{...})"},
+		{"named", NodeTypeCollection, "fieldkinds.Plugins", "provider returning fieldkinds.Plugins"},
+		{"fixed", NodeTypeCollection, "[2]fieldkinds.Plugin", "provider returning [2]fieldkinds.Plugin"},
+		{"now", NodeTypeFunc, "func() time.Time", "type Now func() time.Time"},
+		{"clock", NodeTypeFunc, "fieldkinds.Clock", "provider returning fieldkinds.Clock"},
+		{"events", NodeTypeChan, "chan fieldkinds.Event", "make"},
+		{"outbox", NodeTypeChan, "chan<- fieldkinds.Event", "provider returning chan<- fieldkinds.Event"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fieldName, func(t *testing.T) {
+			field, ok := fields[tt.fieldName]
+			if !ok {
+				t.Fatalf("field %s not found", tt.fieldName)
+			}
+			if field.NodeType() != tt.wantNodeType {
+				t.Fatalf("NodeType() = %v, want %v", field.NodeType(), tt.wantNodeType)
+			}
+
+			var typeString, hint string
+			switch node := field.(type) {
+			case *CollectionNode:
+				typeString, hint = node.TypeString, node.ProvideHint
+			case *FuncNode:
+				typeString, hint = node.TypeString, node.ProvideHint
+			case *ChanNode:
+				typeString, hint = node.TypeString, node.ProvideHint
+			}
+
+			if typeString != tt.wantTypeString {
+				t.Errorf("TypeString = %s, want %s", typeString, tt.wantTypeString)
+			}
+			if !strings.Contains(hint, tt.wantHint) {
+				t.Errorf("ProvideHint = %q, want to contain %q", hint, tt.wantHint)
+			}
+		})
+	}
+
+	// 名前付き関数型には型名を提案しない
+	if clock := fields["clock"].(*FuncNode); strings.Contains(clock.ProvideHint, "named type") {
+		t.Errorf("named func type should not suggest a named type: %q", clock.ProvideHint)
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"now", "Now"},
+		{"Clock", "Clock"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := exportedName(tt.input); got != tt.expected {
+			t.Errorf("exportedName(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,622 @@
+package app
+
+import (
+	"context"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+)
+
+// TypeRef は依存グラフ上の型を表す
+type TypeRef struct {
+	TypeName    string              // 型名（名前のない型の場合は型の文字列表現）
+	PackagePath string              // パッケージパス（名前のない型の場合は空）
+	PackageName string              // パッケージ名（名前のない型の場合は空、分からない場合も空）
+	TypeString  string              // パッケージ名で修飾した型の文字列表現
+	IsPointer   bool                // ポインタ型かどうか
+	IsInterface bool                // インターフェース型かどうか
+	Origin      packages.TypeOrigin // 型が定義されている場所の分類
+}
+
+// Key は型を同一視するためのキーを返す（ポインタかどうかは区別しない）
+func (t TypeRef) Key() string {
+	if t.PackagePath == "" {
+		return strings.TrimPrefix(t.TypeString, "*")
+	}
+	return t.PackagePath + "." + t.TypeName
+}
+
+// Qualified はpkgPathのパッケージから参照する場合の型の文字列表現を返す
+func (t TypeRef) Qualified(pkgPath string) string {
+	if t.PackagePath == "" {
+		return t.TypeString
+	}
+
+	name := t.TypeName
+	if t.PackagePath != pkgPath {
+		name = packageName(t.PackagePath, t.PackageName) + "." + name
+	}
+	if t.IsPointer {
+		name = "*" + name
+	}
+	return name
+}
+
+// packageName はパッケージ名を返す（分からない場合はimportパスの末尾で代用する）
+func packageName(pkgPath, name string) string {
+	if name != "" {
+		return name
+	}
+	return path.Base(pkgPath)
+}
+
+// typeRefFromField はフィールドや引数の型情報からTypeRefを作成する
+func typeRefFromField(field packages.FieldInfo) TypeRef {
+	return TypeRef{
+		TypeName:    field.TypeName,
+		PackagePath: field.PackagePath,
+		PackageName: field.PackageName,
+		TypeString:  field.TypeString,
+		IsPointer:   field.IsPointer,
+		IsInterface: field.IsInterface,
+		Origin:      field.Origin,
+	}
+}
+
+// typeRefFromNode はフィールドノードの型からTypeRefを作成する
+func typeRefFromNode(node FieldNode) TypeRef {
+	switch n := node.(type) {
+	case *StructNode:
+		return TypeRef{
+			TypeName:    n.StructName,
+			PackagePath: n.PackagePath,
+			PackageName: n.PackageName,
+			TypeString:  qualifiedTypeString(n.PackagePath, n.PackageName, n.StructName, !n.IsValue),
+			IsPointer:   !n.IsValue,
+			Origin:      packages.OriginModuleLocal,
+		}
+	case *InterfaceNode:
+		return TypeRef{
+			TypeName:    n.TypeName,
+			PackagePath: n.PackagePath,
+			PackageName: n.PackageName,
+			TypeString:  packageName(n.PackagePath, n.PackageName) + "." + n.TypeName,
+			IsInterface: true,
+			Origin:      packages.OriginModuleLocal,
+		}
+	case *InputNode:
+		return TypeRef{
+			TypeName:    n.TypeName,
+			PackagePath: n.PackagePath,
+			PackageName: n.PackageName,
+			TypeString:  n.TypeString,
+			IsPointer:   n.IsPointer,
+			Origin:      n.Origin,
+		}
+	case *CollectionNode:
+		return TypeRef{TypeName: n.TypeName, PackagePath: n.PackagePath, TypeString: n.TypeString}
+	case *FuncNode:
+		return TypeRef{TypeName: n.TypeName, PackagePath: n.PackagePath, TypeString: n.TypeString}
+	case *ChanNode:
+		return TypeRef{TypeName: n.TypeName, PackagePath: n.PackagePath, TypeString: n.TypeString}
+	}
+	return TypeRef{}
+}
+
+// ResolvedProvider は依存グラフ上で使われる提供関数を表す
+type ResolvedProvider struct {
+	Function InitFunctionInfo // 提供関数
+	Provides TypeRef          // 提供する型
+	Args     []TypeRef        // 引数の型
+}
+
+// AmbiguousProvider は同じ型を返す提供関数が複数あり、どれを使うか決められない依存を表す
+// wireと同様に、同じ型の提供関数が複数あると注入関数を生成できない
+type AmbiguousProvider struct {
+	Type      TypeRef            // 必要な型
+	NeededBy  InitFunctionInfo   // 必要としている提供関数（ルートや構造体のフィールドの場合はゼロ値）
+	Providers []InitFunctionInfo // 型を返す提供関数
+}
+
+// String は候補の提供関数を含めた説明を返す
+// 例: "multiple providers found for *app.Svc, needed by app.NewHandler (app.NewSvc, app.NewSvcAlt)"
+func (a AmbiguousProvider) String() string {
+	var b strings.Builder
+	b.WriteString("multiple providers found for " + a.Type.TypeString)
+	if a.NeededBy.Name != "" {
+		b.WriteString(", needed by " + packageName(a.NeededBy.PackagePath, a.NeededBy.PackageName) + "." + a.NeededBy.Name)
+	}
+	names := make([]string, 0, len(a.Providers))
+	for _, provider := range a.Providers {
+		names = append(names, packageName(provider.PackagePath, provider.PackageName)+"."+provider.Name)
+	}
+	b.WriteString(" (" + strings.Join(names, ", ") + ")")
+	return b.String()
+}
+
+// diagnostic は注入関数の位置に付ける診断を作成する（候補は提供関数の宣言の位置）
+func (a AmbiguousProvider) diagnostic(pos token.Position) Diagnostic {
+	d := newDiagnostic(CodeAmbiguousProvider, pos, a.String(), a.Type)
+	for _, provider := range a.Providers {
+		d.Candidates = append(d.Candidates, Candidate{
+			Type:                typeRefFromField(provider.Result),
+			Function:            provider.Name,
+			FunctionPackagePath: provider.PackagePath,
+			Position:            provider.Position,
+		})
+	}
+	return *d
+}
+
+// UnsatisfiedDependency は同じ型を返す提供関数はあるが、ポインタかどうかが一致しないため用意できない値を表す
+// wireと同様に、Configを返す提供関数で*Configの引数を満たすことはできない
+type UnsatisfiedDependency struct {
+	Type      TypeRef            // 必要な型
+	NeededBy  InitFunctionInfo   // 必要としている提供関数（ルートや構造体のフィールドの場合はゼロ値）
+	Providers []InitFunctionInfo // ポインタかどうかが異なる型を返す提供関数
+}
+
+// String はwireと同じ形式で説明を返す
+// 例: "no provider found for *app.Config, needed by app.NewRepo (app.NewConfig provides app.Config)"
+func (u UnsatisfiedDependency) String() string {
+	var b strings.Builder
+	b.WriteString("no provider found for " + u.Type.TypeString)
+	if u.NeededBy.Name != "" {
+		b.WriteString(", needed by " + packageName(u.NeededBy.PackagePath, u.NeededBy.PackageName) + "." + u.NeededBy.Name)
+	}
+	provided := make([]string, 0, len(u.Providers))
+	for _, provider := range u.Providers {
+		provided = append(provided, packageName(provider.PackagePath, provider.PackageName)+"."+provider.Name+" provides "+provider.Result.TypeString)
+	}
+	if len(provided) > 0 {
+		b.WriteString(" (" + strings.Join(provided, ", ") + ")")
+	}
+	return b.String()
+}
+
+// Binding はインターフェースと、それを満たすために使われる実装型の対応（wire.Bindに相当）
+type Binding struct {
+	Interface TypeRef // インターフェース型
+	Impl      TypeRef // 実装型
+}
+
+// StructField はwire.Structでルートを組み立てる場合のフィールドを表す
+type StructField struct {
+	Name string  // フィールド名
+	Type TypeRef // フィールドの型
+}
+
+// VariableName はフィールドの値を保持する変数名を返す（例: DSN -> dsn）
+func (f StructField) VariableName() string {
+	return lowerFirst(f.Name)
+}
+
+// FieldsOf はグラフ内の構造体のフィールドから供給する値（wire.FieldsOfに相当）
+type FieldsOf struct {
+	Struct TypeRef       // フィールドを持つ構造体（提供関数が返す型）
+	Fields []StructField // 供給するフィールド
+}
+
+// ProviderGraph は注入関数のルートから辿った提供関数の依存グラフ
+type ProviderGraph struct {
+	Providers    []*ResolvedProvider     // 依存される側から順に並んだ提供関数
+	Bindings     []Binding               // インターフェースを返す提供関数がなく、実装型で満たすインターフェース
+	Inputs       []InjectorInput         // どの提供関数でも作れないため引数として渡す必要がある値
+	StructFields []StructField           // ルートを返す提供関数がない場合にwire.Structで埋めるフィールド
+	FieldsOf     []FieldsOf              // 引数の代わりに構造体のフィールドから供給する値
+	Values       []Value                 // 引数の代わりに設定した値で供給する型（wire.Value, wire.InterfaceValue）
+	Conflicts    []TypeConflict          // 名前のない型を複数の意味で必要としている箇所
+	Unsatisfied  []UnsatisfiedDependency // 提供関数が返す型とポインタかどうかが一致しない依存
+	Ambiguous    []AmbiguousProvider     // 同じ型を返す提供関数が複数ある依存（グラフは先頭の提供関数で組み立てる）
+}
+
+// providerResolver はルートから提供関数を辿って依存グラフを作成する
+type providerResolver struct {
+	ctx         context.Context
+	wa          *WireAnalyzer
+	wirePkgPath string
+	excluded    map[string]bool        // 提供関数として扱わない関数（注入関数）
+	bindings    map[string]*StructNode // インターフェースから解決された実装型
+	done        map[string]bool        // 解決済みの型
+	visiting    map[string]bool        // 解決中の型（循環防止）
+	inputNames  map[string]bool        // 使用済みの引数名
+	graph       *ProviderGraph
+}
+
+// newProviderResolver はルートの解析結果からリゾルバを作成する
+func newProviderResolver(ctx context.Context, wa *WireAnalyzer, wirePkgPath string, injectorNames []string, root *StructNode) *providerResolver {
+	excluded := make(map[string]bool, len(injectorNames))
+	for _, name := range injectorNames {
+		excluded[wirePkgPath+"."+name] = true
+	}
+
+	r := &providerResolver{
+		ctx:         ctx,
+		wa:          wa,
+		wirePkgPath: wirePkgPath,
+		excluded:    excluded,
+		bindings:    make(map[string]*StructNode),
+		done:        make(map[string]bool),
+		visiting:    make(map[string]bool),
+		inputNames:  make(map[string]bool),
+		graph:       &ProviderGraph{},
+	}
+	r.collectBindings(root, make(map[*StructNode]bool))
+	return r
+}
+
+// collectBindings はツリーからインターフェースと実装型の対応を集める
+func (r *providerResolver) collectBindings(node *StructNode, seen map[*StructNode]bool) {
+	if node == nil || seen[node] {
+		return
+	}
+	seen[node] = true
+
+	for _, field := range node.Fields {
+		switch f := field.(type) {
+		case *StructNode:
+			r.collectBindings(f, seen)
+		case *InterfaceNode:
+			if f.ResolvedStruct != nil {
+				r.bindings[typeRefFromNode(f).Key()] = f.ResolvedStruct
+				r.collectBindings(f.ResolvedStruct, seen)
+			}
+		}
+	}
+}
+
+// resolveRoot はルートの構造体を解決する（pointerは注入関数がポインタを返すかどうか）
+// ルートを返す提供関数がない場合はwire.Structと同様にフィールドから組み立てる
+func (r *providerResolver) resolveRoot(root *StructNode, pointer bool) *ProviderGraph {
+	rootRef := typeRefFromNode(root)
+	rootRef.IsPointer = pointer
+	rootRef.TypeString = qualifiedTypeString(root.PackagePath, root.PackageName, root.StructName, pointer)
+	if len(r.providersFor(rootRef)) > 0 {
+		r.resolve(rootRef, "", InitFunctionInfo{})
+	} else {
+		for _, field := range root.Fields {
+			fieldRef := typeRefFromNode(field)
+			r.graph.StructFields = append(r.graph.StructFields, StructField{
+				Name: field.GetFieldName(),
+				Type: fieldRef,
+			})
+			r.resolve(fieldRef, field.GetFieldName(), InitFunctionInfo{})
+		}
+	}
+
+	r.resolveFieldsOf()
+	r.detectConflicts(root)
+	r.avoidPackageNames(root)
+	return r.graph
+}
+
+// resolve は型を提供する関数を辿り、提供できない型は引数として記録する（neededByは型を必要とする提供関数）
+func (r *providerResolver) resolve(ref TypeRef, nameHint string, neededBy InitFunctionInfo) {
+	key := ref.Key()
+	if key == "" || r.done[key] || r.visiting[key] {
+		return
+	}
+	r.visiting[key] = true
+	defer func() {
+		delete(r.visiting, key)
+		r.done[key] = true
+	}()
+
+	// 値が設定されている場合は提供関数より優先する
+	if value, ok := r.wa.lookupValue(ref); ok {
+		value.Type = ref
+		r.graph.Values = append(r.graph.Values, value)
+		return
+	}
+
+	// 提供関数がある場合は引数を先に解決する
+	if providers := r.providersFor(ref); len(providers) > 0 {
+		matched := matchPointer(providers, ref.IsPointer)
+		if len(matched) == 0 {
+			r.graph.Unsatisfied = append(r.graph.Unsatisfied, UnsatisfiedDependency{Type: ref, NeededBy: neededBy, Providers: providers})
+			return
+		}
+		if len(matched) > 1 {
+			r.graph.Ambiguous = append(r.graph.Ambiguous, AmbiguousProvider{Type: ref, NeededBy: neededBy, Providers: matched})
+		}
+		provider := matched[0]
+		resolved := &ResolvedProvider{
+			Function: provider,
+			Provides: typeRefFromField(provider.Result),
+		}
+		for _, param := range provider.Params {
+			argRef := typeRefFromField(param)
+			resolved.Args = append(resolved.Args, argRef)
+			r.resolve(argRef, param.Name, provider)
+		}
+		r.graph.Providers = append(r.graph.Providers, resolved)
+		return
+	}
+
+	// インターフェースの実装型が解決されている場合は実装型を辿る
+	if impl, ok := r.bindings[key]; ok {
+		implRef := typeRefFromNode(impl)
+		r.graph.Bindings = append(r.graph.Bindings, Binding{Interface: ref, Impl: implRef})
+		r.resolve(implRef, nameHint, neededBy)
+		return
+	}
+
+	r.addInput(ref, nameHint)
+}
+
+// providersFor は型を返す提供関数を探す（wire.goや他のパッケージのwire.goの注入関数は除く）
+func (r *providerResolver) providersFor(ref TypeRef) []InitFunctionInfo {
+	if ref.PackagePath == "" {
+		// 名前のない型は提供関数を特定できない
+		return nil
+	}
+
+	initFuncs, err := r.wa.findTypeProviders(r.ctx, ref.PackagePath, ref.TypeName)
+	if err != nil {
+		return nil
+	}
+
+	providers := make([]InitFunctionInfo, 0, len(initFuncs))
+	for _, initFunc := range initFuncs {
+		if !r.excluded[initFunc.PackagePath+"."+initFunc.Name] && !r.wa.isForeignInjector(r.ctx, initFunc) {
+			providers = append(providers, initFunc)
+		}
+	}
+	return providers
+}
+
+// matchPointer は提供関数のうち、ポインタかどうかが必要な型と一致するものを返す
+// インターフェース型はポインタにならないため、そのまま返す
+func matchPointer(providers []InitFunctionInfo, pointer bool) []InitFunctionInfo {
+	var matched []InitFunctionInfo
+	for _, provider := range providers {
+		if provider.Result.IsInterface || provider.Result.IsPointer == pointer {
+			matched = append(matched, provider)
+		}
+	}
+	return matched
+}
+
+// addInput は注入関数の引数として渡す値を記録する
+func (r *providerResolver) addInput(ref TypeRef, nameHint string) {
+	name := r.uniqueInputName(inputName(ref, nameHint))
+	r.graph.Inputs = append(r.graph.Inputs, InjectorInput{
+		Name: name,
+		Type: ref,
+	})
+}
+
+// uniqueInputName は引数名が重複しないように連番を付ける
+func (r *providerResolver) uniqueInputName(name string) string {
+	candidate := name
+	for i := 2; r.inputNames[candidate] || token.IsKeyword(candidate); i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	r.inputNames[candidate] = true
+	return candidate
+}
+
+// avoidPackageNames は注入関数のパッケージと、シグネチャや本体で参照するパッケージの名前と重なる引数名を付け直す
+// 例: パッケージdbのNewStore(db *sql.DB)の引数をdbとすると、注入関数の中のdb.NewStoreが引数を指してしまう
+func (r *providerResolver) avoidPackageNames(root *StructNode) {
+	reserved := r.referencedPackageNames(root)
+	for i, input := range r.graph.Inputs {
+		if !reserved[input.Name] {
+			continue
+		}
+		delete(r.inputNames, input.Name)
+		candidate := input.Name
+		for n := 2; r.inputNames[candidate] || reserved[candidate]; n++ {
+			candidate = input.Name + strconv.Itoa(n)
+		}
+		r.inputNames[candidate] = true
+		r.graph.Inputs[i].Name = candidate
+	}
+}
+
+// referencedPackageNames は注入関数のパッケージと、依存グラフの型や提供関数が属するパッケージの名前を集める
+func (r *providerResolver) referencedPackageNames(root *StructNode) map[string]bool {
+	names := make(map[string]bool)
+	addType := func(ref TypeRef) {
+		if ref.PackagePath != "" {
+			names[packageName(ref.PackagePath, ref.PackageName)] = true
+		}
+	}
+
+	wireName := path.Base(r.wirePkgPath)
+	if name, _, ok := r.wa.LookupPackage(r.wirePkgPath); ok {
+		wireName = name
+	}
+	names[wireName] = true
+	addType(typeRefFromNode(root))
+
+	for _, provider := range r.graph.Providers {
+		names[packageName(provider.Function.PackagePath, provider.Function.PackageName)] = true
+		addType(provider.Provides)
+		for _, arg := range provider.Args {
+			addType(arg)
+		}
+	}
+	for _, binding := range r.graph.Bindings {
+		addType(binding.Interface)
+		addType(binding.Impl)
+	}
+	for _, input := range r.graph.Inputs {
+		addType(input.Type)
+	}
+	for _, field := range r.graph.StructFields {
+		addType(field.Type)
+	}
+	for _, fieldsOf := range r.graph.FieldsOf {
+		addType(fieldsOf.Struct)
+	}
+	for _, value := range r.graph.Values {
+		addType(value.Type)
+		if value.ExprPackage != "" {
+			names[packageName(value.ExprPackage, value.ExprPackageName)] = true
+		}
+	}
+	return names
+}
+
+// fieldCandidate はwire.FieldsOfで値を供給できる構造体のフィールド
+type fieldCandidate struct {
+	provider *ResolvedProvider // 構造体を返す提供関数
+	field    StructField       // フィールド
+}
+
+// resolveFieldsOf は引数として残った基本型の値のうち、グラフ内の構造体の同名フィールドで供給できるものをwire.FieldsOfに置き換える
+// 例: NewDB(dsn string) と NewConfig() *Config があり、ConfigにDSNフィールドがある場合
+func (r *providerResolver) resolveFieldsOf() {
+	if len(r.graph.Inputs) == 0 {
+		return
+	}
+
+	// 型のキー -> 同じ型のエクスポートされたフィールド
+	candidates := make(map[string][]fieldCandidate)
+	for _, provider := range r.graph.Providers {
+		if provider.Function.Result.Kind != packages.FieldKindStruct {
+			continue
+		}
+		// ツリーのノードは他の注入関数と共有するため、組み立てはtreeMuで直列にする
+		r.wa.treeMu.Lock()
+		node, err := r.wa.analyzeStruct(r.ctx, provider.Provides.PackagePath, provider.Provides.TypeName)
+		r.wa.treeMu.Unlock()
+		if err != nil {
+			continue
+		}
+		for _, field := range node.Fields {
+			if !token.IsExported(field.GetFieldName()) {
+				continue
+			}
+			fieldRef := typeRefFromNode(field)
+			candidates[fieldRef.Key()] = append(candidates[fieldRef.Key()], fieldCandidate{
+				provider: provider,
+				field:    StructField{Name: field.GetFieldName(), Type: fieldRef},
+			})
+		}
+	}
+
+	var inputs []InjectorInput
+	for _, input := range r.graph.Inputs {
+		candidate, ok := matchField(input, candidates[input.Type.Key()])
+		if !ok || r.dependsOn(candidate.provider, input.Type.Key(), make(map[string]bool)) {
+			inputs = append(inputs, input)
+			continue
+		}
+		r.addFieldsOf(candidate.provider.Provides, candidate.field)
+	}
+	if len(inputs) == len(r.graph.Inputs) {
+		return
+	}
+	r.graph.Inputs = inputs
+
+	// フィールドを使う提供関数が構造体の提供関数より後になるように並べ直す
+	r.sortProviders()
+}
+
+// matchField は引数と型・名前（大文字小文字を区別しない）が一致するフィールドを1つだけ選ぶ
+// 基本型の値は型だけでは区別できないため、名前も一致する場合に限る
+func matchField(input InjectorInput, candidates []fieldCandidate) (fieldCandidate, bool) {
+	if input.Type.Origin != packages.OriginBasic {
+		return fieldCandidate{}, false
+	}
+
+	var matched []fieldCandidate
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate.field.Name, input.Name) {
+			matched = append(matched, candidate)
+		}
+	}
+	if len(matched) != 1 {
+		return fieldCandidate{}, false
+	}
+	return matched[0], true
+}
+
+// dependsOn は提供関数が（間接的に）指定された型の値を必要とするかを判定する（循環の検出）
+func (r *providerResolver) dependsOn(provider *ResolvedProvider, key string, seen map[string]bool) bool {
+	for _, arg := range provider.Args {
+		argKey := r.implKey(arg.Key())
+		if argKey == key {
+			return true
+		}
+		if seen[argKey] {
+			continue
+		}
+		seen[argKey] = true
+		if dep := r.providerOf(argKey); dep != nil && r.dependsOn(dep, key, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// addFieldsOf はwire.FieldsOfで供給するフィールドを記録する
+func (r *providerResolver) addFieldsOf(structRef TypeRef, field StructField) {
+	for i := range r.graph.FieldsOf {
+		if r.graph.FieldsOf[i].Struct.Key() == structRef.Key() {
+			r.graph.FieldsOf[i].Fields = append(r.graph.FieldsOf[i].Fields, field)
+			return
+		}
+	}
+	r.graph.FieldsOf = append(r.graph.FieldsOf, FieldsOf{Struct: structRef, Fields: []StructField{field}})
+}
+
+// sortProviders は提供関数を依存される側から順に並べ直す（元の順序をできるだけ保つ）
+func (r *providerResolver) sortProviders() {
+	// フィールドの型のキー -> 構造体のキー
+	fieldOwners := make(map[string]string)
+	for _, fieldsOf := range r.graph.FieldsOf {
+		for _, field := range fieldsOf.Fields {
+			fieldOwners[field.Type.Key()] = fieldsOf.Struct.Key()
+		}
+	}
+
+	var sorted []*ResolvedProvider
+	visited := make(map[*ResolvedProvider]bool)
+	var visit func(provider *ResolvedProvider)
+	visit = func(provider *ResolvedProvider) {
+		if visited[provider] {
+			return
+		}
+		visited[provider] = true
+		for _, arg := range provider.Args {
+			key := r.implKey(arg.Key())
+			if owner, ok := fieldOwners[key]; ok {
+				key = owner
+			}
+			if dep := r.providerOf(key); dep != nil {
+				visit(dep)
+			}
+		}
+		sorted = append(sorted, provider)
+	}
+	for _, provider := range r.graph.Providers {
+		visit(provider)
+	}
+	r.graph.Providers = sorted
+}
+
+// implKey はインターフェースの型のキーを、wire.Bindで結びつけた実装型のキーに置き換える
+func (r *providerResolver) implKey(key string) string {
+	for _, binding := range r.graph.Bindings {
+		if binding.Interface.Key() == key {
+			return binding.Impl.Key()
+		}
+	}
+	return key
+}
+
+// providerOf は型を返すグラフ内の提供関数を返す
+func (r *providerResolver) providerOf(key string) *ResolvedProvider {
+	for _, provider := range r.graph.Providers {
+		if provider.Provides.Key() == key {
+			return provider
+		}
+	}
+	return nil
+}
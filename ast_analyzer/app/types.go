@@ -1,17 +1,33 @@
 package app
 
+import (
+	"go/token"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+)
+
 // NodeType はノードの種類を表す
 type NodeType int
 
 const (
 	NodeTypeStruct NodeType = iota
 	NodeTypeInterface
+	NodeTypeCollection
+	NodeTypeFunc
+	NodeTypeChan
+	NodeTypeInput
 )
 
 // InitFunctionInfo は初期化関数の情報を保持する
 type InitFunctionInfo struct {
-	Name        string // 関数名
-	PackagePath string // パッケージパス
+	Name         string               // 関数名
+	PackagePath  string               // パッケージパス
+	PackageName  string               // パッケージ名
+	Params       []packages.FieldInfo // 引数の型情報
+	Result       packages.FieldInfo   // 提供する型
+	ReturnsError bool                 // errorを返すかどうか
+	HasCleanup   bool                 // クリーンアップ関数を返すかどうか
+	Position     token.Position       // 関数の宣言の位置
 }
 
 // FieldNode はフィールドを表すインターフェース
@@ -21,14 +37,21 @@ type FieldNode interface {
 }
 
 // StructNode は構造体ノードを表す（構造体の定義とフィールドを保持）
+// フィールドとして参照するノードはフィールドごとに作り、同じ構造体のノードとFieldsを共有する
 type StructNode struct {
-	FieldName     string             // フィールド名（ルートの場合は空）
+	FieldName     string             // フィールド名（ルートやインターフェースの実装型の場合は空）
 	StructName    string             // 構造体名
 	PackagePath   string             // パッケージパス
+	PackageName   string             // パッケージ名（スキップされた場合は空の場合がある）
 	InitFunctions []InitFunctionInfo // 構造体を返す初期化関数
 	Fields        []FieldNode        // フィールドのノード
 	Skipped       bool               // 解析がスキップされたかどうか
 	SkipReason    string             // スキップされた理由
+	Cancelled     bool               // 解析が中断されたため、初期化関数やフィールドが欠けている可能性があるかどうか
+	Diagnostic    *Diagnostic        // スキップされた理由の診断（スキップされていない場合はnil）
+	Position      token.Position     // 構造体の型の宣言の位置（スキップされた場合はゼロ値）
+	FieldPosition token.Position     // フィールドの宣言の位置（ルートやインターフェースの実装型の場合はゼロ値）
+	IsValue       bool               // フィールドの型がポインタではなく構造体の値かどうか（ルートやインターフェースの実装型の場合はfalse）
 }
 
 func (s *StructNode) GetFieldName() string {
@@ -41,12 +64,18 @@ func (s *StructNode) NodeType() NodeType {
 
 // InterfaceNode はインターフェースフィールドを表す
 type InterfaceNode struct {
-	FieldName      string      // フィールド名
-	TypeName       string      // インターフェース型名
-	PackagePath    string      // パッケージパス
-	ResolvedStruct *StructNode // 解決された構造体
-	Skipped        bool        // 解決がスキップされたか
-	SkipReason     string      // スキップされた理由
+	FieldName      string         // フィールド名
+	TypeName       string         // インターフェース型名
+	PackagePath    string         // パッケージパス
+	PackageName    string         // パッケージ名
+	ResolvedStruct *StructNode    // 解決された構造体
+	Value          string         // 設定でwire.InterfaceValueとして与えられた値の式（設定されていない場合は空）
+	Skipped        bool           // 解決がスキップされたか
+	SkipReason     string         // スキップされた理由
+	Cancelled      bool           // 解析が中断されたため実装型を解決していないかどうか
+	Diagnostic     *Diagnostic    // 解決がスキップされた理由の診断（スキップされていない場合はnil）
+	Position       token.Position // フィールドの宣言の位置
+	Implementation *Candidate     // 解決に使った実装型と、それを返す関数・return文の位置（解決されていない場合はnil）
 }
 
 func (i *InterfaceNode) GetFieldName() string {
@@ -56,3 +85,83 @@ func (i *InterfaceNode) GetFieldName() string {
 func (i *InterfaceNode) NodeType() NodeType {
 	return NodeTypeInterface
 }
+
+// CollectionNode はスライス・配列・マップ型のフィールドを表す
+type CollectionNode struct {
+	FieldName   string         // フィールド名
+	TypeString  string         // 型の文字列表現（例: "[]plugin.Plugin", "map[string]handler.Handler"）
+	TypeName    string         // Named型の場合の型名（例: "Plugins"）
+	PackagePath string         // Named型の場合のパッケージパス
+	ElemType    string         // 要素型の文字列表現
+	KeyType     string         // マップのキー型の文字列表現（マップ以外は空）
+	ProvideHint string         // どのように提供する必要があるかの説明
+	Position    token.Position // フィールドの宣言の位置
+}
+
+func (c *CollectionNode) GetFieldName() string {
+	return c.FieldName
+}
+
+func (c *CollectionNode) NodeType() NodeType {
+	return NodeTypeCollection
+}
+
+// FuncNode は関数型のフィールドを表す
+type FuncNode struct {
+	FieldName   string         // フィールド名
+	TypeString  string         // 型の文字列表現（例: "func() time.Time"）
+	TypeName    string         // Named型の場合の型名
+	PackagePath string         // Named型の場合のパッケージパス
+	ProvideHint string         // どのように提供する必要があるかの説明
+	Position    token.Position // フィールドの宣言の位置
+}
+
+func (f *FuncNode) GetFieldName() string {
+	return f.FieldName
+}
+
+func (f *FuncNode) NodeType() NodeType {
+	return NodeTypeFunc
+}
+
+// ChanNode はチャネル型のフィールドを表す
+type ChanNode struct {
+	FieldName   string         // フィールド名
+	TypeString  string         // 型の文字列表現（例: "chan event.Event"）
+	TypeName    string         // Named型の場合の型名
+	PackagePath string         // Named型の場合のパッケージパス
+	ElemType    string         // 要素型の文字列表現
+	ProvideHint string         // どのように提供する必要があるかの説明
+	Position    token.Position // フィールドの宣言の位置
+}
+
+func (c *ChanNode) GetFieldName() string {
+	return c.FieldName
+}
+
+func (c *ChanNode) NodeType() NodeType {
+	return NodeTypeChan
+}
+
+// InputNode は解析の範囲外にあり、外部から供給する必要がある依存を表す
+// 基本型、標準ライブラリやサードパーティの型、範囲内でも構造体でもインターフェースでもないNamed型が該当する
+type InputNode struct {
+	FieldName     string              // フィールド名
+	TypeName      string              // 型名（例: "Duration", "string"）
+	PackagePath   string              // パッケージパス（基本型の場合は空）
+	PackageName   string              // パッケージ名（基本型の場合は空）
+	TypeString    string              // 型の文字列表現（例: "*sql.DB", "time.Duration"）
+	IsPointer     bool                // ポインタ型かどうか
+	Origin        packages.TypeOrigin // 型が定義されている場所の分類
+	InitFunctions []InitFunctionInfo  // 検索範囲内でこの型を返す関数
+	Value         string              // 設定でwire.Valueとして与えられた値の式（設定されていない場合は空）
+	Position      token.Position      // フィールドの宣言の位置
+}
+
+func (i *InputNode) GetFieldName() string {
+	return i.FieldName
+}
+
+func (i *InputNode) NodeType() NodeType {
+	return NodeTypeInput
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+	gopkgs "golang.org/x/tools/go/packages"
+)
+
+// Value はwire.Valueまたはwire.InterfaceValueで供給する値の設定
+// 提供関数がない型（例: *http.Client, io.Writer）にリテラルや変数を割り当て、注入関数の引数にしない
+type Value struct {
+	Type            TypeRef // 供給する型
+	ExprPackage     string  // 値が参照するパッケージのimportパス（リテラルの場合は空）
+	ExprPackageName string  // 値が参照するパッケージのパッケージ名（解析時に読み込んだパッケージから設定する）
+	Expr            string  // パッケージ内の識別子（例: "DefaultClient"）、またはリテラル（例: `"localhost"`）
+}
+
+// ParseValue は "型=値" の形式の設定を解析する
+// 型と値のパッケージはimportパスで修飾する（例: "*net/http.Client=net/http.DefaultClient", "io.Writer=os.Stdout", `string="localhost"`）
+func ParseValue(spec string) (Value, error) {
+	typeSpec, exprSpec, ok := strings.Cut(spec, "=")
+	typeSpec, exprSpec = strings.TrimSpace(typeSpec), strings.TrimSpace(exprSpec)
+	if !ok || typeSpec == "" || exprSpec == "" {
+		return Value{}, fmt.Errorf("invalid value %q: expected TYPE=EXPR", spec)
+	}
+
+	value := Value{Type: parseTypeSpec(typeSpec)}
+
+	// リテラルはそのまま使う
+	if expr, err := parser.ParseExpr(exprSpec); err == nil {
+		if lit, ok := expr.(*ast.BasicLit); ok && lit.Kind != token.ILLEGAL {
+			value.Expr = exprSpec
+			return value, nil
+		}
+	}
+
+	dot := strings.LastIndex(exprSpec, ".")
+	if dot <= 0 || !token.IsIdentifier(exprSpec[dot+1:]) {
+		return Value{}, fmt.Errorf("invalid value %q: expected a literal or an import path qualified identifier", exprSpec)
+	}
+	value.ExprPackage = exprSpec[:dot]
+	value.Expr = exprSpec[dot+1:]
+	return value, nil
+}
+
+// parseTypeSpec は "*net/http.Client" のような型の指定からTypeRefを作る
+func parseTypeSpec(spec string) TypeRef {
+	pointer := strings.HasPrefix(spec, "*")
+	name := strings.TrimPrefix(spec, "*")
+
+	dot := strings.LastIndex(name, ".")
+	if dot <= 0 {
+		// 基本型など名前のない型
+		return TypeRef{TypeName: name, TypeString: spec, IsPointer: pointer}
+	}
+
+	pkgPath, typeName := name[:dot], name[dot+1:]
+	return TypeRef{TypeName: typeName, PackagePath: pkgPath, TypeString: qualifiedTypeString(pkgPath, "", typeName, pointer), IsPointer: pointer}
+}
+
+// qualifiedTypeString はパッケージ名で修飾した型の文字列表現を返す
+func qualifiedTypeString(pkgPath, pkgName, typeName string, pointer bool) string {
+	typeString := packageName(pkgPath, pkgName) + "." + typeName
+	if pointer {
+		typeString = "*" + typeString
+	}
+	return typeString
+}
+
+// Qualified はpkgPathのパッケージから参照する場合の値の式を返す
+func (v Value) Qualified(pkgPath string) string {
+	if v.ExprPackage == "" || v.ExprPackage == pkgPath {
+		return v.Expr
+	}
+	return packageName(v.ExprPackage, v.ExprPackageName) + "." + v.Expr
+}
+
+// WithValues はwire.Valueまたはwire.InterfaceValueで供給する値を設定する
+func WithValues(values ...Value) Option {
+	return func(wa *WireAnalyzer) {
+		for _, value := range values {
+			wa.values[value.Type.Key()] = value
+		}
+	}
+}
+
+// lookupValue は型に設定された値を探す
+func (wa *WireAnalyzer) lookupValue(ref TypeRef) (Value, bool) {
+	wa.valuesOnce.Do(wa.resolveValuePackages)
+	value, ok := wa.values[ref.Key()]
+	return value, ok
+}
+
+// resolveValuePackages は設定された値の型と式が参照するパッケージを読み込み、パッケージ名を設定する
+// 読み込めなかったパッケージはimportパスの末尾をパッケージ名として扱う
+func (wa *WireAnalyzer) resolveValuePackages() {
+	var patterns []string
+	seen := make(map[string]bool)
+	for _, value := range wa.values {
+		for _, pkgPath := range []string{value.Type.PackagePath, value.ExprPackage} {
+			if pkgPath != "" && !seen[pkgPath] {
+				seen[pkgPath] = true
+				patterns = append(patterns, pkgPath)
+			}
+		}
+	}
+	if len(patterns) == 0 {
+		return
+	}
+
+	cfg := &gopkgs.Config{Mode: gopkgs.NeedName, Dir: wa.workDir, BuildFlags: packages.BuildFlags()}
+	pkgs, err := gopkgs.Load(cfg, patterns...)
+	if err != nil {
+		return
+	}
+	names := make(map[string]string, len(pkgs))
+	for _, pkg := range pkgs {
+		if pkg.Name != "" {
+			names[pkg.PkgPath] = pkg.Name
+		}
+	}
+
+	for key, value := range wa.values {
+		if name, ok := names[value.Type.PackagePath]; ok {
+			value.Type.PackageName = name
+			value.Type.TypeString = qualifiedTypeString(value.Type.PackagePath, name, value.Type.TypeName, value.Type.IsPointer)
+		}
+		value.ExprPackageName = names[value.ExprPackage]
+		wa.values[key] = value
+	}
+}
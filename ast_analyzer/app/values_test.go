@@ -0,0 +1,102 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		wantKey     string
+		wantPointer bool
+		wantPackage string
+		wantExpr    string
+		wantErr     bool
+	}{
+		{"ポインタ型とパッケージの変数", "*net/http.Client=net/http.DefaultClient", "net/http.Client", true, "net/http", "DefaultClient", false},
+		{"インターフェース型", "io.Writer=os.Stdout", "io.Writer", false, "os", "Stdout", false},
+		{"基本型とリテラル", `string="localhost"`, "string", false, "", `"localhost"`, false},
+		{"数値リテラル", "int=10", "int", false, "", "10", false},
+		{"値がない", "io.Writer=", "", false, "", "", true},
+		{"区切りがない", "io.Writer", "", false, "", "", true},
+		{"修飾されていない識別子", "io.Writer=stdout", "", false, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseValue(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseValue(%q) succeeded, want error", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseValue(%q) failed: %v", tt.spec, err)
+			}
+			if got.Type.Key() != tt.wantKey || got.Type.IsPointer != tt.wantPointer {
+				t.Errorf("Type = %+v, want key %s pointer %v", got.Type, tt.wantKey, tt.wantPointer)
+			}
+			if got.ExprPackage != tt.wantPackage || got.Expr != tt.wantExpr {
+				t.Errorf("Expr = %s.%s, want %s.%s", got.ExprPackage, got.Expr, tt.wantPackage, tt.wantExpr)
+			}
+		})
+	}
+}
+
+func TestWireAnalyzer_AnalyzeInjectors_Values(t *testing.T) {
+	var values []Value
+	for _, spec := range []string{"*net/http.Client=net/http.DefaultClient", "io.Writer=os.Stdout"} {
+		value, err := ParseValue(spec)
+		if err != nil {
+			t.Fatalf("ParseValue failed: %v", err)
+		}
+		values = append(values, value)
+	}
+
+	tests := []struct {
+		name       string
+		values     []Value
+		wantInputs []string
+		wantValues []string
+	}{
+		{
+			name:       "値を設定しない場合は引数になる",
+			wantInputs: []string{"w io.Writer", "c *http.Client"},
+		},
+		{
+			name:       "値を設定した型は引数にならない",
+			values:     values,
+			wantValues: []string{"io.Writer=os.Stdout", "*http.Client=net/http.DefaultClient"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewWireAnalyzer("../../testdata/values", "./...", WithValues(tt.values...))
+			injectors, err := analyzer.AnalyzeInjectors("../../testdata/values/wire.go")
+			if err != nil {
+				t.Fatalf("AnalyzeInjectors failed: %v", err)
+			}
+			graph := injectors[0].Graph
+
+			var inputs []string
+			for _, input := range graph.Inputs {
+				inputs = append(inputs, input.Name+" "+input.Type.TypeString)
+			}
+			if strings.Join(inputs, ",") != strings.Join(tt.wantInputs, ",") {
+				t.Errorf("Inputs = %v, want %v", inputs, tt.wantInputs)
+			}
+
+			var got []string
+			for _, value := range graph.Values {
+				got = append(got, value.Type.TypeString+"="+value.ExprPackage+"."+value.Expr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantValues, ",") {
+				t.Errorf("Values = %v, want %v", got, tt.wantValues)
+			}
+		})
+	}
+}
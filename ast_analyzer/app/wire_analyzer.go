@@ -1,75 +1,294 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+	"sync"
 
+	"github.com/rmocchy/convinient_wire/ast_analyzer/cache"
 	file "github.com/rmocchy/convinient_wire/ast_analyzer/files"
 	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
 	gopkgs "golang.org/x/tools/go/packages"
 )
 
 // WireAnalyzer はwire.goの解析を行う
+// 型ごとの情報は並行して調べ、ツリーは注入関数とフィールドの順に組み立てるため、結果の順序は実行ごとに変わらない
+// 解析のメソッドは複数のgoroutineから同時に呼び出せる
 type WireAnalyzer struct {
 	workDir       string
 	searchPattern string
-	analyzed      map[string]*StructNode // 解析済みの構造体をキャッシュ（無限ループ防止）
+	boundary      Boundary         // 再帰的に解析する範囲
+	values        map[string]Value // wire.Valueで供給する値（型のキー -> 値）
+	valuesOnce    sync.Once        // 値が参照するパッケージの名前を一度だけ読み込む
+	cache         *cache.Cache     // 抽出済みの情報のキャッシュ（nilの場合は使わない）
+	workers       int              // 型の情報を並行して調べるワーカーの数
+	sem           chan struct{}    // 同時に実行する処理の数を制限する
+
+	treeMu    sync.Mutex               // ツリーの組み立てを直列にする
+	analyzed  map[string]*StructNode   // 解析済みの構造体をキャッシュ（無限ループ防止）
+	analyzing map[string][]*StructNode // 解析中の構造体 -> 解析中に作ったフィールドごとのノード
+
+	structFields  memo[*packages.StructFieldsInfo]    // 構造体のキー -> フィールド
+	initFuncs     memo[[]InitFunctionInfo]            // 構造体のキー -> 構造体を返す初期化関数
+	typeProviders memo[[]InitFunctionInfo]            // 型のキー -> 型を返す関数
+	interfaceRefs memo[[]packages.InterfaceReference] // インターフェースのキー -> 実装型
+	injectFiles   memo[map[string]bool]               // ファイル名 -> wireinjectタグのファイルで宣言された注入関数の名前
+
+	searchMu    sync.Mutex
+	searchPkgs  []*gopkgs.Package         // 読み込み済みの検索対象パッケージ
+	ifaceSearch *packages.InterfaceSearch // 検索対象パッケージから構築したSSA（インターフェースの検索で使い回す）
+
+	factsMu  sync.Mutex
+	facts    *factIndex // 検索対象のパッケージから抽出した情報
+	factsErr error      // 抽出済みの情報を読み込めなかった理由
 }
 
 // NewWireAnalyzer は新しいWireAnalyzerを作成する
-func NewWireAnalyzer(workDir, searchPattern string) *WireAnalyzer {
-	return &WireAnalyzer{
+func NewWireAnalyzer(workDir, searchPattern string, opts ...Option) *WireAnalyzer {
+	wa := &WireAnalyzer{
 		workDir:       workDir,
 		searchPattern: searchPattern,
+		boundary:      DefaultBoundary(),
 		analyzed:      make(map[string]*StructNode),
+		analyzing:     make(map[string][]*StructNode),
+		values:        make(map[string]Value),
+		workers:       defaultWorkers(),
 	}
+	for _, opt := range opts {
+		opt(wa)
+	}
+	wa.sem = make(chan struct{}, wa.workers)
+	return wa
 }
 
 // AnalyzeWireFile はwire.goファイルを解析する
 func (wa *WireAnalyzer) AnalyzeWireFile(wireFilePath string) ([]*StructNode, error) {
+	return wa.AnalyzeWireFileContext(context.Background(), wireFilePath)
+}
+
+// AnalyzeWireFileContext はAnalyzeWireFileと同様にwire.goファイルを解析する
+// ctxが取り消されたりタイムアウトした場合は、それまでに解析した部分的な結果とErrCancelledを返す
+// 解析できなかったノードはCancelledがtrueになる
+func (wa *WireAnalyzer) AnalyzeWireFileContext(ctx context.Context, wireFilePath string) ([]*StructNode, error) {
 	// wire.goから構造体を取得
 	functions, err := file.ParseWireFileStructs(wireFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse wire file: %w", err)
 	}
 
+	// wire.goと同じパッケージに定義された構造体のためにパッケージパスを解決
+	wirePkgPath, err := resolveWirePackagePath(ctx, wireFilePath)
+	if err != nil {
+		return nil, wrapCancelled(ctx, fmt.Errorf("failed to resolve wire package: %w", err))
+	}
+
+	// 各関数の返り値構造体から辿れる型を並行して調べてから、決まった順序でツリーを組み立てる
+	wa.prefetchRoots(ctx, functions, wirePkgPath, nil)
+	wa.treeMu.Lock()
+	defer wa.treeMu.Unlock()
+
 	var results []*StructNode
 
 	// 各関数の返り値構造体を解析
 	for _, funcInfo := range functions {
 		for _, structInfo := range funcInfo.ReturnTypes {
 			// 構造体を再帰的に解析
-			structNode, err := wa.analyzeStruct("", structInfo.Name)
-			if err != nil {
-				// エラーがあっても他の構造体の解析を続ける
-				results = append(results, &StructNode{
-					StructName: structInfo.Name,
-					Skipped:    true,
-					SkipReason: fmt.Sprintf("failed to analyze: %v", err),
-				})
-				continue
-			}
-			results = append(results, structNode)
+			results = append(results, wa.analyzeRoot(ctx, funcInfo, structInfo, wirePkgPath, functions))
 		}
 	}
 
+	if err := wa.checkCancelled(ctx); err != nil {
+		return results, err
+	}
 	return results, nil
 }
 
-// analyzeStruct は構造体を再帰的に解析する
-func (wa *WireAnalyzer) analyzeStruct(packagePath, structName string) (*StructNode, error) {
-	// キャッシュキーを生成
-	cacheKey := packagePath + "." + structName
+// analyzeRoot は注入関数の返り値の構造体を解析する
+// 注入関数自身は構造体を返す関数として扱わない
+func (wa *WireAnalyzer) analyzeRoot(ctx context.Context, funcInfo file.FunctionInfo, structInfo file.StructInfo, wirePkgPath string, injectors []file.FunctionInfo) *StructNode {
+	packagePath := structInfo.PackagePath
 	if packagePath == "" {
-		cacheKey = structName
+		packagePath = wirePkgPath
+	}
+
+	structNode, err := wa.analyzeStruct(ctx, packagePath, structInfo.Name)
+	if err != nil && ctx.Err() != nil {
+		return newCancelledStruct("", structInfo.Name, packagePath, funcInfo.Position)
+	}
+	if err != nil {
+		// エラーがあっても他の構造体の解析を続ける
+		root := TypeRef{TypeName: structInfo.Name, PackagePath: packagePath, IsPointer: structInfo.IsPointer}
+		return newSkippedStruct("", structInfo.Name, packagePath,
+			newDiagnostic(CodeLoadError, funcInfo.Position, fmt.Sprintf("failed to analyze: %v", err), root))
+	}
+
+	initFuncs := make([]InitFunctionInfo, 0, len(structNode.InitFunctions))
+	for _, initFunc := range structNode.InitFunctions {
+		if !isInjector(initFunc, wirePkgPath, injectors) {
+			initFuncs = append(initFuncs, initFunc)
+		}
+	}
+	structNode.InitFunctions = initFuncs
+
+	return structNode
+}
+
+// isInjector は関数がwire.goの注入関数かどうかを判定する
+func isInjector(fn InitFunctionInfo, wirePkgPath string, injectors []file.FunctionInfo) bool {
+	if fn.PackagePath != wirePkgPath {
+		return false
+	}
+	for _, injector := range injectors {
+		if injector.Name == fn.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// isForeignInjector は関数がwireinjectタグを付けたときだけビルドされるファイルで、wire.Buildを呼び出す注入関数かどうかを判定する
+// 他のパッケージのwire.goの注入関数は生成されるwire_gen.goの関数と別物なので、提供関数として扱わない
+func (wa *WireAnalyzer) isForeignInjector(ctx context.Context, fn InitFunctionInfo) bool {
+	if fn.Position.Filename == "" {
+		return false
 	}
+	injectors, _ := wa.injectFiles.do(ctx, fn.Position.Filename, func() (map[string]bool, error) {
+		return wireInjectors(fn.Position.Filename)
+	})
+	return injectors[fn.Name]
+}
+
+// wireInjectors はwireinjectタグを付けたときだけビルドされるファイルから、wire.Buildを呼び出す関数の名前を集める
+// タグのないファイルの場合は空のマップを返す
+func wireInjectors(filename string) (map[string]bool, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	injectors := make(map[string]bool)
+	if !wireInjectOnly(f) {
+		return injectors, nil
+	}
+
+	wireNames := make(map[string]bool)
+	for _, imp := range f.Imports {
+		if imp.Path.Value != `"github.com/google/wire"` {
+			continue
+		}
+		name := "wire"
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		wireNames[name] = true
+	}
+
+	for _, decl := range f.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Recv != nil || funcDecl.Body == nil {
+			continue
+		}
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Build" {
+				if ident, ok := sel.X.(*ast.Ident); ok && wireNames[ident.Name] {
+					injectors[funcDecl.Name.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	return injectors, nil
+}
+
+// wireInjectOnly はファイルのビルド制約がwireinjectタグを付けたときだけ満たされるかを判定する
+func wireInjectOnly(f *ast.File) bool {
+	for _, group := range f.Comments {
+		if group.Pos() > f.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if !constraint.IsGoBuild(comment.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(comment.Text)
+			if err != nil {
+				return false
+			}
+			withTag := expr.Eval(func(tag string) bool { return tag == "wireinject" })
+			withoutTag := expr.Eval(func(string) bool { return false })
+			return withTag && !withoutTag
+		}
+	}
+	return false
+}
+
+// resolveWirePackagePath はwire.goが属するパッケージのパスを解決する
+func resolveWirePackagePath(ctx context.Context, wireFilePath string) (string, error) {
+	_, pkgPath, err := resolvePackage(ctx, filepath.Dir(wireFilePath))
+	if err != nil {
+		return "", err
+	}
+	if pkgPath == "" {
+		return "", fmt.Errorf("no package found for %s", wireFilePath)
+	}
+	return pkgPath, nil
+}
+
+// ResolvePackage はディレクトリのパッケージ名とパッケージパスを解決する
+// Goファイルがまだないディレクトリの場合、パッケージ名は空になる
+func ResolvePackage(dir string) (name, pkgPath string, err error) {
+	return resolvePackage(context.Background(), dir)
+}
+
+// resolvePackage はctxが取り消されると読み込みを中断するResolvePackage
+func resolvePackage(ctx context.Context, dir string) (name, pkgPath string, err error) {
+	cfg := &gopkgs.Config{
+		Mode:       gopkgs.NeedName,
+		Dir:        dir,
+		BuildFlags: packages.BuildFlags(),
+		Context:    ctx,
+	}
+
+	pkgs, err := gopkgs.Load(cfg, ".")
+	if err != nil {
+		return "", "", err
+	}
+	if err := ctx.Err(); err != nil {
+		return "", "", err
+	}
+	if len(pkgs) == 0 {
+		return "", "", fmt.Errorf("no package found in %s", dir)
+	}
+
+	return pkgs[0].Name, pkgs[0].PkgPath, nil
+}
+
+// analyzeStruct は構造体を再帰的に解析する（treeMuを確保して呼ぶ）
+func (wa *WireAnalyzer) analyzeStruct(ctx context.Context, packagePath, structName string) (*StructNode, error) {
+	// キャッシュキーを生成
+	cacheKey := structKey(packagePath, structName)
 
 	// 既に解析済みの場合はキャッシュから返す
 	if cached, ok := wa.analyzed[cacheKey]; ok {
 		return cached, nil
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// 構造体のフィールド情報を取得
-	fieldsInfo, err := packages.ExtractStructFields(wa.workDir, packagePath, structName)
+	fieldsInfo, err := wa.extractStructFields(ctx, packagePath, structName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract struct fields for %s: %w", structName, err)
 	}
@@ -77,145 +296,471 @@ func (wa *WireAnalyzer) analyzeStruct(packagePath, structName string) (*StructNo
 	result := &StructNode{
 		StructName:    structName,
 		PackagePath:   packagePath,
+		PackageName:   fieldsInfo.PackageName,
 		InitFunctions: make([]InitFunctionInfo, 0),
 		Fields:        make([]FieldNode, 0, len(fieldsInfo.Fields)),
+		Position:      fieldsInfo.Position,
 	}
 
 	// キャッシュに登録（無限ループ防止のため、フィールド解析前に登録）
 	wa.analyzed[cacheKey] = result
+	wa.analyzing[cacheKey] = nil
 
 	// 初期化関数を探す
-	initFuncs, err := wa.findInitFunctions(packagePath, structName)
+	initFuncs, err := wa.findInitFunctions(ctx, packagePath, structName)
 	if err == nil {
 		result.InitFunctions = initFuncs
 	}
 
 	// 各フィールドを解析
 	for _, field := range fieldsInfo.Fields {
-		fieldNode := wa.analyzeField(field)
+		fieldNode := wa.analyzeField(ctx, field)
 		if fieldNode != nil {
 			result.Fields = append(result.Fields, fieldNode)
 		}
 	}
 
+	// 途中で中断された場合は初期化関数やフィールドが欠けている可能性がある
+	if ctx.Err() != nil {
+		result.Cancelled = true
+	}
+
+	// 循環によって解析中に作ったフィールドごとのノードに、解析した内容を反映する
+	for _, node := range wa.analyzing[cacheKey] {
+		node.InitFunctions = result.InitFunctions
+		node.Fields = result.Fields
+		node.Cancelled = result.Cancelled
+	}
+	delete(wa.analyzing, cacheKey)
+
 	return result, nil
 }
 
+// fieldStruct は構造体をフィールドとして参照するノードを作る
+// 解析済みの構造体は複数のフィールドやルートで共有するため、フィールド名と位置はフィールドごとのコピーに持たせる
+func (wa *WireAnalyzer) fieldStruct(resolved *StructNode, field packages.FieldInfo) *StructNode {
+	node := *resolved
+	node.FieldName = field.Name
+	node.FieldPosition = field.Position
+	node.IsValue = !field.IsPointer
+
+	key := structKey(resolved.PackagePath, resolved.StructName)
+	if pending, ok := wa.analyzing[key]; ok {
+		wa.analyzing[key] = append(pending, &node)
+	}
+	return &node
+}
+
+// extractStructFields は構造体のフィールド情報を取得する（抽出済みの情報があればそれを使う）
+func (wa *WireAnalyzer) extractStructFields(ctx context.Context, packagePath, structName string) (*packages.StructFieldsInfo, error) {
+	return wa.structFields.do(ctx, structKey(packagePath, structName), func() (*packages.StructFieldsInfo, error) {
+		if facts := wa.packageFacts(ctx, packagePath); facts != nil {
+			return facts.StructFields(structName)
+		}
+		release, err := wa.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return packages.ExtractStructFieldsContext(ctx, wa.workDir, packagePath, structName)
+	})
+}
+
 // findInitFunctions は構造体を返す初期化関数を探す
-func (wa *WireAnalyzer) findInitFunctions(packagePath, structName string) ([]InitFunctionInfo, error) {
-	// パッケージを読み込む
+func (wa *WireAnalyzer) findInitFunctions(ctx context.Context, packagePath, structName string) ([]InitFunctionInfo, error) {
+	return wa.initFuncs.do(ctx, structKey(packagePath, structName), func() ([]InitFunctionInfo, error) {
+		if index := wa.loadFacts(ctx); index != nil {
+			return toInitFunctions(packages.FactsFunctionsReturningStruct(structName, packagePath, index.facts)), nil
+		}
+
+		pkgs, err := wa.loadSearchPackages(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// 構造体を返す関数を探す
+		functions := packages.FindFunctionsReturningStruct(structName, packagePath, pkgs)
+
+		return toInitFunctions(functions), nil
+	})
+}
+
+// findTypeProviders は構造体以外のNamed型を返す関数を探す
+func (wa *WireAnalyzer) findTypeProviders(ctx context.Context, packagePath, typeName string) ([]InitFunctionInfo, error) {
+	return wa.typeProviders.do(ctx, structKey(packagePath, typeName), func() ([]InitFunctionInfo, error) {
+		if index := wa.loadFacts(ctx); index != nil {
+			return toInitFunctions(packages.FactsFunctionsReturningType(typeName, packagePath, index.facts)), nil
+		}
+
+		pkgs, err := wa.loadSearchPackages(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		functions := packages.FindFunctionsReturningType(typeName, packagePath, pkgs)
+
+		return toInitFunctions(functions), nil
+	})
+}
+
+// loadSearchPackages は検索対象のパッケージを読み込む（一度読み込んだ結果を再利用する）
+// ctxが取り消されて読み込めなかった場合は、次に呼ばれたときに読み込み直す
+func (wa *WireAnalyzer) loadSearchPackages(ctx context.Context) ([]*gopkgs.Package, error) {
+	wa.searchMu.Lock()
+	defer wa.searchMu.Unlock()
+
+	if wa.searchPkgs != nil {
+		return wa.searchPkgs, nil
+	}
+
+	// wire.goの注入関数と構造体も見えるようにwireinjectタグを付けて読み込む
 	cfg := &gopkgs.Config{
-		Mode: gopkgs.NeedName | gopkgs.NeedFiles | gopkgs.NeedImports |
-			gopkgs.NeedDeps | gopkgs.NeedTypes | gopkgs.NeedSyntax | gopkgs.NeedTypesInfo,
-		Dir: wa.workDir,
+		Mode: gopkgs.NeedName | gopkgs.NeedFiles | gopkgs.NeedImports | gopkgs.NeedDeps |
+			gopkgs.NeedTypes | gopkgs.NeedSyntax | gopkgs.NeedTypesInfo | gopkgs.NeedModule,
+		Dir:        wa.workDir,
+		BuildFlags: packages.BuildFlags(),
+		Context:    ctx,
 	}
 
 	pkgs, err := gopkgs.Load(cfg, wa.searchPattern)
 	if err != nil {
 		return nil, err
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	wa.searchPkgs = pkgs
+	return pkgs, nil
+}
+
+// SearchPackages は構文木と型情報を含む検索対象のパッケージを返す
+func (wa *WireAnalyzer) SearchPackages() ([]*gopkgs.Package, error) {
+	return wa.loadSearchPackages(context.Background())
+}
 
-	// 構造体を返す関数を探す
-	functions := packages.FindFunctionsReturningStruct(structName, packagePath, pkgs)
+// LookupPackage は検索対象のパッケージからパッケージ名とディレクトリを探す
+func (wa *WireAnalyzer) LookupPackage(packagePath string) (name, dir string, ok bool) {
+	if index := wa.loadFacts(context.Background()); index != nil {
+		if facts, found := index.byPath[packagePath]; found {
+			return facts.Name, facts.Dir, true
+		}
+		return "", "", false
+	}
+
+	pkgs, err := wa.loadSearchPackages(context.Background())
+	if err != nil {
+		return "", "", false
+	}
 
-	// InitFunctionInfoに変換
+	for _, pkg := range pkgs {
+		if pkg.PkgPath == packagePath {
+			return pkg.Name, pkg.Dir, true
+		}
+	}
+	return "", "", false
+}
+
+// ResolveType は "handler.UserHandler" や "example.com/app/handler.UserHandler" の形式の型名を検索対象のパッケージから探す
+// インターフェース以外の型はIsPointerをtrueにする
+func (wa *WireAnalyzer) ResolveType(spec string) (TypeRef, error) {
+	dot := strings.LastIndex(spec, ".")
+	if dot <= 0 || dot == len(spec)-1 {
+		return TypeRef{}, fmt.Errorf("invalid type %q: expected package.Type", spec)
+	}
+	pkgSpec, typeName := spec[:dot], spec[dot+1:]
+
+	pkgs, err := wa.loadSearchPackages(context.Background())
+	if err != nil {
+		return TypeRef{}, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	var matches []TypeRef
+	for _, pkg := range pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+		if pkg.PkgPath != pkgSpec && pkg.Name != pkgSpec && !strings.HasSuffix(pkg.PkgPath, "/"+pkgSpec) {
+			continue
+		}
+		obj, ok := pkg.Types.Scope().Lookup(typeName).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		// インターフェース以外の型はポインタで受け渡す
+		matches = append(matches, TypeRef{
+			TypeName:    typeName,
+			PackagePath: pkg.PkgPath,
+			PackageName: pkg.Name,
+			TypeString:  pkg.Name + "." + typeName,
+			IsPointer:   !types.IsInterface(obj.Type()),
+			IsInterface: types.IsInterface(obj.Type()),
+			Origin:      packages.OriginModuleLocal,
+		})
+	}
+
+	switch len(matches) {
+	case 0:
+		return TypeRef{}, fmt.Errorf("type %s not found", spec)
+	case 1:
+		return matches[0], nil
+	}
+
+	candidates := make([]string, 0, len(matches))
+	for _, match := range matches {
+		candidates = append(candidates, match.Key())
+	}
+	return TypeRef{}, fmt.Errorf("type %s is ambiguous: %s", spec, strings.Join(candidates, ", "))
+}
+
+// toInitFunctions はFunctionInfoをInitFunctionInfoに変換する
+func toInitFunctions(functions []packages.FunctionInfo) []InitFunctionInfo {
 	initFuncs := make([]InitFunctionInfo, 0, len(functions))
 	for _, fn := range functions {
 		initFuncs = append(initFuncs, InitFunctionInfo{
-			Name:        fn.Name,
-			PackagePath: fn.PackagePath,
+			Name:         fn.Name,
+			PackagePath:  fn.PackagePath,
+			PackageName:  fn.PackageName,
+			Params:       fn.Params,
+			Result:       fn.Result,
+			ReturnsError: fn.ReturnsError,
+			HasCleanup:   fn.HasCleanup,
+			Position:     fn.Position,
 		})
 	}
 
-	return initFuncs, nil
+	return initFuncs
 }
 
 // analyzeField はフィールドを解析する
-func (wa *WireAnalyzer) analyzeField(field packages.FieldInfo) FieldNode {
+func (wa *WireAnalyzer) analyzeField(ctx context.Context, field packages.FieldInfo) FieldNode {
+	// スライス・マップ・関数・チャネル型の場合
+	switch field.Kind {
+	case packages.FieldKindSlice, packages.FieldKindArray, packages.FieldKindMap:
+		return newCollectionNode(field)
+	case packages.FieldKindFunc:
+		return newFuncNode(field)
+	case packages.FieldKindChan:
+		return newChanNode(field)
+	}
+
+	// 解析範囲外の型は外部から供給する入力として扱う
+	if !wa.boundary.Follows(field.Origin, field.PackagePath) {
+		return wa.newInputNode(ctx, field)
+	}
+
 	// インターフェース型の場合
 	if field.IsInterface {
-		resolvedStruct, skipReason := wa.resolveInterface(field)
-		return &InterfaceNode{
+		// 値が設定されている場合は実装型を探さない
+		if value, ok := wa.lookupValue(typeRefFromField(field)); ok {
+			return &InterfaceNode{
+				FieldName:   field.Name,
+				TypeName:    field.TypeName,
+				PackagePath: field.PackagePath,
+				PackageName: field.PackageName,
+				Value:       value.Qualified(field.PackagePath),
+				Position:    field.Position,
+			}
+		}
+
+		resolvedStruct, implementation, diagnostic := wa.resolveInterface(ctx, field)
+		cancelled := diagnostic != nil && ctx.Err() != nil
+		if cancelled {
+			diagnostic = newCancelledDiagnostic(field.Position, typeRefFromField(field))
+		}
+		node := &InterfaceNode{
 			FieldName:      field.Name,
 			TypeName:       field.TypeName,
 			PackagePath:    field.PackagePath,
+			PackageName:    field.PackageName,
 			ResolvedStruct: resolvedStruct,
-			Skipped:        skipReason != "",
-			SkipReason:     skipReason,
+			Cancelled:      cancelled,
+			Position:       field.Position,
+			Implementation: implementation,
+		}
+		if diagnostic != nil {
+			node.Skipped = true
+			node.SkipReason = diagnostic.Message
+			node.Diagnostic = diagnostic
 		}
+		return node
 	}
 
 	// 構造体型の場合
-	if field.TypeName != "" && field.PackagePath != "" && !isBuiltinType(field.TypeName) {
-		resolvedStruct, err := wa.analyzeStruct(field.PackagePath, field.TypeName)
+	if field.Kind == packages.FieldKindStruct {
+		resolvedStruct, err := wa.analyzeStruct(ctx, field.PackagePath, field.TypeName)
+		if err != nil && ctx.Err() != nil {
+			node := newCancelledStruct(field.Name, field.TypeName, field.PackagePath, field.Position)
+			node.FieldPosition = field.Position
+			node.IsValue = !field.IsPointer
+			return node
+		}
 		if err != nil {
-			// エラーの場合はnilを返す（スキップ）
-			return nil
+			// 読み込めなかった構造体は、依存グラフから黙って抜け落ちないよう理由とともにスキップする
+			node := newSkippedStruct(field.Name, field.TypeName, field.PackagePath,
+				newDiagnostic(CodeLoadError, field.Position, fmt.Sprintf("failed to analyze: %v", err), typeRefFromField(field)))
+			node.FieldPosition = field.Position
+			node.IsValue = !field.IsPointer
+			return node
 		}
-		// FieldNameをセットして返す
-		resolvedStruct.FieldName = field.Name
-		return resolvedStruct
+		return wa.fieldStruct(resolvedStruct, field)
 	}
 
-	// 基本型などはスキップ（nilを返す）
-	return nil
+	// 範囲内の基本型ベースのNamed型なども外部から供給する入力として扱う
+	return wa.newInputNode(ctx, field)
 }
 
-// resolveInterface はインターフェースから具体的な構造体を解決する
-func (wa *WireAnalyzer) resolveInterface(field packages.FieldInfo) (*StructNode, string) {
+// newInputNode は外部から供給する入力のノードを作成する
+func (wa *WireAnalyzer) newInputNode(ctx context.Context, field packages.FieldInfo) *InputNode {
+	node := &InputNode{
+		FieldName:     field.Name,
+		TypeName:      field.TypeName,
+		PackagePath:   field.PackagePath,
+		PackageName:   field.PackageName,
+		TypeString:    field.TypeString,
+		IsPointer:     field.IsPointer,
+		Origin:        field.Origin,
+		InitFunctions: make([]InitFunctionInfo, 0),
+		Position:      field.Position,
+	}
+	if value, ok := wa.lookupValue(typeRefFromField(field)); ok {
+		node.Value = value.Qualified(field.PackagePath)
+	}
+
+	// Named型の場合は検索範囲内にこの型を返す関数があるかを探す
+	if field.PackagePath != "" {
+		if initFuncs, err := wa.findTypeProviders(ctx, field.PackagePath, field.TypeName); err == nil {
+			node.InitFunctions = initFuncs
+		}
+	}
+
+	return node
+}
+
+// resolveInterface はインターフェースから具体的な構造体を解決し、解決に使った実装型の候補とともに返す
+// 解決できない場合は理由の診断を返す
+func (wa *WireAnalyzer) resolveInterface(ctx context.Context, field packages.FieldInfo) (*StructNode, *Candidate, *Diagnostic) {
+	iface := typeRefFromField(field)
+
 	// インターフェースを参照する関数を検索
-	refs, err := packages.FindInterfaceReferences(
-		wa.workDir,
-		field.TypeName,
-		field.PackagePath,
-		wa.searchPattern,
-	)
+	refs, err := wa.findInterfaceReferences(ctx, field.TypeName, field.PackagePath)
 	if err != nil {
-		return nil, fmt.Sprintf("failed to find interface references: %v", err)
+		return nil, nil, newDiagnostic(CodeLoadError, field.Position, fmt.Sprintf("failed to find interface references: %v", err), iface)
 	}
 
 	// 参照が見つからない場合
 	if len(refs) == 0 {
-		return nil, "no implementing types found"
+		return nil, nil, newDiagnostic(CodeNoImplementation, field.Position, "no implementing types found", iface)
 	}
 
 	// 複数の実装がある場合はスキップ
 	if len(refs) > 1 {
-		return nil, fmt.Sprintf("multiple implementing types found (%d)", len(refs))
+		diagnostic := newDiagnostic(CodeAmbiguousImplementation, field.Position, fmt.Sprintf("multiple implementing types found (%d)", len(refs)), iface)
+		for _, ref := range refs {
+			diagnostic.Candidates = append(diagnostic.Candidates, newCandidate(ref))
+		}
+		return nil, nil, diagnostic
 	}
 
 	// 実装型を再帰的に解析
 	ref := refs[0]
-	resolvedStruct, err := wa.analyzeStruct(ref.ImplementingPkgPath, ref.ImplementingType)
+	resolvedStruct, err := wa.analyzeStruct(ctx, ref.ImplementingPkgPath, ref.ImplementingType)
 	if err != nil {
-		return nil, fmt.Sprintf("failed to analyze implementing type: %v", err)
-	}
-
-	return resolvedStruct, ""
-}
-
-// isBuiltinType はビルトイン型かどうかを判定する
-func isBuiltinType(typeName string) bool {
-	builtinTypes := map[string]bool{
-		"string":     true,
-		"int":        true,
-		"int8":       true,
-		"int16":      true,
-		"int32":      true,
-		"int64":      true,
-		"uint":       true,
-		"uint8":      true,
-		"uint16":     true,
-		"uint32":     true,
-		"uint64":     true,
-		"float32":    true,
-		"float64":    true,
-		"bool":       true,
-		"byte":       true,
-		"rune":       true,
-		"error":      true,
-		"complex64":  true,
-		"complex128": true,
-	}
-	return builtinTypes[typeName]
+		diagnostic := newDiagnostic(CodeLoadError, field.Position, fmt.Sprintf("failed to analyze implementing type: %v", err), iface)
+		diagnostic.Candidates = []Candidate{newCandidate(ref)}
+		return nil, nil, diagnostic
+	}
+
+	implementation := newCandidate(ref)
+	return resolvedStruct, &implementation, nil
+}
+
+// newCandidate はインターフェースの参照から実装型の候補を作成する
+func newCandidate(ref packages.InterfaceReference) Candidate {
+	return Candidate{
+		Type: TypeRef{
+			TypeName:    ref.ImplementingType,
+			PackagePath: ref.ImplementingPkgPath,
+			IsPointer:   true,
+		},
+		Function:            ref.FunctionName,
+		FunctionPackagePath: ref.PackagePath,
+		Position:            ref.Position,
+		ReturnPosition:      ref.ReturnPosition,
+	}
+}
+
+// findInterfaceReferences はインターフェースの実装型を探す（定義パッケージの抽出済みの情報があればそれを使う）
+func (wa *WireAnalyzer) findInterfaceReferences(ctx context.Context, interfaceName, interfacePkgPath string) ([]packages.InterfaceReference, error) {
+	return wa.interfaceRefs.do(ctx, structKey(interfacePkgPath, interfaceName), func() ([]packages.InterfaceReference, error) {
+		if index := wa.loadFacts(ctx); index != nil {
+			implements := func(ref packages.InterfaceReference) bool {
+				return wa.implements(ctx, interfaceName, interfacePkgPath, ref)
+			}
+			if refs, ok := packages.FactsInterfaceReferences(interfaceName, interfacePkgPath, index.facts, implements); ok {
+				return refs, nil
+			}
+		}
+		release, err := wa.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		search, err := wa.loadInterfaceSearch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return search.References(interfaceName, interfacePkgPath), nil
+	})
+}
+
+// implements は抽出済みの情報ではシグネチャの表記が異なる実装型の候補を、検索対象のパッケージの型情報で確認する
+// any と interface{} のように表記だけが異なる場合があるため、types.Implementsで判定する
+func (wa *WireAnalyzer) implements(ctx context.Context, interfaceName, interfacePkgPath string, ref packages.InterfaceReference) bool {
+	release, err := wa.acquire(ctx)
+	if err != nil {
+		return false
+	}
+	defer release()
+	pkgs, err := wa.loadSearchPackages(ctx)
+	if err != nil {
+		return false
+	}
+
+	var iface *types.Interface
+	var impl types.Type
+	gopkgs.Visit(pkgs, nil, func(pkg *gopkgs.Package) {
+		if pkg.Types == nil {
+			return
+		}
+		if pkg.PkgPath == interfacePkgPath {
+			if obj, ok := pkg.Types.Scope().Lookup(interfaceName).(*types.TypeName); ok {
+				iface, _ = obj.Type().Underlying().(*types.Interface)
+			}
+		}
+		if pkg.PkgPath == ref.ImplementingPkgPath {
+			if obj, ok := pkg.Types.Scope().Lookup(ref.ImplementingType).(*types.TypeName); ok {
+				impl = obj.Type()
+			}
+		}
+	})
+	if iface == nil || impl == nil {
+		return false
+	}
+	return types.Implements(impl, iface) || types.Implements(types.NewPointer(impl), iface)
+}
+
+// loadInterfaceSearch は検索対象のパッケージからSSAを一度だけ構築する（インターフェースごとに読み込み直さない）
+func (wa *WireAnalyzer) loadInterfaceSearch(ctx context.Context) (*packages.InterfaceSearch, error) {
+	pkgs, err := wa.loadSearchPackages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	wa.searchMu.Lock()
+	defer wa.searchMu.Unlock()
+	if wa.ifaceSearch == nil {
+		wa.ifaceSearch = packages.NewInterfaceSearch(pkgs)
+	}
+	return wa.ifaceSearch, nil
 }
@@ -1,7 +1,10 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"go/token"
+	"path/filepath"
 	"testing"
 )
 
@@ -66,6 +69,22 @@ func printStructAnalysis(t *testing.T, result *StructNode, indent int) {
 				t.Logf("%s>%s -> %s",
 					prefix, interfaceNode.FieldName, interfaceNode.TypeName)
 			}
+		case NodeTypeCollection:
+			collectionNode := fieldNode.(*CollectionNode)
+			t.Logf("%s>%s -> %s [COLLECTION] %s",
+				prefix, collectionNode.FieldName, collectionNode.TypeString, collectionNode.ProvideHint)
+		case NodeTypeFunc:
+			funcNode := fieldNode.(*FuncNode)
+			t.Logf("%s>%s -> %s [FUNC] %s",
+				prefix, funcNode.FieldName, funcNode.TypeString, funcNode.ProvideHint)
+		case NodeTypeChan:
+			chanNode := fieldNode.(*ChanNode)
+			t.Logf("%s>%s -> %s [CHAN] %s",
+				prefix, chanNode.FieldName, chanNode.TypeString, chanNode.ProvideHint)
+		case NodeTypeInput:
+			inputNode := fieldNode.(*InputNode)
+			t.Logf("%s>%s -> %s [INPUT:%s]",
+				prefix, inputNode.FieldName, inputNode.TypeString, inputNode.Origin)
 		}
 	}
 }
@@ -77,7 +96,7 @@ func TestWireAnalyzer_AnalyzeStruct(t *testing.T) {
 	analyzer := NewWireAnalyzer(workDir, searchPattern)
 
 	// ControllerSetを直接解析
-	result, err := analyzer.analyzeStruct("", "ControllerSet")
+	result, err := analyzer.analyzeStruct(context.Background(), "", "ControllerSet")
 	if err != nil {
 		t.Fatalf("analyzeStruct failed: %v", err)
 	}
@@ -85,6 +104,88 @@ func TestWireAnalyzer_AnalyzeStruct(t *testing.T) {
 	printStructAnalysis(t, result, 0)
 }
 
+func TestWireAnalyzer_AnalyzeInjectors_Positions(t *testing.T) {
+	workDir := "../../sample/basic"
+	wireFilePath := "../../sample/basic/wire.go"
+
+	injectors, err := NewWireAnalyzer(workDir, "./...").AnalyzeInjectors(wireFilePath)
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+	if len(injectors) != 1 {
+		t.Fatalf("len(injectors) = %d, want 1", len(injectors))
+	}
+
+	root := injectors[0].Root
+	handler, ok := root.Fields[0].(*StructNode)
+	if !ok {
+		t.Fatalf("ControllerSet.handler is %T, want *StructNode", root.Fields[0])
+	}
+	service, ok := handler.Fields[0].(*InterfaceNode)
+	if !ok || service.Implementation == nil {
+		t.Fatalf("UserHandler.service = %+v, want a resolved interface", handler.Fields[0])
+	}
+	if len(handler.InitFunctions) != 1 {
+		t.Fatalf("len(UserHandler.InitFunctions) = %d, want 1", len(handler.InitFunctions))
+	}
+
+	tests := []struct {
+		name     string
+		pos      token.Position
+		wantFile string
+		wantLine int
+	}{
+		{"注入関数", injectors[0].Position, "wire.go", 18},
+		{"ルートの構造体の宣言", root.Position, "wire.go", 13},
+		{"構造体のフィールドの宣言", handler.FieldPosition, "wire.go", 14},
+		{"フィールドの構造体の宣言", handler.Position, "user_handler.go", 10},
+		{"提供関数の宣言", handler.InitFunctions[0].Position, "user_handler.go", 15},
+		{"インターフェースのフィールドの宣言", service.Position, "user_handler.go", 11},
+		{"実装型を返す関数の宣言", service.Implementation.Position, "user_service.go", 20},
+		{"実装型を返すreturn文", service.Implementation.ReturnPosition, "user_service.go", 21},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if filepath.Base(tt.pos.Filename) != tt.wantFile || tt.pos.Line != tt.wantLine {
+				t.Errorf("position = %s, want %s:%d", tt.pos, tt.wantFile, tt.wantLine)
+			}
+		})
+	}
+
+	if root.FieldPosition.IsValid() {
+		t.Errorf("root FieldPosition = %s, want zero", root.FieldPosition)
+	}
+	if service.Implementation.Function != "NewUserService" {
+		t.Errorf("Implementation.Function = %s, want NewUserService", service.Implementation.Function)
+	}
+}
+
+func TestWireAnalyzer_AnalyzeInjectors_SharedStructFields(t *testing.T) {
+	// repo.UserRepositoryはapi.Server.usersとworker.Worker.usersの2つのフィールドから参照される
+	injectors, err := NewWireAnalyzer("../../testdata/multi", "./...").AnalyzeInjectors("../../testdata/multi/wire.go")
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+
+	wantFiles := map[string]string{"InitializeAPI": "api.go", "InitializeWorker": "worker.go"}
+	for _, injector := range injectors {
+		outer, ok := injector.Root.Fields[0].(*StructNode)
+		if !ok || len(outer.Fields) == 0 {
+			t.Fatalf("%s: root field = %+v, want a struct with fields", injector.Name, injector.Root.Fields[0])
+		}
+		users, ok := outer.Fields[0].(*StructNode)
+		if !ok {
+			t.Fatalf("%s: %s.users is %T, want *StructNode", injector.Name, outer.StructName, outer.Fields[0])
+		}
+		if users.FieldName != "users" || filepath.Base(users.FieldPosition.Filename) != wantFiles[injector.Name] || users.FieldPosition.Line != 7 {
+			t.Errorf("%s: users field = %s at %s, want users at %s:7", injector.Name, users.FieldName, users.FieldPosition, wantFiles[injector.Name])
+		}
+		if injector.Root.FieldName != "" || injector.Root.FieldPosition.IsValid() {
+			t.Errorf("%s: root field = %q at %s, want none", injector.Name, injector.Root.FieldName, injector.Root.FieldPosition)
+		}
+	}
+}
+
 func ExampleWireAnalyzer_AnalyzeWireFile() {
 	workDir := "../../sample/basic"
 	wireFilePath := "../../sample/basic/wire.go"
@@ -144,6 +245,111 @@ func printStructAnalysisExample(result *StructNode, indent int) {
 				fmt.Printf("%s>%s -> %s\n",
 					prefix, interfaceNode.FieldName, interfaceNode.TypeName)
 			}
+		case NodeTypeCollection:
+			collectionNode := fieldNode.(*CollectionNode)
+			fmt.Printf("%s>%s -> %s [COLLECTION] %s\n",
+				prefix, collectionNode.FieldName, collectionNode.TypeString, collectionNode.ProvideHint)
+		case NodeTypeFunc:
+			funcNode := fieldNode.(*FuncNode)
+			fmt.Printf("%s>%s -> %s [FUNC] %s\n",
+				prefix, funcNode.FieldName, funcNode.TypeString, funcNode.ProvideHint)
+		case NodeTypeChan:
+			chanNode := fieldNode.(*ChanNode)
+			fmt.Printf("%s>%s -> %s [CHAN] %s\n",
+				prefix, chanNode.FieldName, chanNode.TypeString, chanNode.ProvideHint)
+		case NodeTypeInput:
+			inputNode := fieldNode.(*InputNode)
+			fmt.Printf("%s>%s -> %s [INPUT:%s]\n",
+				prefix, inputNode.FieldName, inputNode.TypeString, inputNode.Origin)
 		}
 	}
 }
+
+func TestWireAnalyzer_ResolveType(t *testing.T) {
+	analyzer := NewWireAnalyzer("../../testdata/inputs", "./...")
+
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "handler.Handler", want: "example.com/inputs/handler.Handler"},
+		{spec: "example.com/inputs/repo.DB", want: "example.com/inputs/repo.DB"},
+		{spec: "inputs/notify.Notifier", want: "example.com/inputs/notify.Notifier"},
+		{spec: "handler.Missing", wantErr: true},
+		{spec: "Handler", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := analyzer.ResolveType(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ResolveType(%q) = %v, want error", tt.spec, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveType(%q) failed: %v", tt.spec, err)
+			}
+			if got.Key() != tt.want {
+				t.Errorf("ResolveType(%q) = %s, want %s", tt.spec, got.Key(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWireAnalyzer_DiscoverProvidedTypes(t *testing.T) {
+	analyzer := NewWireAnalyzer("../../testdata/inputs", "./...")
+
+	got, err := analyzer.DiscoverProvidedTypes("./...", "*er")
+	if err != nil {
+		t.Fatalf("DiscoverProvidedTypes failed: %v", err)
+	}
+
+	// 提供関数のない型（Notifierインターフェース）は含まない
+	want := []string{
+		"*handler.Handler",
+		"*notify.EmailNotifier",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d types, want %d: %+v", len(got), len(want), got)
+	}
+	for i, ref := range got {
+		if ref.Qualified("") != want[i] {
+			t.Errorf("types[%d] = %s, want %s", i, ref.Qualified(""), want[i])
+		}
+	}
+
+	if _, err := analyzer.DiscoverProvidedTypes("./...", "[Handler"); err == nil {
+		t.Error("expected an error for an invalid name rule, got nil")
+	}
+}
+
+func TestWireInjectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     []string
+	}{
+		{"wireinjectタグのファイルの注入関数", "../../testdata/wirecheck/fixed/wire.go", []string{"InitializeService"}},
+		{"タグのないファイル", "../../testdata/wirecheck/fixed/fixed.go", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wireInjectors(tt.filename)
+			if err != nil {
+				t.Fatalf("wireInjectors failed: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("wireInjectors() = %v, want %v", got, tt.want)
+			}
+			for _, name := range tt.want {
+				if !got[name] {
+					t.Errorf("wireInjectors() = %v, want %s", got, name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Package cache はパッケージから抽出した情報をディスクに保存し、次回以降の解析で再利用する
+// キーはパッケージのファイルの内容とビルドフラグから作ったハッシュで、内容が変わったパッケージだけを読み込み直す
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+)
+
+// dirName はユーザーのキャッシュディレクトリに作るディレクトリ名
+const dirName = "convinient_wire"
+
+// DefaultDir はキャッシュを保存するディレクトリの既定値を返す（$XDG_CACHE_HOME/convinient_wire など）
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve user cache directory: %w", err)
+	}
+	return filepath.Join(dir, dirName), nil
+}
+
+// Cache はパッケージの抽出済みの情報をキーごとにファイルとして保存する
+type Cache struct {
+	dir string
+}
+
+// New はディレクトリを指定してCacheを作成する（ディレクトリは保存時に作成する）
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Dir はキャッシュを保存するディレクトリを返す
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Load はキーに対応する抽出済みの情報を読み込む（ない場合や壊れている場合はokがfalse）
+func (c *Cache) Load(key string) (facts *packages.PackageFacts, ok bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	if err := json.Unmarshal(data, &facts); err != nil || facts == nil {
+		return nil, false
+	}
+	return facts, true
+}
+
+// Store はキーに対応する抽出済みの情報を保存する
+// 同時に実行された別のプロセスが書きかけのファイルを読まないよう、一時ファイルに書いてから置き換える
+func (c *Cache) Store(key string, facts *packages.PackageFacts) error {
+	data, err := json.Marshal(facts)
+	if err != nil {
+		return fmt.Errorf("failed to encode package facts: %w", err)
+	}
+
+	filePath := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return nil
+}
+
+// path はキーに対応するファイルのパスを返す（1つのディレクトリにファイルが集中しないよう先頭2文字で分ける）
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
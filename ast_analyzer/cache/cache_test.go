@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+	"github.com/rmocchy/convinient_wire/internal/testutil"
+	gopkgs "golang.org/x/tools/go/packages"
+)
+
+func TestCache_StoreLoad(t *testing.T) {
+	c := New(t.TempDir())
+	key := "0123456789abcdef"
+
+	if _, ok := c.Load(key); ok {
+		t.Fatalf("Load() before Store ok = true, want false")
+	}
+
+	want := &packages.PackageFacts{
+		PackagePath: "example.com/app/db",
+		Name:        "db",
+		Types: []packages.TypeFacts{
+			{
+				Name:     "DB",
+				IsStruct: true,
+				Fields:   []packages.FieldInfo{{Name: "DSN", TypeName: "string", TypeString: "string"}},
+				Methods:  []string{"Close func() error"},
+			},
+		},
+	}
+	if err := c.Store(key, want); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	got, ok := c.Load(key)
+	if !ok {
+		t.Fatalf("Load() after Store ok = false, want true")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %+v, want %+v", got, want)
+	}
+
+	// 壊れたファイルはキャッシュにないものとして扱う
+	if err := os.WriteFile(c.path(key), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Load(key); ok {
+		t.Errorf("Load() of corrupted file ok = true, want false")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	dir := testutil.CopyFixture(t, "multi")
+
+	keys := func(buildFlags []string) map[string]string {
+		t.Helper()
+		cfg := &gopkgs.Config{
+			Mode:       gopkgs.NeedName | gopkgs.NeedFiles | gopkgs.NeedImports | gopkgs.NeedDeps | gopkgs.NeedModule,
+			Dir:        dir,
+			BuildFlags: buildFlags,
+		}
+		pkgs, err := gopkgs.Load(cfg, "./...")
+		if err != nil {
+			t.Fatalf("failed to load packages: %v", err)
+		}
+		keys, err := Keys(pkgs, buildFlags)
+		if err != nil {
+			t.Fatalf("Keys() error = %v", err)
+		}
+		return keys
+	}
+
+	before := keys(nil)
+	if again := keys(nil); !reflect.DeepEqual(again, before) {
+		t.Errorf("Keys() is not stable between loads")
+	}
+
+	// ビルドフラグが変わると全てのキーが変わる
+	withTags := keys([]string{"-tags=wireinject"})
+	if withTags["example.com/multi/db"] == before["example.com/multi/db"] {
+		t.Errorf("key of db did not change with build flags")
+	}
+
+	// ファイルを変更すると、そのパッケージと依存しているパッケージのキーだけが変わる
+	repoPath := filepath.Join(dir, "repo", "repo.go")
+	src, err := os.ReadFile(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(repoPath, append(src, "\n// changed\n"...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	after := keys(nil)
+
+	for _, pkgPath := range []string{"example.com/multi/repo", "example.com/multi/api", "example.com/multi/worker"} {
+		if after[pkgPath] == before[pkgPath] {
+			t.Errorf("key of %s did not change", pkgPath)
+		}
+	}
+	if after["example.com/multi/db"] != before["example.com/multi/db"] {
+		t.Errorf("key of example.com/multi/db changed")
+	}
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	gopkgs "golang.org/x/tools/go/packages"
+)
+
+// formatVersion はキャッシュに保存する情報の形式のバージョン（PackageFactsを変更したら上げる）
+const formatVersion = "5"
+
+// Keys は読み込んだパッケージとその依存先ごとにキャッシュのキーを計算する（パッケージパス -> キー）
+// メインモジュールのパッケージはファイルの内容、それ以外（標準ライブラリやモジュールキャッシュ）はファイルの更新日時とサイズを使い、
+// 依存先のキーも含めることで、フィールドの型などの依存先の変更でも抽出済みの情報を作り直す
+// pkgsはNeedName・NeedFiles・NeedImports・NeedDeps・NeedModuleで読み込まれていること
+func Keys(pkgs []*gopkgs.Package, buildFlags []string) (map[string]string, error) {
+	keys := make(map[string]string)
+	var firstErr error
+
+	// 依存先を先に訪れるため、親のキーを計算する時点で依存先のキーは揃っている
+	gopkgs.Visit(pkgs, nil, func(pkg *gopkgs.Package) {
+		if firstErr != nil {
+			return
+		}
+		key, err := packageKey(pkg, buildFlags, keys)
+		if err != nil {
+			firstErr = err
+			return
+		}
+		keys[pkg.PkgPath] = key
+	})
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return keys, nil
+}
+
+// packageKey は1つのパッケージのキーを計算する
+func packageKey(pkg *gopkgs.Package, buildFlags []string, depKeys map[string]string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "version %s\n", formatVersion)
+	for _, flag := range buildFlags {
+		fmt.Fprintf(h, "flag %s\n", flag)
+	}
+	fmt.Fprintf(h, "package %s %s\n", pkg.PkgPath, pkg.Name)
+
+	main := pkg.Module != nil && pkg.Module.Main
+	switch {
+	case pkg.Module == nil:
+		fmt.Fprintln(h, "module std")
+	default:
+		fmt.Fprintf(h, "module %s %s %t\n", pkg.Module.Path, pkg.Module.Version, main)
+	}
+
+	files := append([]string(nil), pkg.GoFiles...)
+	sort.Strings(files)
+	for _, filePath := range files {
+		if err := hashFile(h, filePath, main); err != nil {
+			return "", err
+		}
+	}
+
+	imports := make([]string, 0, len(pkg.Imports))
+	for importPath := range pkg.Imports {
+		imports = append(imports, importPath)
+	}
+	sort.Strings(imports)
+	for _, importPath := range imports {
+		fmt.Fprintf(h, "import %s %s\n", importPath, depKeys[pkg.Imports[importPath].PkgPath])
+	}
+
+	for _, err := range pkg.Errors {
+		fmt.Fprintf(h, "error %s\n", err.Msg)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFile はファイルをハッシュに加える（contentがfalseの場合は更新日時とサイズだけを使う）
+func hashFile(h io.Writer, filePath string, content bool) error {
+	if !content {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", filePath, err)
+		}
+		fmt.Fprintf(h, "file %s %d %d\n", filePath, info.Size(), info.ModTime().UnixNano())
+		return nil
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	defer f.Close()
+
+	fmt.Fprintf(h, "file %s\n", filePath)
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	fmt.Fprintln(h)
+	return nil
+}
@@ -1,13 +1,43 @@
 package file
 
+import "go/token"
+
 // StructInfo は構造体の情報を保持する構造体
 type StructInfo struct {
-	Name      string // 構造体名
-	IsPointer bool   // ポインタ型かどうか
+	Name        string // 構造体名
+	IsPointer   bool   // ポインタ型かどうか
+	PackagePath string // importパス（wire.goと同じパッケージの型の場合は空）
 }
 
 // FunctionInfo は関数の情報を保持する構造体
 type FunctionInfo struct {
-	Name        string
-	ReturnTypes []StructInfo // エラー型以外の返り値の構造体情報
+	Name         string
+	ReturnTypes  []StructInfo   // エラー型以外の返り値の構造体情報
+	ReturnsError bool           // 返り値にerror型を含むかどうか
+	Position     token.Position // 関数名の位置
+}
+
+// InjectorBody は生成済みの注入関数の本体から読み取った内容を保持する構造体
+type InjectorBody struct {
+	Name       string         // 注入関数名
+	Params     []string       // 引数名
+	ParamTypes []string       // 引数の型（importパスで修飾、Paramsと同じ順）
+	Results    []string       // 返り値の型（importパスで修飾）
+	Calls      []ProviderCall // 本体で呼び出している提供関数（呼び出し順）
+}
+
+// ProviderCall は注入関数の本体での提供関数の呼び出し、または構造体リテラルを表す
+type ProviderCall struct {
+	Func     string   // 呼び出した関数（"importパス.関数名"、同じパッケージの場合は関数名のみ）。構造体リテラルの場合は型名
+	Var      string   // 結果を代入した変数名
+	Args     []string // 引数の由来（提供関数の結果は関数名、そのフィールドは"関数名.フィールド名"、注入関数の引数は"param:型"、設定された値は式）
+	ArgVars  []string // 引数に渡している変数名（変数以外の式の場合は空文字）
+	Fields   []string // 構造体リテラルのフィールド名（Argsと同じ順）
+	IsStruct bool     // 構造体リテラルかどうか
+}
+
+// WireGenFile は生成済みのwire_gen.goの内容を保持する構造体
+type WireGenFile struct {
+	Generator string         // 生成したツール（GeneratorWireまたはGeneratorConvinientWire）
+	Injectors []InjectorBody // 注入関数
 }
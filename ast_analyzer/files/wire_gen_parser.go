@@ -0,0 +1,287 @@
+package file
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// ParamPrefix は注入関数の引数から渡された値の由来に付ける接頭辞
+const ParamPrefix = "param:"
+
+// wire_gen.goを生成したツール
+const (
+	GeneratorWire           = "wire"            // github.com/google/wire
+	GeneratorConvinientWire = "convinient_wire" // このツールのgenコマンド
+)
+
+// generatorHeaders は生成ツールごとの生成コードのヘッダー
+var generatorHeaders = map[string]string{
+	"// Code generated by Wire. DO NOT EDIT.":            GeneratorWire,
+	"// Code generated by convinient_wire. DO NOT EDIT.": GeneratorConvinientWire,
+}
+
+// ParseWireGenFile は生成済みのwire_gen.goをパースして、注入関数ごとの提供関数の呼び出しグラフを取得する
+// 生成コードのヘッダーがないファイルはエラーにする
+func ParseWireGenFile(filename string, src any) (*WireGenFile, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	// ヘッダーはpackage宣言より前に書かれている
+	generator := ""
+	for _, group := range node.Comments {
+		if group.Pos() > node.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if g, ok := generatorHeaders[comment.Text]; ok {
+				generator = g
+			}
+		}
+	}
+	if generator == "" {
+		return nil, fmt.Errorf("%s is not a generated wire file", filename)
+	}
+
+	return &WireGenFile{Generator: generator, Injectors: injectorBodies(node)}, nil
+}
+
+// ParseInjectorBodies はwire_gen.goをパースして、注入関数の本体で呼び出している提供関数を取得する
+// srcがnilの場合はfilenameのファイルを読み込む（parser.ParseFileと同じ）
+// 変数名に依存しないように、引数は値を作った提供関数の名前で表す
+func ParseInjectorBodies(filename string, src any) ([]InjectorBody, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	return injectorBodies(node), nil
+}
+
+// injectorBodies はパース済みのファイルから注入関数の本体を取り出す
+func injectorBodies(node *ast.File) []InjectorBody {
+	importMap := extractImports(node)
+	packageVars := packageValues(node)
+
+	// "// wire.go:" 以降はwire.goからコピーされた宣言なので注入関数として扱わない
+	copiedFrom := token.NoPos
+	for _, group := range node.Comments {
+		if strings.TrimSpace(group.Text()) == "wire.go:" {
+			copiedFrom = group.Pos()
+			break
+		}
+	}
+
+	var bodies []InjectorBody
+	for _, decl := range node.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Recv != nil || funcDecl.Body == nil {
+			continue
+		}
+		if copiedFrom.IsValid() && funcDecl.Pos() > copiedFrom {
+			continue
+		}
+		bodies = append(bodies, parseInjectorBody(funcDecl, importMap, packageVars))
+	}
+
+	return bodies
+}
+
+// packageValues はパッケージレベルの変数の初期値を集める
+// wireはwire.Valueの値を_wireXxxValueのような変数に置き、注入関数の本体ではその変数を代入する
+func packageValues(node *ast.File) map[string]ast.Expr {
+	values := make(map[string]ast.Expr)
+	for _, decl := range node.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok || len(valueSpec.Names) != len(valueSpec.Values) {
+				continue
+			}
+			for i, name := range valueSpec.Names {
+				values[name.Name] = valueSpec.Values[i]
+			}
+		}
+	}
+	return values
+}
+
+// parseInjectorBody は注入関数の本体から提供関数の呼び出しを順に取り出す
+func parseInjectorBody(funcDecl *ast.FuncDecl, importMap map[string]string, packageVars map[string]ast.Expr) InjectorBody {
+	body := InjectorBody{Name: funcDecl.Name.Name}
+
+	// 変数名 -> 値の由来
+	sources := make(map[string]string)
+	for _, field := range funcDecl.Type.Params.List {
+		typeExpr := qualifiedExpr(field.Type, importMap)
+		for _, name := range field.Names {
+			body.Params = append(body.Params, name.Name)
+			body.ParamTypes = append(body.ParamTypes, typeExpr)
+			sources[name.Name] = ParamPrefix + typeExpr
+		}
+	}
+	if results := funcDecl.Type.Results; results != nil {
+		for _, field := range results.List {
+			typeExpr := qualifiedExpr(field.Type, importMap)
+			for range max(len(field.Names), 1) {
+				body.Results = append(body.Results, typeExpr)
+			}
+		}
+	}
+
+	// 呼び出し順が依存関係と矛盾していても引数の由来を特定できるように、先に変数を登録する
+	var assigns []*ast.AssignStmt
+	for _, stmt := range funcDecl.Body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || len(assign.Rhs) != 1 || len(assign.Lhs) == 0 {
+			continue
+		}
+		if _, ok := parseProviderCall(assign.Rhs[0], sources, importMap); !ok {
+			continue
+		}
+		assigns = append(assigns, assign)
+	}
+	for _, assign := range assigns {
+		call, _ := parseProviderCall(assign.Rhs[0], sources, importMap)
+		if ident, ok := assign.Lhs[0].(*ast.Ident); ok {
+			sources[ident.Name] = call.Func
+		}
+	}
+
+	// 構造体のフィールドや設定された値を代入した変数は、変数名ではなく値の由来で表す
+	// 例: wireのstring2 := config2.DSN と、このツールのdsn := config2.DSN は同じ値
+	for _, stmt := range funcDecl.Body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || len(assign.Rhs) != 1 || len(assign.Lhs) != 1 {
+			continue
+		}
+		ident, ok := assign.Lhs[0].(*ast.Ident)
+		if !ok {
+			continue
+		}
+		if source, ok := valueSource(assign.Rhs[0], sources, importMap, packageVars); ok {
+			sources[ident.Name] = source
+		}
+	}
+
+	for _, assign := range assigns {
+		call, _ := parseProviderCall(assign.Rhs[0], sources, importMap)
+		if ident, ok := assign.Lhs[0].(*ast.Ident); ok {
+			call.Var = ident.Name
+		}
+		body.Calls = append(body.Calls, call)
+	}
+
+	return body
+}
+
+// valueSource は提供関数の呼び出し以外の代入の右辺から値の由来を求める
+// 提供関数の結果のフィールドは"由来.フィールド名"、パッケージの値や定数はimportパスで修飾した式で表す
+func valueSource(expr ast.Expr, sources map[string]string, importMap map[string]string, packageVars map[string]ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok {
+			return "", false
+		}
+		if source, ok := sources[x.Name]; ok {
+			return source + "." + e.Sel.Name, true
+		}
+		if _, ok := importMap[x.Name]; ok {
+			return qualifiedExpr(e, importMap), true
+		}
+	case *ast.Ident:
+		if source, ok := sources[e.Name]; ok {
+			return source, true
+		}
+		if value, ok := packageVars[e.Name]; ok {
+			// wireが値を置いた変数は初期値の式で表す（変数同士の参照はたどらない）
+			return valueSource(value, sources, importMap, nil)
+		}
+	case *ast.BasicLit:
+		return e.Value, true
+	}
+	return "", false
+}
+
+// parseProviderCall は代入の右辺から提供関数の呼び出しまたは構造体リテラルを読み取る
+func parseProviderCall(expr ast.Expr, sources map[string]string, importMap map[string]string) (ProviderCall, bool) {
+	argSource := func(arg ast.Expr) string {
+		if ident, ok := arg.(*ast.Ident); ok {
+			if source, ok := sources[ident.Name]; ok {
+				return source
+			}
+		}
+		return types.ExprString(arg)
+	}
+	argVar := func(arg ast.Expr) string {
+		if ident, ok := arg.(*ast.Ident); ok && ident.Name != "nil" {
+			return ident.Name
+		}
+		return ""
+	}
+
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unary.X
+	}
+
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		call := ProviderCall{Func: qualifiedExpr(e.Fun, importMap)}
+		for _, arg := range e.Args {
+			call.Args = append(call.Args, argSource(arg))
+			call.ArgVars = append(call.ArgVars, argVar(arg))
+		}
+		return call, true
+
+	case *ast.CompositeLit:
+		call := ProviderCall{Func: qualifiedExpr(e.Type, importMap), IsStruct: true}
+		for _, elt := range e.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			if key, ok := kv.Key.(*ast.Ident); ok {
+				call.Fields = append(call.Fields, key.Name)
+				call.Args = append(call.Args, argSource(kv.Value))
+				call.ArgVars = append(call.ArgVars, argVar(kv.Value))
+			}
+		}
+		return call, true
+	}
+
+	return ProviderCall{}, false
+}
+
+// qualifiedExpr は式の文字列表現を、パッケージ名をimportパスに置き換えて返す（例: "*repository.Config" -> "*github.com/.../repository.Config"）
+func qualifiedExpr(expr ast.Expr, importMap map[string]string) string {
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		if pkgIdent, ok := e.X.(*ast.Ident); ok {
+			if path, ok := importMap[pkgIdent.Name]; ok {
+				return path + "." + e.Sel.Name
+			}
+		}
+	case *ast.StarExpr:
+		return "*" + qualifiedExpr(e.X, importMap)
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return "[]" + qualifiedExpr(e.Elt, importMap)
+		}
+	case *ast.MapType:
+		return "map[" + qualifiedExpr(e.Key, importMap) + "]" + qualifiedExpr(e.Value, importMap)
+	case *ast.ParenExpr:
+		return qualifiedExpr(e.X, importMap)
+	}
+	return types.ExprString(expr)
+}
@@ -0,0 +1,154 @@
+package file
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseInjectorBodies(t *testing.T) {
+	wireGenPath := filepath.Join("..", "..", "sample", "basic", "wire_gen.go")
+
+	bodies, err := ParseInjectorBodies(wireGenPath, nil)
+	if err != nil {
+		t.Fatalf("ParseInjectorBodies failed: %v", err)
+	}
+
+	// wire.goからコピーされた宣言は含まない
+	if len(bodies) != 1 || bodies[0].Name != "InitializeUserHandler" {
+		t.Fatalf("unexpected injectors: %+v", bodies)
+	}
+
+	const base = "github.com/rmocchy/convinient_wire/sample/basic/"
+	want := []ProviderCall{
+		{Func: base + "repository.NewConfig", Var: "config"},
+		{Func: base + "repository.NewUserRepository", Var: "userRepository", Args: []string{base + "repository.NewConfig"}, ArgVars: []string{"config"}},
+		{Func: base + "service.NewUserService", Var: "userService", Args: []string{base + "repository.NewUserRepository"}, ArgVars: []string{"userRepository"}},
+		{Func: base + "handler.NewUserHandler", Var: "userHandler", Args: []string{base + "service.NewUserService"}, ArgVars: []string{"userService"}},
+		{Func: "ControllerSet", Var: "controllerSet", Args: []string{base + "handler.NewUserHandler"}, ArgVars: []string{"userHandler"}, Fields: []string{"handler"}, IsStruct: true},
+	}
+	if !reflect.DeepEqual(bodies[0].Calls, want) {
+		t.Errorf("Calls = %+v\nwant %+v", bodies[0].Calls, want)
+	}
+}
+
+func TestParseInjectorBodies_Params(t *testing.T) {
+	src := `package main
+
+import (
+	"context"
+
+	db "example.com/app/database"
+)
+
+func InitializeApp(ctx context.Context, dsn string) (*App, error) {
+	conn, err := db.Open(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+	app := &App{conn: conn}
+	return app, nil
+}
+`
+	bodies, err := ParseInjectorBodies("wire_gen.go", src)
+	if err != nil {
+		t.Fatalf("ParseInjectorBodies failed: %v", err)
+	}
+
+	want := []ProviderCall{
+		{Func: "example.com/app/database.Open", Var: "conn", Args: []string{"param:context.Context", "param:string"}, ArgVars: []string{"ctx", "dsn"}},
+		{Func: "App", Var: "app", Args: []string{"example.com/app/database.Open"}, ArgVars: []string{"conn"}, Fields: []string{"conn"}, IsStruct: true},
+	}
+	if !reflect.DeepEqual(bodies[0].Params, []string{"ctx", "dsn"}) {
+		t.Errorf("Params = %v", bodies[0].Params)
+	}
+	if !reflect.DeepEqual(bodies[0].ParamTypes, []string{"context.Context", "string"}) {
+		t.Errorf("ParamTypes = %v", bodies[0].ParamTypes)
+	}
+	if !reflect.DeepEqual(bodies[0].Results, []string{"*App", "error"}) {
+		t.Errorf("Results = %v", bodies[0].Results)
+	}
+	if !reflect.DeepEqual(bodies[0].Calls, want) {
+		t.Errorf("Calls = %+v\nwant %+v", bodies[0].Calls, want)
+	}
+}
+
+func TestParseInjectorBodies_FieldsAndValues(t *testing.T) {
+	// wireはwire.FieldsOfのフィールドをstring2のような型名の変数に、wire.Valueの値を_wireXxxValueに置く
+	src := `package main
+
+import (
+	"net/http"
+
+	"example.com/app/config"
+	"example.com/app/db"
+)
+
+func InitializeApp() *App {
+	client := _wireClientValue
+	config2 := config.NewConfig()
+	string2 := config2.DSN
+	db2 := db.NewDB(string2, client, 10)
+	app := &App{db: db2}
+	return app
+}
+
+var (
+	_wireClientValue = http.DefaultClient
+)
+`
+	bodies, err := ParseInjectorBodies("wire_gen.go", src)
+	if err != nil {
+		t.Fatalf("ParseInjectorBodies failed: %v", err)
+	}
+
+	want := []string{"example.com/app/config.NewConfig.DSN", "net/http.DefaultClient", "10"}
+	if got := bodies[0].Calls[1].Args; !reflect.DeepEqual(got, want) {
+		t.Errorf("Args = %v, want %v", got, want)
+	}
+}
+
+func TestParseWireGenFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		src           any
+		wantGenerator string
+		wantErr       bool
+	}{
+		{
+			name:          "generated by wire",
+			path:          filepath.Join("..", "..", "sample", "basic", "wire_gen.go"),
+			wantGenerator: GeneratorWire,
+		},
+		{
+			name:          "generated by convinient_wire",
+			path:          "wire_gen.go",
+			src:           "// Code generated by convinient_wire. DO NOT EDIT.\n\npackage main\n",
+			wantGenerator: GeneratorConvinientWire,
+		},
+		{
+			name:    "not generated",
+			path:    filepath.Join("..", "..", "sample", "basic", "wire.go"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			genFile, err := ParseWireGenFile(tt.path, tt.src)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWireGenFile failed: %v", err)
+			}
+			if genFile.Generator != tt.wantGenerator {
+				t.Errorf("Generator = %q, want %q", genFile.Generator, tt.wantGenerator)
+			}
+		})
+	}
+}
@@ -36,8 +36,10 @@ func ParseWireFileStructs(filepath string) ([]FunctionInfo, error) {
 		returnTypes := extractStructTypes(funcDecl.Type.Results, importMap)
 
 		functions = append(functions, FunctionInfo{
-			Name:        funcDecl.Name.Name,
-			ReturnTypes: returnTypes,
+			Name:         funcDecl.Name.Name,
+			ReturnTypes:  returnTypes,
+			ReturnsError: returnsError(funcDecl.Type.Results),
+			Position:     fset.Position(funcDecl.Name.Pos()),
 		})
 
 		return true
@@ -113,6 +115,19 @@ func extractStructTypes(results *ast.FieldList, importMap map[string]string) []S
 	return structs
 }
 
+// returnsError は返り値にerror型が含まれるかどうかを判定
+func returnsError(results *ast.FieldList) bool {
+	if results == nil {
+		return false
+	}
+	for _, field := range results.List {
+		if ident, ok := field.Type.(*ast.Ident); ok && ident.Name == "error" {
+			return true
+		}
+	}
+	return false
+}
+
 // parseStructType は式から構造体情報を抽出
 func parseStructType(expr ast.Expr, importMap map[string]string) StructInfo {
 	switch t := expr.(type) {
@@ -129,12 +144,13 @@ func parseStructType(expr ast.Expr, importMap map[string]string) StructInfo {
 		return info
 	case *ast.SelectorExpr:
 		// パッケージ.型名の形式
-		if _, ok := t.X.(*ast.Ident); ok {
+		if pkgIdent, ok := t.X.(*ast.Ident); ok {
 			structName := t.Sel.Name
 
 			return StructInfo{
-				Name:      structName,
-				IsPointer: false,
+				Name:        structName,
+				IsPointer:   false,
+				PackagePath: importMap[pkgIdent.Name],
 			}
 		}
 	}
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"go/ast"
 	"path/filepath"
 	"testing"
 )
@@ -55,6 +56,39 @@ func TestParseWireFileStructs(t *testing.T) {
 		t.Error("Expected pointer type, but got non-pointer")
 	}
 
+	// wire.goと同じパッケージの型なのでimportパスは空
+	if structInfo.PackagePath != "" {
+		t.Errorf("Expected empty package path, got %s", structInfo.PackagePath)
+	}
+
+	// (*ControllerSet, error) なのでerrorを返す
+	if !targetFunc.ReturnsError {
+		t.Error("Expected function to return error")
+	}
+
 	t.Logf("Successfully parsed function: %s", targetFunc.Name)
 	t.Logf("  Struct: %s, IsPointer: %v", structInfo.Name, structInfo.IsPointer)
 }
+
+func TestParseStructType_Selector(t *testing.T) {
+	importMap := map[string]string{
+		"handler": "github.com/rmocchy/convinient_wire/sample/basic/handler",
+	}
+
+	expr := &ast.StarExpr{X: &ast.SelectorExpr{
+		X:   ast.NewIdent("handler"),
+		Sel: ast.NewIdent("UserHandler"),
+	}}
+
+	info := parseStructType(expr, importMap)
+
+	if info.Name != "UserHandler" {
+		t.Errorf("Expected struct name UserHandler, got %s", info.Name)
+	}
+	if !info.IsPointer {
+		t.Error("Expected pointer type, but got non-pointer")
+	}
+	if info.PackagePath != importMap["handler"] {
+		t.Errorf("Expected package path %s, got %s", importMap["handler"], info.PackagePath)
+	}
+}
@@ -0,0 +1,54 @@
+package packages
+
+import (
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ClassifyPackagePath はパッケージパスが標準ライブラリ・サードパーティ・メインモジュールのどれに属するかを判定する
+// pkgPath: 判定するパッケージパス（空文字列の場合はOriginBasic）
+// pkgs: パッケージ解決に使ったパッケージ群（NeedDepsとNeedModuleでロードされていること）
+func ClassifyPackagePath(pkgPath string, pkgs []*packages.Package) TypeOrigin {
+	return newOriginClassifier(pkgs).classify(pkgPath)
+}
+
+// originClassifier はパッケージパスからモジュール情報を引くためのインデックス
+type originClassifier struct {
+	byPath map[string]*packages.Package
+}
+
+// newOriginClassifier はパッケージ群とその依存先からインデックスを作成する
+func newOriginClassifier(pkgs []*packages.Package) *originClassifier {
+	byPath := make(map[string]*packages.Package)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		byPath[pkg.PkgPath] = pkg
+	})
+	return &originClassifier{byPath: byPath}
+}
+
+// classify はパッケージパスを分類する
+func (c *originClassifier) classify(pkgPath string) TypeOrigin {
+	if pkgPath == "" {
+		return OriginBasic
+	}
+
+	// モジュール情報がある場合はそれを使う
+	if pkg, ok := c.byPath[pkgPath]; ok {
+		switch {
+		case pkg.Module == nil:
+			return OriginStdlib
+		case pkg.Module.Main:
+			return OriginModuleLocal
+		default:
+			return OriginThirdParty
+		}
+	}
+
+	// ロードされていないパッケージは先頭要素にドットを含むかで推測する
+	first, _, _ := strings.Cut(pkgPath, "/")
+	if strings.Contains(first, ".") {
+		return OriginThirdParty
+	}
+	return OriginStdlib
+}
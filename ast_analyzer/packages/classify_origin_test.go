@@ -0,0 +1,70 @@
+package packages
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestClassifyPackagePath(t *testing.T) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedModule,
+		Dir:  "../../testdata/externals",
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("failed to load packages: %v", err)
+	}
+
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("packages have errors")
+	}
+
+	tests := []struct {
+		name    string
+		pkgPath string
+		want    TypeOrigin
+	}{
+		{name: "パッケージなし", pkgPath: "", want: OriginBasic},
+		{name: "標準ライブラリ", pkgPath: "net/http", want: OriginStdlib},
+		{name: "メインモジュール", pkgPath: "example.com/externals", want: OriginModuleLocal},
+		{name: "サードパーティ", pkgPath: "example.com/vendorlib", want: OriginThirdParty},
+		{name: "未ロードの標準ライブラリ", pkgPath: "encoding/json", want: OriginStdlib},
+		{name: "未ロードのサードパーティ", pkgPath: "github.com/google/wire", want: OriginThirdParty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyPackagePath(tt.pkgPath, pkgs); got != tt.want {
+				t.Errorf("ClassifyPackagePath(%q) = %v, want %v", tt.pkgPath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractStructFieldsOrigin(t *testing.T) {
+	info, err := ExtractStructFields("../../testdata/externals", "example.com/externals", "Service")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := map[string]TypeOrigin{
+		"ctx":      OriginStdlib,
+		"db":       OriginStdlib,
+		"client":   OriginStdlib,
+		"out":      OriginStdlib,
+		"timeout":  OriginStdlib,
+		"name":     OriginBasic,
+		"err":      OriginBasic,
+		"dsn":      OriginModuleLocal,
+		"vendor":   OriginThirdParty,
+		"settings": OriginModuleLocal,
+	}
+
+	for _, field := range info.Fields {
+		if field.Origin != want[field.Name] {
+			t.Errorf("%s: Origin = %v, want %v", field.Name, field.Origin, want[field.Name])
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package packages
 
 import (
+	"context"
 	"fmt"
+	"go/token"
 	"go/types"
 
 	"golang.org/x/tools/go/packages"
@@ -9,16 +11,32 @@ import (
 
 // ExtractStructFields は作業ディレクトリを指定してpackagePathと構造体名から構造体のフィールド情報を取得する
 // workDir: パッケージ解決の基準となる作業ディレクトリ（空文字列の場合はカレントディレクトリ）
-// packagePath: パッケージパス（モジュールパスまたは相対パス）
+// packagePath: パッケージパス（モジュールパスまたは相対パス、空文字列の場合は作業ディレクトリのパッケージ）
 // structName: 取得する構造体の名前
 func ExtractStructFields(workDir, packagePath, structName string) (*StructFieldsInfo, error) {
-	// パッケージをロード
+	return ExtractStructFieldsContext(context.Background(), workDir, packagePath, structName)
+}
+
+// ExtractStructFieldsContext はExtractStructFieldsと同様に構造体のフィールド情報を取得する
+// ctxが取り消されるとパッケージの読み込みを中断し、ctx.Err()を含むエラーを返す
+func ExtractStructFieldsContext(ctx context.Context, workDir, packagePath, structName string) (*StructFieldsInfo, error) {
+	if packagePath == "" {
+		packagePath = "."
+	}
+
+	// パッケージをロード（wire.goに定義された構造体も対象にするためwireinjectタグを付ける）
 	cfg := &packages.Config{
-		Mode: packages.LoadAllSyntax,
-		Dir:  workDir,
+		Mode:       packages.LoadAllSyntax | packages.NeedModule,
+		Dir:        workDir,
+		BuildFlags: BuildFlags(),
+		Context:    ctx,
 	}
 
 	pkgs, err := packages.Load(cfg, packagePath)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		// go/packagesのエラーからは取り消されたかを判定できないため、ctxのエラーを返す
+		return nil, fmt.Errorf("failed to load package: %w", ctxErr)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to load package: %w", err)
 	}
@@ -57,16 +75,24 @@ func ExtractStructFields(workDir, packagePath, structName string) (*StructFields
 	}
 
 	// フィールド情報を抽出
-	fields := extractFields(structType)
+	fields := extractFields(pkg.Fset, structType)
+
+	// 各フィールドの型がどこで定義されているかを分類
+	classifier := newOriginClassifier(pkgs)
+	for i := range fields {
+		fields[i].Origin = classifier.classify(fields[i].PackagePath)
+	}
 
 	return &StructFieldsInfo{
-		StructName: structName,
-		Fields:     fields,
+		StructName:  structName,
+		PackageName: pkg.Name,
+		Fields:      fields,
+		Position:    pkg.Fset.Position(typeName.Pos()),
 	}, nil
 }
 
 // extractFields は構造体のフィールド情報を抽出する
-func extractFields(structType *types.Struct) []FieldInfo {
+func extractFields(fset *token.FileSet, structType *types.Struct) []FieldInfo {
 	var fields []FieldInfo
 
 	for i := 0; i < structType.NumFields(); i++ {
@@ -74,6 +100,7 @@ func extractFields(structType *types.Struct) []FieldInfo {
 		fieldType := field.Type()
 
 		fieldInfo := parseFieldType(field.Name(), fieldType)
+		fieldInfo.Position = fset.Position(field.Pos())
 		fields = append(fields, fieldInfo)
 	}
 
@@ -83,7 +110,8 @@ func extractFields(structType *types.Struct) []FieldInfo {
 // parseFieldType はフィールドの型情報を解析してFieldInfoを作成する
 func parseFieldType(fieldName string, fieldType types.Type) FieldInfo {
 	info := FieldInfo{
-		Name: fieldName,
+		Name:       fieldName,
+		TypeString: typeString(fieldType),
 	}
 
 	// ポインタ型の場合は剥がす
@@ -102,6 +130,7 @@ func parseFieldType(fieldName string, fieldType types.Type) FieldInfo {
 		// パッケージ情報を取得
 		if pkg := obj.Pkg(); pkg != nil {
 			info.PackagePath = pkg.Path()
+			info.PackageName = pkg.Name()
 		}
 
 		// Named型の基底型がインターフェースかどうかチェック
@@ -119,5 +148,35 @@ func parseFieldType(fieldName string, fieldType types.Type) FieldInfo {
 		}
 	}
 
+	// 基底型から種類を判定
+	setFieldKind(&info, fieldType.Underlying())
+
 	return info
 }
+
+// setFieldKind は基底型から型の種類と要素型を設定する
+func setFieldKind(info *FieldInfo, underlying types.Type) {
+	switch t := underlying.(type) {
+	case *types.Struct:
+		info.Kind = FieldKindStruct
+	case *types.Interface:
+		info.Kind = FieldKindInterface
+	case *types.Slice:
+		info.Kind = FieldKindSlice
+		info.ElemType = typeString(t.Elem())
+	case *types.Array:
+		info.Kind = FieldKindArray
+		info.ElemType = typeString(t.Elem())
+	case *types.Map:
+		info.Kind = FieldKindMap
+		info.KeyType = typeString(t.Key())
+		info.ElemType = typeString(t.Elem())
+	case *types.Signature:
+		info.Kind = FieldKindFunc
+	case *types.Chan:
+		info.Kind = FieldKindChan
+		info.ElemType = typeString(t.Elem())
+	default:
+		info.Kind = FieldKindOther
+	}
+}
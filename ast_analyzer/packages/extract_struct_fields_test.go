@@ -1,6 +1,8 @@
 package packages
 
 import (
+	"context"
+	"errors"
 	"path/filepath"
 	"testing"
 )
@@ -30,6 +32,14 @@ func TestExtractStructFields(t *testing.T) {
 					t.Fatalf("Expected 1 field, got %d", len(info.Fields))
 				}
 
+				// 構造体とフィールドの宣言の位置
+				if filepath.Base(info.Position.Filename) != "user_handler.go" || info.Position.Line != 10 {
+					t.Errorf("Position = %s, want user_handler.go:10", info.Position)
+				}
+				if filepath.Base(info.Fields[0].Position.Filename) != "user_handler.go" || info.Fields[0].Position.Line != 11 {
+					t.Errorf("field Position = %s, want user_handler.go:11", info.Fields[0].Position)
+				}
+
 				field := info.Fields[0]
 				if field.Name != "service" {
 					t.Errorf("Expected field name 'service', got '%s'", field.Name)
@@ -137,3 +147,64 @@ func TestExtractStructFieldsWithPointerFields(t *testing.T) {
 	// 実際のサンプルにポインタフィールドがある場合はここでテスト
 	t.Skip("Skipping pointer field test - add when sample with pointer fields is available")
 }
+
+func TestExtractStructFieldsKinds(t *testing.T) {
+	workDir := filepath.Join("..", "..", "testdata", "fieldkinds")
+
+	info, err := ExtractStructFields(workDir, "example.com/fieldkinds", "App")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		fieldName      string
+		wantKind       FieldKind
+		wantTypeString string
+		wantElemType   string
+		wantKeyType    string
+	}{
+		{"plugins", FieldKindSlice, "[]fieldkinds.Plugin", "fieldkinds.Plugin", ""},
+		{"handlers", FieldKindMap, "map[string]fieldkinds.Handler", "fieldkinds.Handler", "string"},
+		{"now", FieldKindFunc, "func() time.Time", "", ""},
+		{"events", FieldKindChan, "chan fieldkinds.Event", "fieldkinds.Event", ""},
+		{"named", FieldKindSlice, "fieldkinds.Plugins", "fieldkinds.Plugin", ""},
+		{"clock", FieldKindFunc, "fieldkinds.Clock", "", ""},
+		{"fixed", FieldKindArray, "[2]fieldkinds.Plugin", "fieldkinds.Plugin", ""},
+	}
+
+	fields := make(map[string]FieldInfo)
+	for _, field := range info.Fields {
+		fields[field.Name] = field
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fieldName, func(t *testing.T) {
+			field, ok := fields[tt.fieldName]
+			if !ok {
+				t.Fatalf("field %s not found", tt.fieldName)
+			}
+			if field.Kind != tt.wantKind {
+				t.Errorf("Kind = %v, want %v", field.Kind, tt.wantKind)
+			}
+			if field.TypeString != tt.wantTypeString {
+				t.Errorf("TypeString = %s, want %s", field.TypeString, tt.wantTypeString)
+			}
+			if field.ElemType != tt.wantElemType {
+				t.Errorf("ElemType = %s, want %s", field.ElemType, tt.wantElemType)
+			}
+			if field.KeyType != tt.wantKeyType {
+				t.Errorf("KeyType = %s, want %s", field.KeyType, tt.wantKeyType)
+			}
+		})
+	}
+}
+
+func TestExtractStructFieldsContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ExtractStructFieldsContext(ctx, "../../sample/basic", "github.com/rmocchy/convinient_wire/sample/basic/handler", "UserHandler")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ExtractStructFieldsContext() error = %v, want context.Canceled", err)
+	}
+}
@@ -13,6 +13,7 @@ import (
 // 注意: インターフェースは対象外です
 func FindFunctionsReturningStruct(structName, structPkgPath string, pkgs []*packages.Package) []FunctionInfo {
 	var functions []FunctionInfo
+	classifier := newOriginClassifier(pkgs)
 
 	for _, pkg := range pkgs {
 		scope := pkg.Types.Scope()
@@ -31,20 +32,17 @@ func FindFunctionsReturningStruct(structName, structPkgPath string, pkgs []*pack
 				continue
 			}
 
-			// 返り値をチェック
-			results := sig.Results()
-			if results == nil {
+			// 返り値をチェック（複数の値を返す関数は提供関数にならない）
+			if !hasSingleResult(sig) {
 				continue
 			}
+			results := sig.Results()
 
 			// 各返り値が指定された構造体かどうかをチェック
 			for i := 0; i < results.Len(); i++ {
 				result := results.At(i)
 				if matchesStructType(result.Type(), structName, structPkgPath) {
-					functions = append(functions, FunctionInfo{
-						Name:        fn.Name(),
-						PackagePath: pkg.PkgPath,
-					})
+					functions = append(functions, newFunctionInfo(pkg.Fset, fn, pkg.PkgPath, classifier))
 					break // 同じ関数を複数回追加しないように
 				}
 			}
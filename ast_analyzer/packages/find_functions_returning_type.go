@@ -0,0 +1,74 @@
+package packages
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// FindFunctionsReturningType は指定されたNamed型を返り値に持つ関数を探す
+// FindFunctionsReturningStructと異なり、基底型が構造体以外（基本型、インターフェース、関数型など）のNamed型も対象とする
+// typeName: 型名
+// typePkgPath: 型が定義されているパッケージパス
+// pkgs: 検索対象のパッケージ群
+func FindFunctionsReturningType(typeName, typePkgPath string, pkgs []*packages.Package) []FunctionInfo {
+	var functions []FunctionInfo
+	classifier := newOriginClassifier(pkgs)
+
+	for _, pkg := range pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+		for _, fn := range FunctionsReturningType(typeName, typePkgPath, []*types.Package{pkg.Types}) {
+			functions = append(functions, newFunctionInfo(pkg.Fset, fn, pkg.PkgPath, classifier))
+		}
+	}
+
+	return functions
+}
+
+// FunctionsReturningType は型情報のパッケージ群から、指定されたNamed型（またはそのポインタ）を返り値に持つ関数を探す
+func FunctionsReturningType(typeName, typePkgPath string, pkgs []*types.Package) []*types.Func {
+	var functions []*types.Func
+
+	for _, pkg := range pkgs {
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			fn, ok := scope.Lookup(name).(*types.Func)
+			if !ok {
+				continue
+			}
+
+			sig := fn.Type().(*types.Signature)
+			if !hasSingleResult(sig) {
+				continue
+			}
+			results := sig.Results()
+			for i := 0; i < results.Len(); i++ {
+				if matchesNamedType(results.At(i).Type(), typeName, typePkgPath) {
+					functions = append(functions, fn)
+					break // 同じ関数を複数回追加しないように
+				}
+			}
+		}
+	}
+
+	return functions
+}
+
+// matchesNamedType は型が指定されたNamed型（またはそのポインタ）と一致するかチェック
+func matchesNamedType(t types.Type, typeName, typePkgPath string) bool {
+	t = types.Unalias(derefType(t))
+
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+
+	obj := named.Obj()
+	if obj.Name() != typeName || obj.Pkg() == nil {
+		return false
+	}
+
+	return obj.Pkg().Path() == typePkgPath
+}
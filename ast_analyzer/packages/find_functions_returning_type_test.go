@@ -0,0 +1,65 @@
+package packages
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestFindFunctionsReturningType(t *testing.T) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
+			packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir: "../../testdata/externals",
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("failed to load packages: %v", err)
+	}
+
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("packages have errors")
+	}
+
+	tests := []struct {
+		name        string
+		typeName    string
+		typePkgPath string
+		wantFuncs   []string
+	}{
+		{
+			name:        "標準ライブラリの構造体を返す関数",
+			typeName:    "Client",
+			typePkgPath: "net/http",
+			wantFuncs:   []string{"NewHTTPClient"},
+		},
+		{
+			name:        "モジュール内の構造体を返す関数",
+			typeName:    "Settings",
+			typePkgPath: "example.com/externals",
+			wantFuncs:   []string{"NewSettings"},
+		},
+		{
+			name:        "提供する関数がない型",
+			typeName:    "Duration",
+			typePkgPath: "time",
+			wantFuncs:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			functions := FindFunctionsReturningType(tt.typeName, tt.typePkgPath, pkgs)
+
+			if len(functions) != len(tt.wantFuncs) {
+				t.Fatalf("got %d functions, want %d: %+v", len(functions), len(tt.wantFuncs), functions)
+			}
+			for i, fn := range functions {
+				if fn.Name != tt.wantFuncs[i] {
+					t.Errorf("functions[%d] = %s, want %s", i, fn.Name, tt.wantFuncs[i])
+				}
+			}
+		})
+	}
+}
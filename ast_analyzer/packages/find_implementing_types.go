@@ -0,0 +1,128 @@
+package packages
+
+import (
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// FindImplementingTypes は検索対象のパッケージ内の全てのNamed型を走査し、指定されたインターフェースを実装する型を探す
+// interfaceName: インターフェース名
+// interfacePkgPath: インターフェースが定義されているパッケージパス
+// pkgs: 検索対象のパッケージ群（インターフェースの定義パッケージは依存先に含まれていればよい）
+// T と *T の両方についてtypes.Implementsで判定する
+func FindImplementingTypes(interfaceName, interfacePkgPath string, pkgs []*packages.Package) []InterfaceReference {
+	iface := lookupInterface(pkgs, interfaceName, interfacePkgPath)
+	if iface == nil {
+		return nil
+	}
+
+	// 同じLoadで読み込んだパッケージはFileSetを共有している
+	var searched []*types.Package
+	var fset *token.FileSet
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 || pkg.Types == nil {
+			continue
+		}
+		searched = append(searched, pkg.Types)
+		fset = pkg.Fset
+	}
+
+	var references []InterfaceReference
+	for _, typeName := range ImplementingTypes(iface, searched) {
+		references = append(references, InterfaceReference{
+			ImplementingType:    typeName.Name(),
+			ImplementingPkgPath: typeName.Pkg().Path(),
+			FoundBy:             StrategyMethodSet,
+			Position:            fset.Position(typeName.Pos()),
+		})
+	}
+
+	return references
+}
+
+// ImplementingTypes は型情報のパッケージ群から、インターフェースを実装するNamed型を探す
+// go/packagesでロードしたパッケージがない場合（go/analysisのパスなど）にも使えるように型情報だけを受け取る
+func ImplementingTypes(iface *types.Interface, pkgs []*types.Package) []*types.TypeName {
+	var implementing []*types.TypeName
+
+	for _, pkg := range pkgs {
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			typeName, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || typeName.IsAlias() {
+				continue
+			}
+
+			named, ok := typeName.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+
+			// インターフェース自身やジェネリック型は対象外
+			if _, isInterface := named.Underlying().(*types.Interface); isInterface {
+				continue
+			}
+			if named.TypeParams().Len() > 0 {
+				continue
+			}
+
+			if !types.Implements(named, iface) && !types.Implements(types.NewPointer(named), iface) {
+				continue
+			}
+
+			implementing = append(implementing, typeName)
+		}
+	}
+
+	return implementing
+}
+
+// lookupInterface はパッケージ群とその依存先からインターフェース型を探す
+func lookupInterface(pkgs []*packages.Package, interfaceName, interfacePkgPath string) *types.Interface {
+	var iface *types.Interface
+
+	packages.Visit(pkgs, func(pkg *packages.Package) bool {
+		if iface != nil {
+			return false
+		}
+		if pkg.Types == nil || pkg.Types.Path() != interfacePkgPath {
+			return true
+		}
+
+		typeName, ok := pkg.Types.Scope().Lookup(interfaceName).(*types.TypeName)
+		if !ok {
+			return false
+		}
+		if it, ok := typeName.Type().Underlying().(*types.Interface); ok {
+			iface = it
+		}
+		return false
+	}, nil)
+
+	return iface
+}
+
+// mergeReferences はコンストラクタ戦略とメソッドセット戦略の結果をマージする
+// 同じ実装型が両方で見つかった場合はコンストラクタ側の参照にフラグを追加する
+func mergeReferences(constructorRefs, methodSetRefs []InterfaceReference) []InterfaceReference {
+	merged := make([]InterfaceReference, 0, len(constructorRefs)+len(methodSetRefs))
+	merged = append(merged, constructorRefs...)
+
+	for _, implRef := range methodSetRefs {
+		found := false
+		for i := range merged {
+			if merged[i].ImplementingType == implRef.ImplementingType &&
+				merged[i].ImplementingPkgPath == implRef.ImplementingPkgPath {
+				merged[i].FoundBy |= StrategyMethodSet
+				found = true
+			}
+		}
+		if !found {
+			merged = append(merged, implRef)
+		}
+	}
+
+	return merged
+}
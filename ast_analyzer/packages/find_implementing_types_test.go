@@ -0,0 +1,105 @@
+package packages
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestFindImplementingTypes(t *testing.T) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
+			packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir: "../../testdata/implementations",
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("failed to load packages: %v", err)
+	}
+
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("packages have errors")
+	}
+
+	refs := FindImplementingTypes("Notifier", "example.com/implementations", pkgs)
+
+	got := make(map[string]DiscoveryStrategy)
+	for _, ref := range refs {
+		got[ref.ImplementingType] = ref.FoundBy
+		if ref.FunctionName != "" {
+			t.Errorf("メソッドセット戦略の参照に関数名が設定されています: %+v", ref)
+		}
+	}
+
+	// T と *T の両方のメソッドセットで判定されること
+	for _, want := range []string{"emailNotifier", "SlackNotifier"} {
+		strategy, ok := got[want]
+		if !ok {
+			t.Errorf("実装型 %s が見つかりませんでした。取得した参照: %+v", want, refs)
+			continue
+		}
+		if strategy != StrategyMethodSet {
+			t.Errorf("%s の戦略が期待と異なります。got = %v", want, strategy)
+		}
+	}
+
+	// インターフェース自身は含まれないこと
+	if _, ok := got["Notifier"]; ok {
+		t.Error("インターフェース自身が実装型として返されました")
+	}
+}
+
+func TestFindInterfaceReferences_MergeStrategies(t *testing.T) {
+	refs, err := FindInterfaceReferences(
+		"../../testdata/implementations",
+		"Notifier",
+		"example.com/implementations",
+		"./...",
+	)
+	if err != nil {
+		t.Fatalf("FindInterfaceReferences() error = %v", err)
+	}
+
+	if len(refs) != 2 {
+		t.Fatalf("参照の数が期待と異なります。got = %d, refs = %+v", len(refs), refs)
+	}
+
+	for _, ref := range refs {
+		switch ref.ImplementingType {
+		case "emailNotifier":
+			// コンストラクタとメソッドセットの両方で見つかる
+			if ref.FunctionName != "NewEmailNotifier" {
+				t.Errorf("関数名が期待と異なります。got = %s", ref.FunctionName)
+			}
+			if !ref.FoundBy.Has(StrategyConstructor) || !ref.FoundBy.Has(StrategyMethodSet) {
+				t.Errorf("emailNotifier の戦略が期待と異なります。got = %v", ref.FoundBy)
+			}
+		case "SlackNotifier":
+			// コンストラクタがないためメソッドセットのみで見つかる
+			if ref.FoundBy != StrategyMethodSet {
+				t.Errorf("SlackNotifier の戦略が期待と異なります。got = %v", ref.FoundBy)
+			}
+		default:
+			t.Errorf("予期しない実装型: %+v", ref)
+		}
+	}
+}
+
+func TestDiscoveryStrategy_String(t *testing.T) {
+	tests := []struct {
+		strategy DiscoveryStrategy
+		expected string
+	}{
+		{StrategyConstructor, "constructor"},
+		{StrategyMethodSet, "method-set"},
+		{StrategyConstructor | StrategyMethodSet, "constructor+method-set"},
+		{0, "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.strategy.String(); got != tt.expected {
+			t.Errorf("String() = %v, want %v", got, tt.expected)
+		}
+	}
+}
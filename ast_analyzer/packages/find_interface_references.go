@@ -1,19 +1,26 @@
 package packages
 
 import (
+	"context"
 	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
 
 	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
 )
 
 // InterfaceReference はインターフェースを参照する関数の情報を保持する
 type InterfaceReference struct {
-	FunctionName        string // 関数名
-	PackagePath         string // 関数が定義されているパッケージパス
-	ImplementingType    string // 対応づけられた実装型の名前
-	ImplementingPkgPath string // 実装型のパッケージパス
+	FunctionName        string            // 関数名（メソッドセット戦略のみで見つかった場合は空）
+	PackagePath         string            // 関数が定義されているパッケージパス
+	ImplementingType    string            // 対応づけられた実装型の名前
+	ImplementingPkgPath string            // 実装型のパッケージパス
+	FoundBy             DiscoveryStrategy // 実装型を見つけた戦略
+	Position            token.Position    // 関数の宣言の位置（メソッドセット戦略のみで見つかった場合は実装型の宣言の位置）
+	ReturnPosition      token.Position    // 実装型を返すreturn文の位置（メソッドセット戦略のみで見つかった場合はゼロ値）
 }
 
 // FindInterfaceReferences は指定されたインターフェースを参照する関数とそこで対応づけられた構造体を返す
@@ -22,14 +29,26 @@ type InterfaceReference struct {
 // interfacePkgPath: インターフェースが定義されているパッケージパス
 // searchPattern: 検索対象のパッケージパターン（例: "./...", "github.com/user/repo/..."）
 func FindInterfaceReferences(workDir, interfaceName, interfacePkgPath, searchPattern string) ([]InterfaceReference, error) {
+	return FindInterfaceReferencesContext(context.Background(), workDir, interfaceName, interfacePkgPath, searchPattern)
+}
+
+// FindInterfaceReferencesContext はFindInterfaceReferencesと同様にインターフェースの実装型を探す
+// ctxが取り消されるとパッケージの読み込みを中断し、SSAを構築せずにctx.Err()を含むエラーを返す
+func FindInterfaceReferencesContext(ctx context.Context, workDir, interfaceName, interfacePkgPath, searchPattern string) ([]InterfaceReference, error) {
 	// パッケージをロード
 	cfg := &packages.Config{
 		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
-			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports,
-		Dir: workDir,
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		Dir:        workDir,
+		BuildFlags: BuildFlags(),
+		Context:    ctx,
 	}
 
 	pkgs, err := packages.Load(cfg, searchPattern)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		// go/packagesのエラーからは取り消されたかを判定できないため、ctxのエラーを返す
+		return nil, fmt.Errorf("failed to load packages: %w", ctxErr)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to load packages: %w", err)
 	}
@@ -38,75 +57,99 @@ func FindInterfaceReferences(workDir, interfaceName, interfacePkgPath, searchPat
 		return nil, fmt.Errorf("no packages found for pattern: %s", searchPattern)
 	}
 
+	return NewInterfaceSearch(pkgs).References(interfaceName, interfacePkgPath), nil
+}
+
+// InterfaceSearch は読み込んだパッケージとSSAを保持し、複数のインターフェースの検索で使い回す
+// SSAの構築は重いため、インターフェースごとに読み込み直さないようにする
+type InterfaceSearch struct {
+	pkgs    []*packages.Package
+	prog    *ssa.Program
+	ssaPkgs []*ssa.Package
+}
+
+// NewInterfaceSearch は構文木と型情報を含むパッケージ（依存パッケージを含む）からSSAを構築する
+func NewInterfaceSearch(pkgs []*packages.Package) *InterfaceSearch {
+	// 関数本体のデータフローを追跡するためにSSAを構築
+	prog, ssaPkgs := ssautil.Packages(pkgs, 0)
+	prog.Build()
+
+	return &InterfaceSearch{pkgs: pkgs, prog: prog, ssaPkgs: ssaPkgs}
+}
+
+// References は指定されたインターフェースを参照する関数とそこで対応づけられた構造体を返す
+// 構築済みのSSAを読むだけなので、複数のゴルーチンから同時に呼び出せる
+func (s *InterfaceSearch) References(interfaceName, interfacePkgPath string) []InterfaceReference {
 	var references []InterfaceReference
 
-	// 各パッケージを検索
-	for _, pkg := range pkgs {
-		if len(pkg.Errors) > 0 {
+	// 各パッケージを検索（コンストラクタ戦略）
+	for i, pkg := range s.pkgs {
+		if len(pkg.Errors) > 0 || s.ssaPkgs[i] == nil {
 			// エラーがあっても他のパッケージを処理
 			continue
 		}
 
-		pkgRefs := findReferencesInPackage(pkg, interfaceName, interfacePkgPath)
+		pkgRefs := findReferencesInPackage(s.prog, pkg, interfaceName, interfacePkgPath)
 		references = append(references, pkgRefs...)
 	}
 
-	return references, nil
+	// メソッドセット戦略の結果をマージ
+	implRefs := FindImplementingTypes(interfaceName, interfacePkgPath, s.pkgs)
+	return mergeReferences(references, implRefs)
 }
 
 // findReferencesInPackage は特定のパッケージ内でインターフェース参照を検索
-func findReferencesInPackage(pkg *packages.Package, interfaceName, interfacePkgPath string) []InterfaceReference {
+func findReferencesInPackage(prog *ssa.Program, pkg *packages.Package, interfaceName, interfacePkgPath string) []InterfaceReference {
 	var references []InterfaceReference
 
-	// パッケージ内の全ての関数を走査
+	// パッケージ内の全ての関数宣言を走査（関数リテラルは関数宣言の一部として扱う）
 	for _, file := range pkg.Syntax {
-		ast.Inspect(file, func(n ast.Node) bool {
-			// 関数宣言を探す
-			funcDecl, ok := n.(*ast.FuncDecl)
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
 			if !ok {
-				return true
+				continue
 			}
 
-			// コンストラクタ関数かどうか（Newで始まる）をチェック
-			// また、戻り値の型がインターフェースかどうかをチェック
-			refs := checkFunctionForInterface(pkg, funcDecl, interfaceName, interfacePkgPath)
+			// 戻り値の型がインターフェースかどうかをチェック
+			refs := checkFunctionForInterface(prog, pkg, funcDecl, interfaceName, interfacePkgPath)
 			references = append(references, refs...)
-
-			return true
-		})
+		}
 	}
 
 	return references
 }
 
 // checkFunctionForInterface は関数がインターフェースを参照しているかチェック
-func checkFunctionForInterface(pkg *packages.Package, funcDecl *ast.FuncDecl, interfaceName, interfacePkgPath string) []InterfaceReference {
+func checkFunctionForInterface(prog *ssa.Program, pkg *packages.Package, funcDecl *ast.FuncDecl, interfaceName, interfacePkgPath string) []InterfaceReference {
 	var references []InterfaceReference
 
-	if funcDecl.Type.Results == nil {
+	fnObj, ok := pkg.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+	if !ok {
 		return references
 	}
 
+	results := fnObj.Type().(*types.Signature).Results()
+
 	// 戻り値の型をチェック
-	for _, result := range funcDecl.Type.Results.List {
-		resultType := pkg.TypesInfo.TypeOf(result.Type)
-		if resultType == nil {
+	for i := 0; i < results.Len(); i++ {
+		resultType := results.At(i).Type()
+
+		// インターフェース型かチェック
+		if !isTargetInterface(resultType, interfaceName, interfacePkgPath) {
 			continue
 		}
 
-		// インターフェース型かチェック
-		if isTargetInterface(resultType, interfaceName, interfacePkgPath) {
-			// 関数本体から実装型を探す
-			implType := findImplementingType(pkg, funcDecl, resultType)
-			if implType != nil {
-				ref := InterfaceReference{
-					FunctionName:        funcDecl.Name.Name,
-					PackagePath:         pkg.PkgPath,
-					ImplementingType:    getTypeName(implType),
-					ImplementingPkgPath: getPackagePath(implType),
-				}
-				references = append(references, ref)
-			}
+		// 関数本体のデータフローから実装型を探す（複数あれば全て報告）
+		for _, impl := range findImplementingTypes(prog.FuncValue(fnObj), i) {
+			references = append(references, InterfaceReference{
+				FunctionName:        funcDecl.Name.Name,
+				PackagePath:         pkg.PkgPath,
+				ImplementingType:    getTypeName(impl.typ),
+				ImplementingPkgPath: getPackagePath(impl.typ),
+				FoundBy:             StrategyConstructor,
+				Position:            pkg.Fset.Position(funcDecl.Name.Pos()),
+				ReturnPosition:      pkg.Fset.Position(impl.returnPos),
+			})
 		}
 	}
 
@@ -143,47 +186,3 @@ func isTargetInterface(t types.Type, interfaceName, interfacePkgPath string) boo
 
 	return obj.Pkg().Path() == interfacePkgPath
 }
-
-// findImplementingType は関数本体から実装型を探す
-func findImplementingType(pkg *packages.Package, funcDecl *ast.FuncDecl, interfaceType types.Type) types.Type {
-	if funcDecl.Body == nil {
-		return nil
-	}
-
-	var implType types.Type
-
-	// 関数本体を走査してreturn文を探す
-	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
-		retStmt, ok := n.(*ast.ReturnStmt)
-		if !ok {
-			return true
-		}
-
-		// return文の値を検査
-		for _, expr := range retStmt.Results {
-			exprType := pkg.TypesInfo.TypeOf(expr)
-			if exprType == nil {
-				continue
-			}
-
-			// インターフェース型に代入可能な具象型を見つける
-			if types.AssignableTo(exprType, interfaceType) {
-				// ポインタの場合は元の型を取得
-				if ptr, ok := exprType.(*types.Pointer); ok {
-					if named, ok := ptr.Elem().(*types.Named); ok {
-						implType = named
-						return false
-					}
-				}
-				if named, ok := exprType.(*types.Named); ok {
-					implType = named
-					return false
-				}
-			}
-		}
-
-		return true
-	})
-
-	return implType
-}
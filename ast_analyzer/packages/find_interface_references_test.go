@@ -1,7 +1,12 @@
 package packages
 
 import (
+	"context"
+	"errors"
+	"reflect"
 	"testing"
+
+	"golang.org/x/tools/go/packages"
 )
 
 func TestFindInterfaceReferences(t *testing.T) {
@@ -89,7 +94,7 @@ func TestFindInterfaceReferences(t *testing.T) {
 				t.Logf("見つかった参照:")
 				for _, ref := range refs {
 					t.Logf("  関数: %s (パッケージ: %s)", ref.FunctionName, ref.PackagePath)
-					t.Logf("    実装型: %s (パッケージ: %s)", ref.ImplementingType, ref.ImplementingPkgPath)
+					t.Logf("    実装型: %s (パッケージ: %s, 戦略: %s)", ref.ImplementingType, ref.ImplementingPkgPath, ref.FoundBy)
 				}
 			}
 		})
@@ -145,3 +150,41 @@ func TestFindInterfaceReferences_InvalidWorkDir(t *testing.T) {
 		t.Error("無効な作業ディレクトリでエラーが発生しませんでした")
 	}
 }
+
+func TestFindInterfaceReferencesContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := FindInterfaceReferencesContext(ctx, "../../sample/basic", "UserService", "github.com/rmocchy/convinient_wire/sample/basic/service", "./...")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("FindInterfaceReferencesContext() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestInterfaceSearch_References(t *testing.T) {
+	// 一度構築したSSAで複数のインターフェースを検索しても、個別に読み込んだ場合と同じ結果になる
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		Dir:        "../../sample/basic",
+		BuildFlags: BuildFlags(),
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("failed to load packages: %v", err)
+	}
+	search := NewInterfaceSearch(pkgs)
+
+	for _, iface := range []struct{ name, pkgPath string }{
+		{"UserService", "github.com/rmocchy/convinient_wire/sample/basic/service"},
+		{"UserRepository", "github.com/rmocchy/convinient_wire/sample/basic/repository"},
+	} {
+		want, err := FindInterfaceReferences("../../sample/basic", iface.name, iface.pkgPath, "./...")
+		if err != nil {
+			t.Fatalf("FindInterfaceReferences(%s) error = %v", iface.name, err)
+		}
+		if got := search.References(iface.name, iface.pkgPath); !reflect.DeepEqual(got, want) {
+			t.Errorf("References(%s) = %+v, want %+v", iface.name, got, want)
+		}
+	}
+}
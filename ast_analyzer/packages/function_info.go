@@ -0,0 +1,74 @@
+package packages
+
+import (
+	"go/token"
+	"go/types"
+)
+
+// newFunctionInfo は関数オブジェクトから引数と返り値の情報を含むFunctionInfoを作成する
+func newFunctionInfo(fset *token.FileSet, fn *types.Func, pkgPath string, classifier *originClassifier) FunctionInfo {
+	info := FunctionInfo{
+		Name:        fn.Name(),
+		PackagePath: pkgPath,
+		PackageName: fn.Pkg().Name(),
+		Position:    fset.Position(fn.Pos()),
+	}
+
+	sig := fn.Type().(*types.Signature)
+
+	// 引数
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		param := params.At(i)
+		paramInfo := parseFieldType(param.Name(), param.Type())
+		paramInfo.Origin = classifier.classify(paramInfo.PackagePath)
+		info.Params = append(info.Params, paramInfo)
+	}
+
+	// 返り値（error・クリーンアップ関数とそれ以外に分ける）
+	results := sig.Results()
+	resultSet := false
+	for i := 0; i < results.Len(); i++ {
+		resultType := results.At(i).Type()
+		switch {
+		case isErrorType(resultType):
+			info.ReturnsError = true
+		case isCleanupFunc(resultType):
+			info.HasCleanup = true
+		case !resultSet:
+			info.Result = parseFieldType("", resultType)
+			info.Result.Origin = classifier.classify(info.Result.PackagePath)
+			resultSet = true
+		}
+	}
+
+	return info
+}
+
+// hasSingleResult はerrorとクリーンアップ関数を除いた返り値が1つだけかどうかを判定する
+// (*A, *B) のように複数の値を返す関数は、Wireと同様にどちらの型の提供関数としても扱わない
+func hasSingleResult(sig *types.Signature) bool {
+	count := 0
+	results := sig.Results()
+	for i := 0; i < results.Len(); i++ {
+		resultType := results.At(i).Type()
+		if !isErrorType(resultType) && !isCleanupFunc(resultType) {
+			count++
+		}
+	}
+	return count == 1
+}
+
+// isErrorType はerror型かどうかを判定する
+func isErrorType(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// isCleanupFunc は引数も返り値も持たない関数型（Wireのクリーンアップ関数）かどうかを判定する
+func isCleanupFunc(t types.Type) bool {
+	sig, ok := types.Unalias(t).(*types.Signature)
+	if !ok {
+		return false
+	}
+	return sig.Params().Len() == 0 && sig.Results().Len() == 0
+}
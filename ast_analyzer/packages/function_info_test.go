@@ -0,0 +1,69 @@
+package packages
+
+import (
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestNewFunctionInfo(t *testing.T) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+			packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedModule,
+		Dir: "../../testdata/externals",
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("failed to load packages: %v", err)
+	}
+
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("packages have errors")
+	}
+
+	functions := FindFunctionsReturningType("DB", "database/sql", pkgs)
+	if len(functions) != 1 {
+		t.Fatalf("got %d functions, want 1: %+v", len(functions), functions)
+	}
+
+	fn := functions[0]
+	if fn.Name != "NewDB" {
+		t.Errorf("Name = %s, want NewDB", fn.Name)
+	}
+	if !fn.ReturnsError {
+		t.Error("Expected ReturnsError to be true")
+	}
+	if !fn.HasCleanup {
+		t.Error("Expected HasCleanup to be true")
+	}
+	if filepath.Base(fn.Position.Filename) != "service.go" || fn.Position.Line != 46 {
+		t.Errorf("Position = %s, want service.go:46", fn.Position)
+	}
+
+	// 提供する型
+	if fn.Result.TypeString != "*sql.DB" || !fn.Result.IsPointer {
+		t.Errorf("Result = %+v, want *sql.DB", fn.Result)
+	}
+
+	// 引数
+	wantParams := []struct {
+		name       string
+		typeString string
+		origin     TypeOrigin
+	}{
+		{"ctx", "context.Context", OriginStdlib},
+		{"dsn", "externals.DSN", OriginModuleLocal},
+	}
+	if len(fn.Params) != len(wantParams) {
+		t.Fatalf("got %d params, want %d: %+v", len(fn.Params), len(wantParams), fn.Params)
+	}
+	for i, want := range wantParams {
+		param := fn.Params[i]
+		if param.Name != want.name || param.TypeString != want.typeString || param.Origin != want.origin {
+			t.Errorf("Params[%d] = {%s %s %v}, want {%s %s %v}",
+				i, param.Name, param.TypeString, param.Origin, want.name, want.typeString, want.origin)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package packages
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+)
+
+// PackageFacts は1つのパッケージから抽出した、依存関係の解析に使う情報
+// 型情報を読み込まずに構造体のフィールド・提供関数・インターフェースの実装型を探せるよう、
+// ディスクにキャッシュできる値だけで構成する
+type PackageFacts struct {
+	PackagePath  string            // パッケージパス
+	Name         string            // パッケージ名
+	Dir          string            // パッケージのディレクトリ
+	HasErrors    bool              // 読み込みや型検査でエラーがあったかどうか
+	Types        []TypeFacts       // パッケージレベルのNamed型（名前順）
+	Functions    []FunctionFacts   // パッケージレベルの関数（名前順）
+	Constructors []ConstructorFact // インターフェースを返す関数のreturn文から辿った実装型（宣言順）
+}
+
+// TypeFacts はパッケージレベルのNamed型の情報
+type TypeFacts struct {
+	Name        string         // 型名
+	IsStruct    bool           // 基底型が構造体かどうか
+	IsInterface bool           // 基底型がインターフェースかどうか
+	IsGeneric   bool           // 型パラメータを持つかどうか
+	Fields      []FieldInfo    // 構造体のフィールド
+	Methods     []string       // インターフェースの場合はメソッド、それ以外は *T のメソッドセット（methodKeyの形式）
+	Position    token.Position // 型の宣言の位置
+}
+
+// FunctionFacts はパッケージレベルの関数の情報
+type FunctionFacts struct {
+	FunctionInfo
+	Returns []ReturnedType // 返り値のNamed型（ポインタは剥がす、複数の値を返す関数は空）
+}
+
+// ReturnedType は関数が返すNamed型
+type ReturnedType struct {
+	Name        string // 型名
+	PackagePath string // 型が定義されているパッケージパス
+	IsStruct    bool   // 基底型が構造体かどうか
+}
+
+// ConstructorFact はインターフェースを返す関数と、return文から辿った実装型の組
+type ConstructorFact struct {
+	FunctionName        string         // 関数名
+	InterfaceName       string         // 返り値のインターフェース名
+	InterfacePkgPath    string         // 返り値のインターフェースのパッケージパス
+	ImplementingType    string         // 実装型の名前
+	ImplementingPkgPath string         // 実装型のパッケージパス
+	Position            token.Position // 関数の宣言の位置
+	ReturnPosition      token.Position // 実装型を返すreturn文の位置
+}
+
+// ExtractPackageFacts は読み込んだパッケージから解析に使う情報を抽出する
+// pkg: 構文木と型情報を含めて読み込んだパッケージ
+// prog: pkgを含むSSAプログラム（構築済みであること）
+// pkgs: フィールドの型がどこで定義されているかの分類に使うパッケージ群（NeedDepsとNeedModuleでロードされていること）
+func ExtractPackageFacts(pkg *packages.Package, prog *ssa.Program, pkgs []*packages.Package) *PackageFacts {
+	if pkg.Types == nil {
+		return &PackageFacts{PackagePath: pkg.PkgPath, Name: pkg.Name, Dir: pkg.Dir, HasErrors: true}
+	}
+	hasErrors := len(pkg.Errors) > 0
+	// エラーのあるパッケージはSSAを構築できないためコンストラクタ戦略の対象外
+	if hasErrors {
+		prog = nil
+	}
+	facts := extractFacts(pkg.Fset, pkg.Types, pkg.Syntax, pkg.TypesInfo, prog, newOriginClassifier(pkgs))
+	facts.Dir = pkg.Dir
+	facts.HasErrors = hasErrors
+	return facts
+}
+
+// ExtractFacts はExtractPackageFactsと同様に、型検査済みのパッケージから解析に使う情報を抽出する
+// go/analysisのPassのように、go/packagesを使わずに型検査したパッケージに使う（型の定義元はパッケージパスから推測する）
+// prog: pkgを含むSSAプログラム（構築済みであること。nilの場合はコンストラクタ戦略の情報を抽出しない）
+func ExtractFacts(fset *token.FileSet, pkg *types.Package, files []*ast.File, info *types.Info, prog *ssa.Program) *PackageFacts {
+	facts := extractFacts(fset, pkg, files, info, prog, newOriginClassifier(nil))
+	if len(files) > 0 {
+		facts.Dir = filepath.Dir(fset.Position(files[0].Pos()).Filename)
+	}
+	return facts
+}
+
+// extractFacts は型検査済みのパッケージからNamed型・関数・コンストラクタの情報を抽出する
+func extractFacts(fset *token.FileSet, pkg *types.Package, files []*ast.File, info *types.Info, prog *ssa.Program, classifier *originClassifier) *PackageFacts {
+	facts := &PackageFacts{PackagePath: pkg.Path(), Name: pkg.Name()}
+
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		switch obj := scope.Lookup(name).(type) {
+		case *types.TypeName:
+			if typeFacts, ok := extractTypeFacts(fset, obj, classifier); ok {
+				facts.Types = append(facts.Types, typeFacts)
+			}
+		case *types.Func:
+			facts.Functions = append(facts.Functions, FunctionFacts{
+				FunctionInfo: newFunctionInfo(fset, obj, pkg.Path(), classifier),
+				Returns:      returnedTypes(obj),
+			})
+		}
+	}
+
+	if prog != nil {
+		facts.Constructors = extractConstructors(prog, fset, files, info)
+	}
+
+	return facts
+}
+
+// extractTypeFacts はNamed型の情報を抽出する（エイリアスやNamed型でないものは対象外）
+func extractTypeFacts(fset *token.FileSet, obj *types.TypeName, classifier *originClassifier) (TypeFacts, bool) {
+	if obj.IsAlias() {
+		return TypeFacts{}, false
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return TypeFacts{}, false
+	}
+
+	typeFacts := TypeFacts{Name: obj.Name(), IsGeneric: named.TypeParams().Len() > 0, Position: fset.Position(obj.Pos())}
+	switch under := types.Unalias(named.Underlying()).(type) {
+	case *types.Struct:
+		typeFacts.IsStruct = true
+		typeFacts.Fields = extractFields(fset, under)
+		for i := range typeFacts.Fields {
+			typeFacts.Fields[i].Origin = classifier.classify(typeFacts.Fields[i].PackagePath)
+		}
+	case *types.Interface:
+		typeFacts.IsInterface = true
+		for i := 0; i < under.NumMethods(); i++ {
+			typeFacts.Methods = append(typeFacts.Methods, methodKey(under.Method(i)))
+		}
+		sort.Strings(typeFacts.Methods)
+		return typeFacts, true
+	}
+
+	// T と *T のどちらかで実装していればよいため、*T のメソッドセットを記録する
+	methodSet := types.NewMethodSet(types.NewPointer(named))
+	for i := 0; i < methodSet.Len(); i++ {
+		if fn, ok := methodSet.At(i).Obj().(*types.Func); ok {
+			typeFacts.Methods = append(typeFacts.Methods, methodKey(fn))
+		}
+	}
+	sort.Strings(typeFacts.Methods)
+
+	return typeFacts, true
+}
+
+// methodKey はメソッドを照合するための"名前 シグネチャ"の文字列を返す（非公開のメソッドはパッケージパスで区別する）
+// 名前で候補を絞り込み、シグネチャの表記が一致しなければ型情報で確認する
+// （any と interface{} やエイリアスのように、同じ型でも表記が異なる場合がある）
+func methodKey(fn *types.Func) string {
+	return fn.Id() + " " + types.TypeString(fn.Type(), nil)
+}
+
+// methodName はmethodKeyの形式の文字列からメソッドの名前を取り出す
+func methodName(key string) string {
+	name, _, _ := strings.Cut(key, " ")
+	return name
+}
+
+// returnedTypes は関数の返り値のNamed型を集める（複数の値を返す関数は提供関数にならないため集めない）
+func returnedTypes(fn *types.Func) []ReturnedType {
+	sig := fn.Type().(*types.Signature)
+	if !hasSingleResult(sig) {
+		return nil
+	}
+	var returned []ReturnedType
+	results := sig.Results()
+	for i := 0; i < results.Len(); i++ {
+		named, ok := types.Unalias(derefType(results.At(i).Type())).(*types.Named)
+		if !ok || named.Obj().Pkg() == nil {
+			continue
+		}
+		_, isStruct := named.Underlying().(*types.Struct)
+		returned = append(returned, ReturnedType{
+			Name:        named.Obj().Name(),
+			PackagePath: named.Obj().Pkg().Path(),
+			IsStruct:    isStruct,
+		})
+	}
+	return returned
+}
+
+// extractConstructors はインターフェースを返す関数ごとに、return文から辿った実装型を集める
+func extractConstructors(prog *ssa.Program, fset *token.FileSet, files []*ast.File, info *types.Info) []ConstructorFact {
+	var constructors []ConstructorFact
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			fnObj, ok := info.Defs[funcDecl.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			// 関数本体を含めてSSAを構築していないパッケージは追跡できない
+			fn := prog.FuncValue(fnObj)
+			if fn == nil {
+				continue
+			}
+
+			results := fnObj.Type().(*types.Signature).Results()
+			for i := 0; i < results.Len(); i++ {
+				named, ok := types.Unalias(derefType(results.At(i).Type())).(*types.Named)
+				if !ok || named.Obj().Pkg() == nil || !types.IsInterface(named) {
+					continue
+				}
+				for _, impl := range findImplementingTypes(fn, i) {
+					constructors = append(constructors, ConstructorFact{
+						FunctionName:        funcDecl.Name.Name,
+						InterfaceName:       named.Obj().Name(),
+						InterfacePkgPath:    named.Obj().Pkg().Path(),
+						ImplementingType:    getTypeName(impl.typ),
+						ImplementingPkgPath: getPackagePath(impl.typ),
+						Position:            fset.Position(funcDecl.Name.Pos()),
+						ReturnPosition:      fset.Position(impl.returnPos),
+					})
+				}
+			}
+		}
+	}
+	return constructors
+}
+
+// lookupType はパッケージのNamed型の情報を探す
+func (f *PackageFacts) lookupType(name string) (TypeFacts, bool) {
+	i := sort.Search(len(f.Types), func(i int) bool { return f.Types[i].Name >= name })
+	if i < len(f.Types) && f.Types[i].Name == name {
+		return f.Types[i], true
+	}
+	return TypeFacts{}, false
+}
+
+// StructFields はExtractStructFieldsと同様に、抽出済みの情報から構造体のフィールドを返す
+func (f *PackageFacts) StructFields(structName string) (*StructFieldsInfo, error) {
+	if f.HasErrors {
+		return nil, fmt.Errorf("package %s has errors", f.PackagePath)
+	}
+	typeFacts, ok := f.lookupType(structName)
+	if !ok {
+		return nil, fmt.Errorf("struct %s not found in package %s", structName, f.PackagePath)
+	}
+	if !typeFacts.IsStruct {
+		return nil, fmt.Errorf("%s is not a struct type", structName)
+	}
+	return &StructFieldsInfo{StructName: structName, PackageName: f.Name, Fields: typeFacts.Fields, Position: typeFacts.Position}, nil
+}
+
+// LookupTypeFacts はパッケージ群の抽出済みの情報からNamed型を探す
+func LookupTypeFacts(facts []*PackageFacts, typeName, pkgPath string) (TypeFacts, bool) {
+	for _, f := range facts {
+		if f.PackagePath == pkgPath {
+			return f.lookupType(typeName)
+		}
+	}
+	return TypeFacts{}, false
+}
+
+// FactsFunctionsReturningStruct はFindFunctionsReturningStructと同様に、抽出済みの情報から構造体を返す関数を探す
+func FactsFunctionsReturningStruct(structName, structPkgPath string, facts []*PackageFacts) []FunctionInfo {
+	return factsFunctionsReturning(facts, func(returned ReturnedType) bool {
+		return returned.IsStruct && returned.Name == structName && returned.PackagePath == structPkgPath
+	})
+}
+
+// FactsFunctionsReturningType はFindFunctionsReturningTypeと同様に、抽出済みの情報からNamed型を返す関数を探す
+func FactsFunctionsReturningType(typeName, typePkgPath string, facts []*PackageFacts) []FunctionInfo {
+	return factsFunctionsReturning(facts, func(returned ReturnedType) bool {
+		return returned.Name == typeName && returned.PackagePath == typePkgPath
+	})
+}
+
+// factsFunctionsReturning は返り値のいずれかが条件を満たす関数を集める
+func factsFunctionsReturning(facts []*PackageFacts, match func(ReturnedType) bool) []FunctionInfo {
+	var functions []FunctionInfo
+	for _, f := range facts {
+		for _, fn := range f.Functions {
+			for _, returned := range fn.Returns {
+				if match(returned) {
+					functions = append(functions, fn.FunctionInfo)
+					break // 同じ関数を複数回追加しないように
+				}
+			}
+		}
+	}
+	return functions
+}
+
+// ImplementsFunc はメソッドの名前は揃っているがシグネチャの表記が異なる実装型の候補を、
+// 読み込んだ型情報のtypes.Implementsで確認する（型情報を読み込めない場合はfalseを返す）
+type ImplementsFunc func(ref InterfaceReference) bool
+
+// FactsInterfaceReferences はFindInterfaceReferencesと同様に、抽出済みの情報からインターフェースの実装型を探す
+// メソッドセット戦略はメソッドの名前で候補を絞り込み、シグネチャの表記が全て一致する候補はそのまま実装型とする
+// 表記が異なる候補はimplementsで確認する（nilの場合は実装型としない）
+// インターフェースの定義パッケージがfactsに含まれていない場合はokがfalseになる
+func FactsInterfaceReferences(interfaceName, interfacePkgPath string, facts []*PackageFacts, implements ImplementsFunc) (refs []InterfaceReference, ok bool) {
+	iface, found := LookupTypeFacts(facts, interfaceName, interfacePkgPath)
+	if !found || !iface.IsInterface {
+		return nil, false
+	}
+
+	// コンストラクタ戦略
+	var references []InterfaceReference
+	for _, f := range facts {
+		for _, constructor := range f.Constructors {
+			if constructor.InterfaceName != interfaceName || constructor.InterfacePkgPath != interfacePkgPath {
+				continue
+			}
+			references = append(references, InterfaceReference{
+				FunctionName:        constructor.FunctionName,
+				PackagePath:         f.PackagePath,
+				ImplementingType:    constructor.ImplementingType,
+				ImplementingPkgPath: constructor.ImplementingPkgPath,
+				FoundBy:             StrategyConstructor,
+				Position:            constructor.Position,
+				ReturnPosition:      constructor.ReturnPosition,
+			})
+		}
+	}
+
+	// メソッドセット戦略
+	var implRefs []InterfaceReference
+	for _, f := range facts {
+		if f.HasErrors {
+			continue
+		}
+		for _, typeFacts := range f.Types {
+			if typeFacts.IsInterface || typeFacts.IsGeneric {
+				continue
+			}
+			ref := InterfaceReference{
+				ImplementingType:    typeFacts.Name,
+				ImplementingPkgPath: f.PackagePath,
+				FoundBy:             StrategyMethodSet,
+				Position:            typeFacts.Position,
+			}
+			switch matchMethods(typeFacts.Methods, iface.Methods) {
+			case methodsMissing:
+				continue
+			case methodsDiffer:
+				if implements == nil || !implements(ref) {
+					continue
+				}
+			}
+			implRefs = append(implRefs, ref)
+		}
+	}
+
+	return mergeReferences(references, implRefs), true
+}
+
+// methodMatch はメソッドセットとインターフェースのメソッドの照合結果
+type methodMatch int
+
+const (
+	methodsMissing   methodMatch = iota // 名前が足りない
+	methodsDiffer                       // 名前は揃っているがシグネチャの表記が異なるものがある
+	methodsIdentical                    // 名前とシグネチャの表記が全て一致する
+)
+
+// matchMethods はメソッドセットがインターフェースのメソッドを全て含むかを名前とシグネチャの表記で照合する（どちらもmethodKeyの形式で整列済み）
+func matchMethods(methodSet, required []string) methodMatch {
+	match := methodsIdentical
+	i := 0
+	for _, method := range required {
+		name := methodName(method)
+		for i < len(methodSet) && methodName(methodSet[i]) < name {
+			i++
+		}
+		if i == len(methodSet) || methodName(methodSet[i]) != name {
+			return methodsMissing
+		}
+		if methodSet[i] != method {
+			match = methodsDiffer
+		}
+	}
+	return match
+}
@@ -0,0 +1,169 @@
+package packages
+
+import (
+	"go/types"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// loadFacts はワークディレクトリのパッケージを読み込み、抽出した情報を返す
+// ExtractStructFieldsと同じ宣言を見るようwireinjectタグを付ける
+func loadFacts(t *testing.T, workDir string) ([]*PackageFacts, []*packages.Package) {
+	t.Helper()
+	cfg := &packages.Config{
+		Mode:       packages.LoadAllSyntax | packages.NeedModule,
+		Dir:        workDir,
+		BuildFlags: BuildFlags(),
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("failed to load packages: %v", err)
+	}
+	prog, _ := ssautil.Packages(pkgs, 0)
+	prog.Build()
+
+	var facts []*PackageFacts
+	for _, pkg := range pkgs {
+		facts = append(facts, ExtractPackageFacts(pkg, prog, pkgs))
+	}
+	return facts, pkgs
+}
+
+func TestExtractPackageFacts(t *testing.T) {
+	workDir := "../../sample/basic"
+	facts, pkgs := loadFacts(t, workDir)
+
+	var structs, interfaces int
+	for _, f := range facts {
+		if f.HasErrors {
+			t.Errorf("package %s has errors", f.PackagePath)
+		}
+		for _, typeFacts := range f.Types {
+			switch {
+			case typeFacts.IsStruct:
+				structs++
+
+				want, err := ExtractStructFields(workDir, f.PackagePath, typeFacts.Name)
+				if err != nil {
+					t.Fatalf("ExtractStructFields(%s.%s) error = %v", f.PackagePath, typeFacts.Name, err)
+				}
+				got, err := f.StructFields(typeFacts.Name)
+				if err != nil {
+					t.Fatalf("StructFields(%s.%s) error = %v", f.PackagePath, typeFacts.Name, err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("StructFields(%s.%s) = %+v, want %+v", f.PackagePath, typeFacts.Name, got, want)
+				}
+
+				wantFuncs := FindFunctionsReturningStruct(typeFacts.Name, f.PackagePath, pkgs)
+				gotFuncs := FactsFunctionsReturningStruct(typeFacts.Name, f.PackagePath, facts)
+				if !reflect.DeepEqual(gotFuncs, wantFuncs) {
+					t.Errorf("FactsFunctionsReturningStruct(%s.%s) = %+v, want %+v", f.PackagePath, typeFacts.Name, gotFuncs, wantFuncs)
+				}
+			case typeFacts.IsInterface:
+				interfaces++
+
+				want, err := FindInterfaceReferences(workDir, typeFacts.Name, f.PackagePath, "./...")
+				if err != nil {
+					t.Fatalf("FindInterfaceReferences(%s.%s) error = %v", f.PackagePath, typeFacts.Name, err)
+				}
+				got, ok := FactsInterfaceReferences(typeFacts.Name, f.PackagePath, facts, nil)
+				if !ok {
+					t.Fatalf("FactsInterfaceReferences(%s.%s) did not find the interface", f.PackagePath, typeFacts.Name)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("FactsInterfaceReferences(%s.%s) = %+v, want %+v", f.PackagePath, typeFacts.Name, got, want)
+				}
+
+				wantFuncs := FindFunctionsReturningType(typeFacts.Name, f.PackagePath, pkgs)
+				gotFuncs := FactsFunctionsReturningType(typeFacts.Name, f.PackagePath, facts)
+				if !reflect.DeepEqual(gotFuncs, wantFuncs) {
+					t.Errorf("FactsFunctionsReturningType(%s.%s) = %+v, want %+v", f.PackagePath, typeFacts.Name, gotFuncs, wantFuncs)
+				}
+			}
+		}
+	}
+	if structs == 0 || interfaces == 0 {
+		t.Fatalf("structs = %d, interfaces = %d, want both non-zero", structs, interfaces)
+	}
+
+	// 検索対象に定義がないインターフェースは判定できない
+	if _, ok := FactsInterfaceReferences("Stringer", "fmt", facts, nil); ok {
+		t.Errorf("FactsInterfaceReferences(fmt.Stringer) ok = true, want false")
+	}
+}
+
+func TestFactsInterfaceReferences_SignatureSpelling(t *testing.T) {
+	// interface{}で宣言したメソッドはanyのメソッドと表記が異なるため、名前で絞り込んだ候補を型情報で確認する
+	workDir := "../../testdata/anyparam"
+	facts, pkgs := loadFacts(t, workDir)
+
+	want := FindImplementingTypes("Encoder", "example.com/anyparam/codec", pkgs)
+	if len(want) != 1 || want[0].ImplementingType != "JSONEncoder" {
+		t.Fatalf("FindImplementingTypes = %+v, want JSONEncoder", want)
+	}
+
+	// 確認できなければ実装型としない
+	if got, _ := FactsInterfaceReferences("Encoder", "example.com/anyparam/codec", facts, nil); len(got) != 0 {
+		t.Errorf("FactsInterfaceReferences without implements = %+v, want none", got)
+	}
+
+	var confirmed []string
+	implements := func(ref InterfaceReference) bool {
+		confirmed = append(confirmed, ref.ImplementingPkgPath+"."+ref.ImplementingType)
+		iface := lookupInterface(pkgs, "Encoder", "example.com/anyparam/codec")
+		for _, typeName := range ImplementingTypes(iface, []*types.Package{findPackage(pkgs, ref.ImplementingPkgPath)}) {
+			if typeName.Name() == ref.ImplementingType {
+				return true
+			}
+		}
+		return false
+	}
+	got, ok := FactsInterfaceReferences("Encoder", "example.com/anyparam/codec", facts, implements)
+	if !ok {
+		t.Fatal("FactsInterfaceReferences did not find the interface")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FactsInterfaceReferences = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(confirmed, []string{"example.com/anyparam/jsoncodec.JSONEncoder"}) {
+		t.Errorf("confirmed = %v, want only the candidate whose signature is spelled differently", confirmed)
+	}
+}
+
+// findPackage は読み込んだパッケージから型情報を探す
+func findPackage(pkgs []*packages.Package, pkgPath string) *types.Package {
+	for _, pkg := range pkgs {
+		if pkg.PkgPath == pkgPath {
+			return pkg.Types
+		}
+	}
+	return nil
+}
+
+func TestMatchMethods(t *testing.T) {
+	tests := []struct {
+		name      string
+		methodSet []string
+		required  []string
+		want      methodMatch
+	}{
+		{name: "全て一致する", methodSet: []string{"A func()", "B func()", "C func()"}, required: []string{"A func()", "C func()"}, want: methodsIdentical},
+		{name: "要求が空", methodSet: []string{"A func()"}, required: nil, want: methodsIdentical},
+		{name: "一部が足りない", methodSet: []string{"A func()", "C func()"}, required: []string{"A func()", "B func()"}, want: methodsMissing},
+		{name: "メソッドセットが空", methodSet: nil, required: []string{"A func()"}, want: methodsMissing},
+		{name: "名前が前方一致するだけ", methodSet: []string{"AB func()"}, required: []string{"A func()"}, want: methodsMissing},
+		{name: "シグネチャの表記が異なる", methodSet: []string{"A func(v interface{})", "B func()"}, required: []string{"A func(v any)"}, want: methodsDiffer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchMethods(tt.methodSet, tt.required); got != tt.want {
+				t.Errorf("matchMethods() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package packages
+
+import (
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// findImplementingTypes はSSAのデータフローを追跡し、関数のindex番目の返り値として返りうる具象型を全て探す
+// ローカル変数経由のreturn、ヘルパー関数やクロージャの呼び出し経由のreturnも追跡する
+// 関数内に定義された関数リテラルのreturn文は、呼び出されない限り対象外
+func findImplementingTypes(fn *ssa.Function, index int) []implementation {
+	tracer := &returnTracer{
+		root:          fn,
+		visitedFuncs:  make(map[returnKey]bool),
+		visitedValues: make(map[ssa.Value]bool),
+	}
+	tracer.traceReturns(fn, index)
+	return tracer.found
+}
+
+// implementation は返り値として見つかった具象型と、それを返すreturn文
+type implementation struct {
+	typ       types.Type
+	returnPos token.Pos // 追跡を始めた関数のreturn文の位置（暗黙のreturnの場合はtoken.NoPos）
+}
+
+// returnKey は追跡済みの関数と返り値の位置の組
+type returnKey struct {
+	fn    *ssa.Function
+	index int
+}
+
+// returnTracer は返り値の具象型を追跡する
+type returnTracer struct {
+	root          *ssa.Function      // 追跡を始めた関数
+	returnPos     token.Pos          // 追跡中のrootのreturn文の位置
+	visitedFuncs  map[returnKey]bool // 追跡済みの関数（再帰呼び出しによる無限ループ防止）
+	visitedValues map[ssa.Value]bool // 追跡済みの値（Phiの循環防止）
+	found         []implementation   // 見つかった具象型（発見順）
+}
+
+// traceReturns は関数の全てのreturn命令を追跡する
+func (t *returnTracer) traceReturns(fn *ssa.Function, index int) {
+	if fn == nil {
+		return
+	}
+
+	key := returnKey{fn: fn, index: index}
+	if t.visitedFuncs[key] {
+		return
+	}
+	t.visitedFuncs[key] = true
+
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			ret, ok := instr.(*ssa.Return)
+			if !ok || index >= len(ret.Results) {
+				continue
+			}
+			// ヘルパー関数の中まで辿っても、報告するのはrootのreturn文
+			if fn == t.root {
+				t.returnPos = ret.Pos()
+			}
+			t.traceValue(ret.Results[index])
+		}
+	}
+}
+
+// traceValue は値の出どころを遡って具象型を探す
+func (t *returnTracer) traceValue(v ssa.Value) {
+	if t.visitedValues[v] {
+		return
+	}
+	t.visitedValues[v] = true
+
+	switch v := v.(type) {
+	case *ssa.MakeInterface:
+		// 具象型からインターフェースへの変換
+		t.addType(v.X.Type())
+	case *ssa.ChangeInterface:
+		t.traceValue(v.X)
+	case *ssa.Phi:
+		// 分岐の合流点は全ての経路を追跡
+		for _, edge := range v.Edges {
+			t.traceValue(edge)
+		}
+	case *ssa.Call:
+		// 単一の返り値を持つ関数呼び出し
+		t.traceReturns(v.Call.StaticCallee(), 0)
+	case *ssa.Extract:
+		// 複数の返り値を持つ関数呼び出しのうちの1つ
+		if call, ok := v.Tuple.(*ssa.Call); ok {
+			t.traceReturns(call.Call.StaticCallee(), v.Index)
+		}
+	}
+}
+
+// addType は具象型を重複なく記録する
+func (t *returnTracer) addType(typ types.Type) {
+	// ポインタの場合は元の型を取得
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok {
+		return
+	}
+
+	for _, existing := range t.found {
+		if types.Identical(existing.typ, named) {
+			return
+		}
+	}
+	t.found = append(t.found, implementation{typ: named, returnPos: t.returnPos})
+}
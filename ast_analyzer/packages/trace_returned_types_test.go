@@ -0,0 +1,111 @@
+package packages
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFindInterfaceReferences_IndirectReturns(t *testing.T) {
+	refs, err := FindInterfaceReferences(
+		"../../testdata/implementations",
+		"Store",
+		"example.com/implementations",
+		"./...",
+	)
+	if err != nil {
+		t.Fatalf("FindInterfaceReferences() error = %v", err)
+	}
+
+	// 関数名ごとに実装型をまとめる
+	got := make(map[string][]string)
+	for _, ref := range refs {
+		if ref.FunctionName == "" {
+			continue
+		}
+		got[ref.FunctionName] = append(got[ref.FunctionName], ref.ImplementingType)
+	}
+	for name := range got {
+		sort.Strings(got[name])
+	}
+
+	tests := []struct {
+		name          string
+		functionName  string
+		wantImplTypes []string
+	}{
+		{
+			name:          "ローカル変数経由のreturn",
+			functionName:  "NewStoreViaLocal",
+			wantImplTypes: []string{"memoryStore"},
+		},
+		{
+			name:          "ヘルパー関数経由のreturn（複数の実装を全て報告）",
+			functionName:  "NewStoreViaHelper",
+			wantImplTypes: []string{"fileStore", "memoryStore"},
+		},
+		{
+			name:          "呼び出されたクロージャ経由のreturn",
+			functionName:  "NewStoreViaClosure",
+			wantImplTypes: []string{"fileStore"},
+		},
+		{
+			name:          "呼び出されない関数リテラルのreturnは対象外",
+			functionName:  "NewStoreIgnoringClosure",
+			wantImplTypes: []string{"memoryStore"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(got[tt.functionName], tt.wantImplTypes) {
+				t.Errorf("%s の実装型が期待と異なります。got = %v, want = %v",
+					tt.functionName, got[tt.functionName], tt.wantImplTypes)
+			}
+		})
+	}
+}
+
+func TestFindInterfaceReferences_ReturnPosition(t *testing.T) {
+	refs, err := FindInterfaceReferences(
+		"../../testdata/implementations",
+		"Store",
+		"example.com/implementations",
+		"./...",
+	)
+	if err != nil {
+		t.Fatalf("FindInterfaceReferences() error = %v", err)
+	}
+
+	// ヘルパー関数やクロージャの中まで辿っても、位置は追跡を始めた関数のreturn文
+	tests := []struct {
+		functionName   string
+		implType       string
+		wantLine       int
+		wantReturnLine int
+	}{
+		{functionName: "NewStoreViaLocal", implType: "memoryStore", wantLine: 17, wantReturnLine: 19},
+		{functionName: "NewStoreViaHelper", implType: "fileStore", wantLine: 23, wantReturnLine: 24},
+		{functionName: "NewStoreViaHelper", implType: "memoryStore", wantLine: 23, wantReturnLine: 24},
+		{functionName: "NewStoreViaClosure", implType: "fileStore", wantLine: 38, wantReturnLine: 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.functionName+"/"+tt.implType, func(t *testing.T) {
+			for _, ref := range refs {
+				if ref.FunctionName != tt.functionName || ref.ImplementingType != tt.implType {
+					continue
+				}
+				if filepath.Base(ref.Position.Filename) != "store.go" || ref.Position.Line != tt.wantLine {
+					t.Errorf("Position = %s, want store.go:%d", ref.Position, tt.wantLine)
+				}
+				if filepath.Base(ref.ReturnPosition.Filename) != "store.go" || ref.ReturnPosition.Line != tt.wantReturnLine {
+					t.Errorf("ReturnPosition = %s, want store.go:%d", ref.ReturnPosition, tt.wantReturnLine)
+				}
+				return
+			}
+			t.Fatalf("no reference from %s to %s", tt.functionName, tt.implType)
+		})
+	}
+}
@@ -1,22 +1,104 @@
 package packages
 
+import "go/token"
+
+// FieldKind はフィールドの型の種類を表す（Named型の場合は基底型で判定する）
+type FieldKind int
+
+const (
+	FieldKindOther     FieldKind = iota // 基本型など、下記以外の型
+	FieldKindStruct                     // 構造体型
+	FieldKindInterface                  // インターフェース型
+	FieldKindSlice                      // スライス型
+	FieldKindArray                      // 配列型
+	FieldKindMap                        // マップ型
+	FieldKindFunc                       // 関数型
+	FieldKindChan                       // チャネル型
+)
+
+// TypeOrigin は型が定義されている場所の分類を表す
+type TypeOrigin int
+
+const (
+	OriginBasic       TypeOrigin = iota // パッケージを持たない型（基本型、error、名前のない複合型など）
+	OriginStdlib                        // 標準ライブラリ
+	OriginThirdParty                    // メインモジュール以外のモジュール
+	OriginModuleLocal                   // メインモジュール内
+)
+
+// String は分類の文字列表現を返す
+func (o TypeOrigin) String() string {
+	switch o {
+	case OriginBasic:
+		return "basic"
+	case OriginStdlib:
+		return "stdlib"
+	case OriginThirdParty:
+		return "third-party"
+	case OriginModuleLocal:
+		return "module-local"
+	}
+	return "unknown"
+}
+
 // FieldInfo は構造体のフィールド情報を保持する
 type FieldInfo struct {
-	Name        string // フィールド名
-	TypeName    string // 型名（例: "UserService", "UserRepository"）
-	PackagePath string // importに使ったパッケージパス（例: "github.com/rmocchy/convinient_wire/sample/basic/service"）
-	IsPointer   bool   // ポインタ型かどうか
-	IsInterface bool   // インターフェース型かどうか
+	Name        string         // フィールド名
+	TypeName    string         // 型名（例: "UserService", "UserRepository"）
+	PackagePath string         // importに使ったパッケージパス（例: "github.com/rmocchy/convinient_wire/sample/basic/service"）
+	PackageName string         // パッケージ名（例: "service"、importパスの末尾と異なる場合がある）
+	IsPointer   bool           // ポインタ型かどうか
+	IsInterface bool           // インターフェース型かどうか
+	Kind        FieldKind      // 型の種類
+	TypeString  string         // パッケージ名で修飾した型の文字列表現（例: "[]plugin.Plugin", "func() time.Time"）
+	ElemType    string         // スライス・配列・マップ・チャネルの要素型の文字列表現
+	KeyType     string         // マップのキー型の文字列表現
+	Origin      TypeOrigin     // 型が定義されている場所の分類
+	Position    token.Position // 構造体のフィールドの場合は宣言の位置（それ以外はゼロ値）
 }
 
 // StructFieldsInfo は構造体とそのフィールド情報を保持する
 type StructFieldsInfo struct {
-	StructName string      // 構造体名
-	Fields     []FieldInfo // フィールド情報のリスト
+	StructName  string         // 構造体名
+	PackageName string         // 構造体を宣言しているパッケージの名前
+	Fields      []FieldInfo    // フィールド情報のリスト
+	Position    token.Position // 構造体の型の宣言の位置
 }
 
 // FunctionInfo は関数情報を保持する
 type FunctionInfo struct {
-	Name        string // 関数名
-	PackagePath string // パッケージパス
+	Name         string         // 関数名
+	PackagePath  string         // パッケージパス
+	PackageName  string         // パッケージ名
+	Params       []FieldInfo    // 引数の型情報（Nameは引数名）
+	Result       FieldInfo      // 提供する型（error・クリーンアップ関数以外の最初の返り値）
+	ReturnsError bool           // 返り値にerrorを含むかどうか
+	HasCleanup   bool           // 返り値にクリーンアップ関数（func()）を含むかどうか
+	Position     token.Position // 関数の宣言の位置
+}
+
+// DiscoveryStrategy は実装型を見つけた戦略を表す（ビットフラグ）
+type DiscoveryStrategy int
+
+const (
+	StrategyConstructor DiscoveryStrategy = 1 << iota // コンストラクタのreturn文から発見
+	StrategyMethodSet                                 // メソッドセット（types.Implements）から発見
+)
+
+// Has は指定された戦略が含まれているかどうかを判定する
+func (s DiscoveryStrategy) Has(strategy DiscoveryStrategy) bool {
+	return s&strategy != 0
+}
+
+// String は戦略の文字列表現を返す
+func (s DiscoveryStrategy) String() string {
+	switch {
+	case s.Has(StrategyConstructor) && s.Has(StrategyMethodSet):
+		return "constructor+method-set"
+	case s.Has(StrategyConstructor):
+		return "constructor"
+	case s.Has(StrategyMethodSet):
+		return "method-set"
+	}
+	return "unknown"
 }
@@ -2,6 +2,12 @@ package packages
 
 import "go/types"
 
+// BuildFlags はパッケージを読み込む際のビルドフラグを返す
+// wire.goに定義された注入関数や構造体も対象にするためwireinjectタグを付ける（解析、キャッシュのキー、監視で共通）
+func BuildFlags() []string {
+	return []string{"-tags=wireinject"}
+}
+
 // derefType はポインタ型を再帰的に剥がす
 func derefType(t types.Type) types.Type {
 	for {
@@ -38,3 +44,10 @@ func getPackagePath(t types.Type) string {
 
 	return ""
 }
+
+// typeString は型をパッケージ名で修飾した文字列表現に変換する（例: "[]plugin.Plugin"）
+func typeString(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		return pkg.Name()
+	})
+}
@@ -0,0 +1,275 @@
+package wirecheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"reflect"
+)
+
+// buildSet は注入関数のwire.Buildに渡されたもの（入れ子のwire.NewSetを含む）を型のキーで引けるようにしたもの
+// 他のパッケージのプロバイダセットはpackageFactとして受け渡すため、型や関数はキー（importパスと名前）で持つ
+type buildSet struct {
+	Providers map[string]string   // 型のキー -> 提供関数のキー
+	Binds     map[string]string   // インターフェースのキー -> wire.Bindで結びつけた実装型のキー
+	Structs   map[string][]string // wire.Structで組み立てる型のキー -> フィールド名（"*"はすべて）
+	Provided  map[string]bool     // wire.Value、wire.InterfaceValue、wire.FieldsOf、注入関数の引数で渡される型
+
+	visited map[string]bool // 展開済みのプロバイダセット変数
+}
+
+// newBuildSet は空のbuildSetを作成する
+func newBuildSet() *buildSet {
+	return &buildSet{
+		Providers: make(map[string]string),
+		Binds:     make(map[string]string),
+		Structs:   make(map[string][]string),
+		Provided:  make(map[string]bool),
+		visited:   make(map[string]bool),
+	}
+}
+
+// merge は他のパッケージのプロバイダセットに渡されたものを加える
+func (s *buildSet) merge(other *buildSet) {
+	for key, fn := range other.Providers {
+		s.Providers[key] = fn
+	}
+	for key, impl := range other.Binds {
+		s.Binds[key] = impl
+	}
+	for key, fields := range other.Structs {
+		s.Structs[key] = fields
+	}
+	for key := range other.Provided {
+		s.Provided[key] = true
+	}
+}
+
+// collectBuildSet は注入関数の引数とwire.Buildの引数を集める
+func (c *checker) collectBuildSet(inj *injector, sig *types.Signature) *buildSet {
+	set := newBuildSet()
+	for i := 0; i < sig.Params().Len(); i++ {
+		if named, ok := namedType(sig.Params().At(i).Type()); ok {
+			set.Provided[typeKey(named)] = true
+		}
+	}
+	for _, arg := range inj.build.Args {
+		c.addEntry(set, c.pass.TypesInfo, arg)
+	}
+	return set
+}
+
+// addEntry はwire.Buildやwire.NewSetの引数を1つ登録する
+func (c *checker) addEntry(set *buildSet, info *types.Info, expr ast.Expr) {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.CallExpr:
+		c.addWireCall(set, info, e)
+
+	case *ast.Ident, *ast.SelectorExpr:
+		ident, ok := e.(*ast.Ident)
+		if !ok {
+			ident = e.(*ast.SelectorExpr).Sel
+		}
+		switch obj := info.Uses[ident].(type) {
+		case *types.Func:
+			sig := obj.Type().(*types.Signature)
+			if sig.Results().Len() == 0 {
+				return
+			}
+			if named, ok := namedType(sig.Results().At(0).Type()); ok {
+				set.Providers[typeKey(named)] = objectKey(obj)
+			}
+		case *types.Var:
+			// プロバイダセットの変数は初期化式を展開する（他のパッケージの変数は依存パッケージの解析で展開済み）
+			key := objectKey(obj)
+			if set.visited[key] {
+				return
+			}
+			set.visited[key] = true
+			if obj.Pkg() != c.pass.Pkg {
+				if imported := c.importedSet(obj); imported != nil {
+					set.merge(imported)
+				}
+				return
+			}
+			if init := findInitializer(c.pass.Files, obj.Name()); init != nil {
+				c.addEntry(set, c.pass.TypesInfo, init)
+			}
+		}
+	}
+}
+
+// isWireCall はwireパッケージの指定した関数の呼び出しかどうかを判定する
+func (c *checker) isWireCall(info *types.Info, call *ast.CallExpr, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	fn, ok := info.Uses[sel.Sel].(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == wireImportPath && fn.Name() == name
+}
+
+// addWireCall はwire.NewSet、wire.Bind、wire.Struct、wire.FieldsOf、wire.Value、wire.InterfaceValueを登録する
+func (c *checker) addWireCall(set *buildSet, info *types.Info, call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	fn, ok := info.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != wireImportPath {
+		return
+	}
+
+	switch fn.Name() {
+	case "NewSet":
+		for _, arg := range call.Args {
+			c.addEntry(set, info, arg)
+		}
+
+	case "Bind":
+		if len(call.Args) != 2 {
+			return
+		}
+		iface, ok := newArgType(info, call.Args[0])
+		impl, implOK := newArgType(info, call.Args[1])
+		if ok && implOK {
+			set.Binds[typeKey(iface)] = typeKey(impl)
+		}
+
+	case "Struct":
+		if len(call.Args) == 0 {
+			return
+		}
+		if named, ok := newArgType(info, call.Args[0]); ok {
+			set.Structs[typeKey(named)] = stringArgs(info, call.Args[1:])
+		}
+
+	case "FieldsOf":
+		if len(call.Args) == 0 {
+			return
+		}
+		named, ok := newArgType(info, call.Args[0])
+		if !ok {
+			return
+		}
+		st, ok := named.Underlying().(*types.Struct)
+		if !ok {
+			return
+		}
+		for _, name := range stringArgs(info, call.Args[1:]) {
+			for i := 0; i < st.NumFields(); i++ {
+				if st.Field(i).Name() != name {
+					continue
+				}
+				if field, ok := namedType(st.Field(i).Type()); ok {
+					set.Provided[typeKey(field)] = true
+				}
+			}
+		}
+
+	case "Value":
+		if len(call.Args) == 1 {
+			if named, ok := namedType(info.TypeOf(call.Args[0])); ok {
+				set.Provided[typeKey(named)] = true
+			}
+		}
+
+	case "InterfaceValue":
+		if len(call.Args) == 2 {
+			if named, ok := newArgType(info, call.Args[0]); ok {
+				set.Provided[typeKey(named)] = true
+			}
+		}
+	}
+}
+
+// findInitializer はファイルからパッケージレベルの変数の初期化式を探す
+func findInitializer(files []*ast.File, name string) ast.Expr {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				value, ok := spec.(*ast.ValueSpec)
+				if !ok || len(value.Values) != len(value.Names) {
+					continue
+				}
+				for i, ident := range value.Names {
+					if ident.Name == name {
+						return value.Values[i]
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fromBuild はwire.Buildに渡されたもので型を提供できる場合に、その依存関係を辿る
+func (w *walker) fromBuild(named *types.Named, path string) bool {
+	key := typeKey(named)
+	if w.build.Provided[key] {
+		return true
+	}
+	if fnKey, ok := w.build.Providers[key]; ok {
+		// 解析中のパッケージから見えない提供関数は引数を辿れない
+		if fn, ok := w.lookupKey(fnKey).(*types.Func); ok {
+			w.walkProvider(fn, path)
+		}
+		return true
+	}
+	if implKey, ok := w.build.Binds[key]; ok {
+		if impl, ok := w.lookupKey(implKey).(*types.TypeName); ok {
+			w.walk(impl.Type(), path)
+		}
+		return true
+	}
+	if fields, ok := w.build.Structs[key]; ok {
+		if st, ok := named.Underlying().(*types.Struct); ok {
+			w.walkFields(st, fields, path)
+		}
+		return true
+	}
+	return false
+}
+
+// structIncludes はwire.Structで指定されたフィールド名にフィールドが含まれるかを判定する
+func structIncludes(fields []string, name, tag string) bool {
+	for _, field := range fields {
+		if field == name || (field == "*" && reflect.StructTag(tag).Get("wire") != "-") {
+			return true
+		}
+	}
+	return false
+}
+
+// stringArgs は文字列定数の引数を返す
+func stringArgs(info *types.Info, args []ast.Expr) []string {
+	var values []string
+	for _, arg := range args {
+		if tv, ok := info.Types[arg]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+			values = append(values, constant.StringVal(tv.Value))
+		}
+	}
+	return values
+}
+
+// newArgType はnew(T)やnew(*T)の引数の名前付き型Tを返す
+func newArgType(info *types.Info, expr ast.Expr) (*types.Named, bool) {
+	ptr, ok := types.Unalias(info.TypeOf(expr)).(*types.Pointer)
+	if !ok {
+		return nil, false
+	}
+	return namedType(ptr.Elem())
+}
+
+// namedType はポインタを外した名前付き型を返す
+func namedType(t types.Type) (*types.Named, bool) {
+	if t == nil {
+		return nil, false
+	}
+	named, ok := types.Unalias(derefType(t)).(*types.Named)
+	return named, ok
+}
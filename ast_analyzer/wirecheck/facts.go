@@ -0,0 +1,231 @@
+package wirecheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+)
+
+// packageFact はモジュール内の依存パッケージの解析で書き出し、注入関数の検査で読み込む情報
+// go vet -vettoolでは依存パッケージがエクスポートデータから読み込まれ、非公開の型やプロバイダセットの初期化式が含まれないため、
+// 依存パッケージを解析する時に型情報と構文木から抽出しておく
+// パッケージのFactは直接importしたパッケージのものしか読み込めないため、モジュール内の間接的な依存パッケージの情報も含める
+type packageFact struct {
+	Facts []*packages.PackageFacts // このパッケージとモジュール内の依存パッケージの情報（パス順、CLIの解析結果のキャッシュと同じ形式）
+	Sets  map[string]*buildSet     // プロバイダセットの変数名 -> wire.NewSetに渡されたもの（入れ子のセットは展開済み）
+}
+
+func (*packageFact) AFact() {}
+
+func (f *packageFact) String() string {
+	return fmt.Sprintf("wirecheck(%d packages, %d sets)", len(f.Facts), len(f.Sets))
+}
+
+// extractFact は解析中のパッケージから依存関係の検査に使う情報を抽出する
+// 注入関数は生成されるwire_gen.goの関数と別物なので、提供関数として扱わないよう除く
+func extractFact(pass *analysis.Pass) *packageFact {
+	facts := packages.ExtractFacts(pass.Fset, pass.Pkg, pass.Files, pass.TypesInfo, buildSSA(pass))
+
+	injectors := make(map[string]bool)
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Body != nil && findWireBuild(pass, funcDecl) != nil {
+				injectors[funcDecl.Name.Name] = true
+			}
+		}
+	}
+	functions := facts.Functions[:0]
+	for _, fn := range facts.Functions {
+		if !injectors[fn.Name] {
+			functions = append(functions, fn)
+		}
+	}
+	facts.Functions = functions
+
+	fact := &packageFact{Facts: dependencyFacts(pass, facts), Sets: make(map[string]*buildSet)}
+	c := &checker{pass: pass}
+	for _, obj := range packageVars(pass.Pkg) {
+		init := findInitializer(pass.Files, obj.Name())
+		if call, ok := ast.Unparen(init).(*ast.CallExpr); !ok || !c.isWireCall(pass.TypesInfo, call, "NewSet") {
+			continue
+		}
+		set := newBuildSet()
+		c.addEntry(set, pass.TypesInfo, init)
+		fact.Sets[obj.Name()] = set
+	}
+	return fact
+}
+
+// packageVars はパッケージレベルの変数を名前順に返す
+func packageVars(pkg *types.Package) []*types.Var {
+	var vars []*types.Var
+	for _, name := range pkg.Scope().Names() {
+		if v, ok := pkg.Scope().Lookup(name).(*types.Var); ok {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+// buildSSA は解析中のパッケージの関数本体のSSAを構築する（依存パッケージは型情報だけから作る）
+// コンストラクタ戦略でreturn文から実装型を辿るために使う
+func buildSSA(pass *analysis.Pass) *ssa.Program {
+	prog := ssa.NewProgram(pass.Fset, 0)
+	created := make(map[*types.Package]bool)
+	var create func(pkgs []*types.Package)
+	create = func(pkgs []*types.Package) {
+		for _, pkg := range pkgs {
+			if created[pkg] {
+				continue
+			}
+			created[pkg] = true
+			prog.CreatePackage(pkg, nil, nil, true)
+			create(pkg.Imports())
+		}
+	}
+	create(pass.Pkg.Imports())
+	prog.CreatePackage(pass.Pkg, pass.Files, pass.TypesInfo, false).Build()
+	return prog
+}
+
+// dependencyFacts は解析中のパッケージの情報に、直接importしたパッケージのFactが含むモジュール内のパッケージの情報を加えてパス順に返す
+func dependencyFacts(pass *analysis.Pass, own *packages.PackageFacts) []*packages.PackageFacts {
+	seen := map[string]bool{own.PackagePath: true}
+	facts := []*packages.PackageFacts{own}
+	for _, pkgFact := range pass.AllPackageFacts() {
+		fact, ok := pkgFact.Fact.(*packageFact)
+		if !ok || pkgFact.Package == pass.Pkg {
+			continue
+		}
+		for _, f := range fact.Facts {
+			if !seen[f.PackagePath] && inModule(pass, f.PackagePath) {
+				seen[f.PackagePath] = true
+				facts = append(facts, f)
+			}
+		}
+	}
+	sort.Slice(facts, func(i, j int) bool { return facts[i].PackagePath < facts[j].PackagePath })
+	return facts
+}
+
+// moduleFacts は提供関数と実装型を探すパッケージ（解析中のパッケージとモジュール内の依存パッケージ）の情報を返す
+func (c *checker) moduleFacts() []*packages.PackageFacts {
+	return c.own.Facts
+}
+
+// importedSet は他のパッケージのプロバイダセットの変数に渡されたものを返す（見つからなければnil）
+func (c *checker) importedSet(v *types.Var) *buildSet {
+	var fact packageFact
+	if !c.pass.ImportPackageFact(v.Pkg(), &fact) {
+		return nil
+	}
+	return fact.Sets[v.Name()]
+}
+
+// lookupPackage は解析中のパッケージから見えるパッケージをパスで探す
+func (c *checker) lookupPackage(pkgPath string) *types.Package {
+	if c.pass.Pkg.Path() == pkgPath {
+		return c.pass.Pkg
+	}
+	for _, pkg := range c.search {
+		if pkg.Path() == pkgPath {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// lookupObject は"importパス.名前"のキーのパッケージレベルの宣言を探す（解析中のパッケージから見えなければnil）
+func (c *checker) lookupObject(pkgPath, name string) types.Object {
+	pkg := c.lookupPackage(pkgPath)
+	if pkg == nil {
+		return nil
+	}
+	return pkg.Scope().Lookup(name)
+}
+
+// lookupKey はobjectKeyのキーの宣言を探す
+func (c *checker) lookupKey(key string) types.Object {
+	i := strings.LastIndex(key, ".")
+	if i < 0 {
+		return nil
+	}
+	return c.lookupObject(key[:i], key[i+1:])
+}
+
+// objectName はパッケージ名で修飾した宣言の名前を返す（解析中のパッケージの宣言は修飾しない）
+func (c *checker) objectName(pkgPath, name string) string {
+	if pkgPath == c.pass.Pkg.Path() {
+		return name
+	}
+	return c.packageName(pkgPath) + "." + name
+}
+
+// packageName はパッケージ名を返す（情報がなければパスの末尾で代用する）
+func (c *checker) packageName(pkgPath string) string {
+	for _, facts := range c.moduleFacts() {
+		if facts.PackagePath == pkgPath {
+			return facts.Name
+		}
+	}
+	if pkg := c.lookupPackage(pkgPath); pkg != nil {
+		return pkg.Name()
+	}
+	return pkgPath
+}
+
+// implementation はインターフェースの実装型
+type implementation struct {
+	name     string          // 型名
+	pkgPath  string          // 型のパッケージパス
+	obj      *types.TypeName // 解析中のパッケージから見える型（見えなければnil）
+	position token.Position  // 型の宣言の位置
+}
+
+// implementations はCLIと同じ検索でインターフェースの実装型を探す
+func (w *walker) implementations(named *types.Named) []implementation {
+	// シグネチャの表記だけが異なる候補は、解析中のパッケージから見える型であればtypes.Implementsで確認する
+	iface := named.Underlying().(*types.Interface)
+	implements := func(ref packages.InterfaceReference) bool {
+		impl, ok := w.lookupObject(ref.ImplementingPkgPath, ref.ImplementingType).(*types.TypeName)
+		return ok && (types.Implements(impl.Type(), iface) || types.Implements(types.NewPointer(impl.Type()), iface))
+	}
+	refs, ok := packages.FactsInterfaceReferences(named.Obj().Name(), named.Obj().Pkg().Path(), w.moduleFacts(), implements)
+	if !ok {
+		return nil
+	}
+	impls := make([]implementation, 0, len(refs))
+	for _, ref := range refs {
+		impl := implementation{name: ref.ImplementingType, pkgPath: ref.ImplementingPkgPath}
+		impl.obj, _ = w.lookupObject(ref.ImplementingPkgPath, ref.ImplementingType).(*types.TypeName)
+		if typeFacts, ok := packages.LookupTypeFacts(w.moduleFacts(), ref.ImplementingType, ref.ImplementingPkgPath); ok {
+			impl.position = typeFacts.Position
+		}
+		impls = append(impls, impl)
+	}
+	return impls
+}
+
+// position は宣言の位置を解析中のFileSetの位置に変換する
+// 依存パッケージの宣言は、同じファイルの同じ行の先頭に置き換える（見つからなければNoPos）
+func (c *checker) position(position token.Position) token.Pos {
+	var pos token.Pos
+	c.pass.Fset.Iterate(func(file *token.File) bool {
+		if file.Name() != position.Filename {
+			return true
+		}
+		if position.Line <= file.LineCount() {
+			pos = file.LineStart(position.Line)
+		}
+		return false
+	})
+	return pos
+}
@@ -0,0 +1,487 @@
+// Package wirecheck はwire.goの注入関数の依存関係の問題を報告するgo/analysisのAnalyzerを提供する
+// go vet -vettool、singlechecker、multicheckerから実行できる
+// wire.Buildに渡した提供関数やwire.Bind、注入関数の引数で解決済みの型は報告しない
+// 注入関数のファイルは通常 wireinject タグ付きのため、go vet では -tags=wireinject、singlechecker では GOFLAGS=-tags=wireinject を付けて実行する
+package wirecheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+)
+
+// wireImportPath はwireパッケージのimportパス
+const wireImportPath = "github.com/google/wire"
+
+// 診断の分類
+const (
+	CategoryUnresolvedInterface      = "unresolved-interface"      // 実装型が見つからないインターフェース
+	CategoryAmbiguousImplementation  = "ambiguous-implementation"  // 実装型が複数あるインターフェース
+	CategoryUnexportedImplementation = "unexported-implementation" // wire.goから参照できない実装型
+	CategoryMissingProvider          = "missing-provider"          // 提供関数がない構造体
+	CategoryAmbiguousProvider        = "ambiguous-provider"        // 提供関数が複数ある型
+)
+
+// Analyzer はwire.Buildを含む注入関数の返り値から依存関係を辿り、wireで解決できない型を報告する
+var Analyzer = &analysis.Analyzer{
+	Name: "wirecheck",
+	Doc: `report wire dependency problems reachable from injectors
+
+For each function calling wire.Build, wirecheck follows the providers
+from the injector's result and reports interfaces without an
+implementation, interfaces with several implementations, unexported
+implementations, types with several providers and structs without a
+provider. Fixes add the missing wire.Bind or wire.Struct to the
+wire.Build call.`,
+	Run:       run,
+	FactTypes: []analysis.Fact{new(packageFact)},
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	// 標準ライブラリ以外のパッケージは、依存先として検査に使う情報をFactとして書き出す
+	if isStdlib(pass.Pkg.Path()) {
+		return nil, nil
+	}
+	fact := extractFact(pass)
+	pass.ExportPackageFact(fact)
+
+	var injectors []*injector
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Body == nil {
+				continue
+			}
+			if call := findWireBuild(pass, funcDecl); call != nil {
+				injectors = append(injectors, &injector{file: file, decl: funcDecl, build: call})
+			}
+		}
+	}
+	if len(injectors) == 0 {
+		return nil, nil
+	}
+
+	c := &checker{pass: pass, search: searchPackages(pass), own: fact}
+	for _, inj := range injectors {
+		c.checkInjector(inj)
+	}
+
+	return nil, nil
+}
+
+// injector はwire.Buildを呼び出す注入関数
+type injector struct {
+	file  *ast.File
+	decl  *ast.FuncDecl
+	build *ast.CallExpr
+}
+
+// checker は1つのパッケージの注入関数を検査する
+type checker struct {
+	pass   *analysis.Pass
+	search []*types.Package // 解析中のパッケージから見えるモジュール内のパッケージ
+	own    *packageFact     // 解析中のパッケージから抽出した情報
+}
+
+// checkInjector は注入関数の返り値から依存関係を辿る
+func (c *checker) checkInjector(inj *injector) {
+	sig := c.pass.TypesInfo.Defs[inj.decl.Name].(*types.Func).Type().(*types.Signature)
+	if sig.Results().Len() == 0 {
+		return
+	}
+
+	named, ok := namedType(sig.Results().At(0).Type())
+	if !ok {
+		return
+	}
+
+	w := &walker{checker: c, inj: inj, build: c.collectBuildSet(inj, sig), seen: make(map[string]bool)}
+	w.seen[typeKey(named)] = true
+	if w.fromBuild(named, named.Obj().Name()) {
+		return
+	}
+
+	// ルートは提供関数がなくてもwire.Structで組み立てられる
+	if w.fromProviders(named, sig.Results().At(0).Type(), named.Obj().Name()) {
+		return
+	}
+	if st, ok := named.Underlying().(*types.Struct); ok {
+		w.walkFields(st, nil, named.Obj().Name())
+	}
+}
+
+// walker は1つの注入関数の依存関係を辿る
+type walker struct {
+	*checker
+	inj   *injector
+	build *buildSet       // wire.Buildに渡されたもの
+	seen  map[string]bool // 検査済みの型
+
+	neededBy string // 辿っている引数を必要とする提供関数（構造体のフィールドの場合は空）
+}
+
+// walk は型を提供できるかを検査し、提供関数の引数を辿る
+func (w *walker) walk(t types.Type, path string) {
+	named, ok := types.Unalias(derefType(t)).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || !w.inScope(named.Obj().Pkg().Path()) {
+		// 基本型や範囲外の型は注入関数の引数として渡される
+		return
+	}
+	key := typeKey(named)
+	if w.seen[key] {
+		return
+	}
+	w.seen[key] = true
+
+	if w.fromBuild(named, path) {
+		return
+	}
+	if w.fromProviders(named, t, path) {
+		return
+	}
+
+	switch underlying := named.Underlying().(type) {
+	case *types.Interface:
+		w.checkInterface(named, path)
+	case *types.Struct:
+		w.report(CategoryMissingProvider, named.Obj().Name(), named.Obj().Pos(),
+			fmt.Sprintf("%s: no provider for %s", path, w.typeString(named)),
+			w.structFix(named)...)
+
+		// wire.Structで組み立てる場合はフィールドが依存関係になる
+		w.walkFields(underlying, nil, path)
+	}
+}
+
+// fromProviders は型を返す提供関数がある場合に、その依存関係を辿る
+// 提供関数が複数ある場合はwireと同様にどれを使うか決められないため報告する
+func (w *walker) fromProviders(named *types.Named, t types.Type, path string) bool {
+	providers := w.providers(named)
+	switch len(providers) {
+	case 0:
+		return false
+	case 1:
+		// 解析中のパッケージから見えない提供関数は引数を辿れない
+		if fn, ok := w.lookupObject(providers[0].PackagePath, providers[0].Name).(*types.Func); ok {
+			w.walkProvider(fn, path)
+		}
+		return true
+	}
+
+	// 例: "multiple providers found for *app.Svc, needed by app.NewHandler (app.NewSvc, app.NewSvcAlt)"
+	message := path + ": multiple providers found for " + w.typeString(t)
+	if w.neededBy != "" {
+		message += ", needed by " + w.neededBy
+	}
+	names := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		names = append(names, w.objectName(provider.PackagePath, provider.Name))
+	}
+	message += " (" + strings.Join(names, ", ") + ")"
+
+	diag := analysis.Diagnostic{
+		Pos:      w.inj.build.Pos(),
+		End:      w.inj.build.End(),
+		Category: CategoryAmbiguousProvider,
+		Message:  message,
+	}
+	for i, provider := range providers {
+		if pos := w.position(provider.Position); pos.IsValid() {
+			diag.Related = append(diag.Related, analysis.RelatedInformation{Pos: pos, Message: names[i] + " is declared here"})
+		}
+	}
+	w.pass.Report(diag)
+	return true
+}
+
+// walkProvider は提供関数の引数を辿る
+func (w *walker) walkProvider(fn *types.Func, path string) {
+	neededBy := w.neededBy
+	w.neededBy = w.objectName(fn.Pkg().Path(), fn.Name())
+	defer func() { w.neededBy = neededBy }()
+
+	params := fn.Type().(*types.Signature).Params()
+	for i := 0; i < params.Len(); i++ {
+		param := params.At(i)
+		name := param.Name()
+		if name == "" || name == "_" {
+			name = strconv.Itoa(i)
+		}
+		w.walk(param.Type(), path+"."+name)
+	}
+}
+
+// walkFields は構造体のフィールドを辿る（fieldsがnilの場合はすべてのフィールド）
+func (w *walker) walkFields(st *types.Struct, fields []string, path string) {
+	neededBy := w.neededBy
+	w.neededBy = ""
+	defer func() { w.neededBy = neededBy }()
+
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if fields == nil || structIncludes(fields, field.Name(), st.Tag(i)) {
+			w.walk(field.Type(), path+"."+field.Name())
+		}
+	}
+}
+
+// checkInterface は提供関数のないインターフェースの実装型を検査する
+func (w *walker) checkInterface(named *types.Named, path string) {
+	impls := w.implementations(named)
+
+	switch len(impls) {
+	case 0:
+		w.report(CategoryUnresolvedInterface, named.Obj().Name(), named.Obj().Pos(),
+			fmt.Sprintf("%s: no implementation of %s found", path, w.typeString(named)))
+
+	case 1:
+		impl := impls[0]
+		if !token.IsExported(impl.name) && impl.pkgPath != w.pass.Pkg.Path() {
+			w.report(CategoryUnexportedImplementation, impl.name, w.position(impl.position),
+				fmt.Sprintf("%s: %s is implemented only by unexported %s.%s, which wire.Bind cannot reference; export it or add a provider returning %s",
+					path, w.typeString(named), w.packageName(impl.pkgPath), impl.name, w.typeString(named)))
+			return
+		}
+		if impl.obj != nil {
+			w.walk(impl.obj.Type(), path)
+		}
+
+	default:
+		names := make([]string, 0, len(impls))
+		fixes := make([]analysis.SuggestedFix, 0, len(impls))
+		for _, impl := range impls {
+			names = append(names, w.objectName(impl.pkgPath, impl.name))
+			if impl.obj == nil {
+				continue
+			}
+			if fix, ok := w.bindFix(named, impl.obj); ok {
+				fixes = append(fixes, fix)
+			}
+		}
+		w.report(CategoryAmbiguousImplementation, named.Obj().Name(), named.Obj().Pos(),
+			fmt.Sprintf("%s: %s has %d implementations (%s); add wire.Bind to choose one",
+				path, w.typeString(named), len(impls), strings.Join(names, ", ")),
+			fixes...)
+	}
+}
+
+// providers はCLIと同じ検索で型を返す提供関数を探す（解析中のパッケージ以外は公開された関数のみ）
+func (w *walker) providers(named *types.Named) []packages.FunctionInfo {
+	var providers []packages.FunctionInfo
+	for _, fn := range packages.FactsFunctionsReturningType(named.Obj().Name(), named.Obj().Pkg().Path(), w.moduleFacts()) {
+		if token.IsExported(fn.Name) || fn.PackagePath == w.pass.Pkg.Path() {
+			providers = append(providers, fn)
+		}
+	}
+	return providers
+}
+
+// report は注入関数のwire.Buildの位置に診断を報告する（宣言の位置が分かれば関連情報として付ける）
+func (w *walker) report(category, name string, declared token.Pos, message string, fixes ...analysis.SuggestedFix) {
+	diag := analysis.Diagnostic{
+		Pos:            w.inj.build.Pos(),
+		End:            w.inj.build.End(),
+		Category:       category,
+		Message:        message,
+		SuggestedFixes: fixes,
+	}
+	if declared.IsValid() {
+		diag.Related = []analysis.RelatedInformation{{Pos: declared, Message: name + " is declared here"}}
+	}
+	w.pass.Report(diag)
+}
+
+// bindFix はインターフェースを実装型に結びつけるwire.Bindを追加する修正を作る
+func (w *walker) bindFix(iface *types.Named, impl *types.TypeName) (analysis.SuggestedFix, bool) {
+	if !impl.Exported() && impl.Pkg() != w.pass.Pkg {
+		return analysis.SuggestedFix{}, false
+	}
+
+	qualifier, imports := w.qualifier()
+	implType := impl.Type()
+	if !types.Implements(implType, iface.Underlying().(*types.Interface)) {
+		implType = types.NewPointer(implType)
+	}
+	expr := fmt.Sprintf("wire.Bind(new(%s), new(%s))", types.TypeString(iface, qualifier), types.TypeString(implType, qualifier))
+
+	return analysis.SuggestedFix{
+		Message:   fmt.Sprintf("Bind %s to %s", w.typeString(iface), w.typeString(implType)),
+		TextEdits: w.buildEdits(expr, *imports),
+	}, true
+}
+
+// structFix はフィールドから構造体を組み立てるwire.Structを追加する修正を作る
+func (w *walker) structFix(named *types.Named) []analysis.SuggestedFix {
+	qualifier, imports := w.qualifier()
+	expr := fmt.Sprintf("wire.Struct(new(%s), \"*\")", types.TypeString(named, qualifier))
+
+	return []analysis.SuggestedFix{{
+		Message:   fmt.Sprintf("Construct %s with wire.Struct", w.typeString(named)),
+		TextEdits: w.buildEdits(expr, *imports),
+	}}
+}
+
+// buildEdits はwire.Buildの最後の引数の後に式を追加し、必要なimportを加える編集を作る
+func (w *walker) buildEdits(expr string, imports []string) []analysis.TextEdit {
+	build := w.inj.build
+	var edit analysis.TextEdit
+	if len(build.Args) == 0 {
+		edit = analysis.TextEdit{Pos: build.Lparen + 1, End: build.Lparen + 1, NewText: []byte(expr)}
+	} else {
+		last := build.Args[len(build.Args)-1].End()
+		edit = analysis.TextEdit{Pos: last, End: last, NewText: []byte(", " + expr)}
+	}
+
+	edits := []analysis.TextEdit{edit}
+	for _, importPath := range imports {
+		edits = append(edits, w.importEdit(importPath))
+	}
+	return edits
+}
+
+// importEdit はファイルにimportを追加する編集を作る
+func (w *walker) importEdit(importPath string) analysis.TextEdit {
+	file := w.inj.file
+	spec := strconv.Quote(importPath)
+
+	var last *ast.GenDecl
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			last = gen
+		}
+	}
+	switch {
+	case last == nil:
+		return analysis.TextEdit{Pos: file.Name.End(), End: file.Name.End(), NewText: []byte("\n\nimport " + spec)}
+	case last.Lparen.IsValid():
+		return analysis.TextEdit{Pos: last.Rparen, End: last.Rparen, NewText: []byte("\t" + spec + "\n")}
+	default:
+		return analysis.TextEdit{Pos: last.End(), End: last.End(), NewText: []byte("\nimport " + spec)}
+	}
+}
+
+// qualifier はwire.goのimport名で型を修飾する関数と、追加が必要なimportを返す
+func (w *walker) qualifier() (types.Qualifier, *[]string) {
+	names := make(map[string]string)
+	for _, spec := range w.inj.file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			names[importPath] = spec.Name.Name
+		} else if obj, ok := w.pass.TypesInfo.Implicits[spec].(*types.PkgName); ok {
+			names[importPath] = obj.Name()
+		}
+	}
+
+	var missing []string
+	qualifier := func(pkg *types.Package) string {
+		if pkg == w.pass.Pkg {
+			return ""
+		}
+		if name, ok := names[pkg.Path()]; ok {
+			return name
+		}
+		names[pkg.Path()] = pkg.Name()
+		missing = append(missing, pkg.Path())
+		return pkg.Name()
+	}
+	return qualifier, &missing
+}
+
+// typeString はパッケージ名で修飾した型の文字列表現を返す
+func (w *walker) typeString(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		if pkg == w.pass.Pkg {
+			return ""
+		}
+		return pkg.Name()
+	})
+}
+
+// inScope は提供関数と実装型を探す範囲のパッケージかどうかを判定する（解析中のモジュール内）
+func (c *checker) inScope(pkgPath string) bool {
+	return inModule(c.pass, pkgPath)
+}
+
+// inModule はパッケージが解析中のパッケージと同じモジュールに属するかを判定する
+// モジュール情報がない場合は先頭のパス要素が一致するかで推測する
+func inModule(pass *analysis.Pass, pkgPath string) bool {
+	if pass.Module != nil && pass.Module.Path != "" {
+		return pkgPath == pass.Module.Path || strings.HasPrefix(pkgPath, pass.Module.Path+"/")
+	}
+	first, _, _ := strings.Cut(pass.Pkg.Path(), "/")
+	pkgFirst, _, _ := strings.Cut(pkgPath, "/")
+	return first == pkgFirst
+}
+
+// isStdlib は標準ライブラリのパッケージかどうかを先頭のパス要素にドメイン（"."）があるかで判定する
+func isStdlib(pkgPath string) bool {
+	first, _, _ := strings.Cut(pkgPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// searchPackages は解析中のパッケージと、同じモジュール内の依存パッケージをパス順に返す
+func searchPackages(pass *analysis.Pass) []*types.Package {
+	seen := map[*types.Package]bool{pass.Pkg: true}
+	queue := []*types.Package{pass.Pkg}
+	for i := 0; i < len(queue); i++ {
+		for _, imported := range queue[i].Imports() {
+			if !seen[imported] && inModule(pass, imported.Path()) {
+				seen[imported] = true
+				queue = append(queue, imported)
+			}
+		}
+	}
+	sort.Slice(queue, func(i, j int) bool { return queue[i].Path() < queue[j].Path() })
+	return queue
+}
+
+// findWireBuild は関数内のwire.Buildの呼び出しを探す
+func findWireBuild(pass *analysis.Pass, funcDecl *ast.FuncDecl) *ast.CallExpr {
+	var found *ast.CallExpr
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Build" {
+			return true
+		}
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			if pkgName, ok := pass.TypesInfo.Uses[ident].(*types.PkgName); ok && pkgName.Imported().Path() == wireImportPath {
+				found = call
+			}
+		}
+		return found == nil
+	})
+	return found
+}
+
+// typeKey は型を同一視するためのキーを返す
+func typeKey(named *types.Named) string {
+	return objectKey(named.Obj())
+}
+
+// objectKey はパッケージレベルの宣言を"importパス.名前"のキーで表す
+func objectKey(obj types.Object) string {
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+// derefType はポインタ型の場合は要素型を返す
+func derefType(t types.Type) types.Type {
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
@@ -0,0 +1,68 @@
+package wirecheck
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	// 注入関数のファイルはwireinjectタグ付きのため、タグを付けて読み込む
+	t.Setenv("GOFLAGS", os.Getenv("GOFLAGS")+" -tags=wireinject")
+
+	analysistest.RunWithSuggestedFixes(t, "../../testdata/wirecheck", Analyzer, ".")
+}
+
+func TestAnalyzer_Fixed(t *testing.T) {
+	// 修正を適用済み（引数、入れ子のプロバイダセットのwire.Bind、wire.Struct）の注入関数は報告しない
+	t.Setenv("GOFLAGS", os.Getenv("GOFLAGS")+" -tags=wireinject")
+
+	analysistest.Run(t, "../../testdata/wirecheck", Analyzer, "./fixed")
+}
+
+func TestAnalyzer_AmbiguousProvider(t *testing.T) {
+	// 同じ型を返す提供関数が複数ある場合は、CLIと同じ文言で候補を報告する
+	t.Setenv("GOFLAGS", os.Getenv("GOFLAGS")+" -tags=wireinject")
+
+	analysistest.Run(t, "../../testdata/ambiguous", Analyzer, ".")
+}
+
+func TestAnalyzer_Vettool(t *testing.T) {
+	// go vet -vettoolでは依存パッケージがエクスポートデータから読み込まれ、非公開の実装型が見えない
+	if testing.Short() {
+		t.Skip("builds the wirecheck command")
+	}
+	tool := filepath.Join(t.TempDir(), "wirecheck")
+	build := exec.Command("go", "build", "-o", tool, "github.com/rmocchy/convinient_wire/cmd/wirecheck")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("go build failed: %v\n%s", err, out)
+	}
+
+	var stderr bytes.Buffer
+	vet := exec.Command("go", "vet", "-vettool="+tool, "-tags=wireinject", "./...")
+	vet.Dir = "../../testdata/wirecheck"
+	vet.Stderr = &stderr
+	if err := vet.Run(); err == nil {
+		t.Fatalf("go vet succeeded, want diagnostics")
+	}
+
+	out := stderr.String()
+	for _, want := range []string{
+		"store.Store is implemented only by unexported store.memoryStore",
+		"notify.Notifier has 2 implementations",
+		"no implementation of cache.Cache found",
+		"no provider for metrics.Metrics",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("go vet output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "fixed/") {
+		t.Errorf("go vet reported the fixed injector:\n%s", out)
+	}
+}
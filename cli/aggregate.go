@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+	"github.com/rmocchy/convinient_wire/generator"
+)
+
+// runAggregate はパッケージパターンから見つけた型をまとめる構造体を生成する
+func runAggregate(args []string, stdout, stderr io.Writer) error {
+	var flags analysisFlags
+	fs := newFlagSet("aggregate", stderr)
+	flags.register(fs)
+	handlers := fs.String("handlers", "./handler/...", "package pattern searched for the aggregated types")
+	match := fs.String("match", "*Handler", "type name rule (path.Match syntax)")
+	structName := fs.String("struct", "ControllerSet", "name of the generated struct")
+	dryRun := fs.Bool("dry-run", false, "print the generated file instead of writing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	wireFilePath := flags.wireFilePath()
+	outDir := filepath.Dir(wireFilePath)
+
+	// wire.goに手書きの構造体が残っていると二重定義になる
+	if declaresType(wireFilePath, *structName) {
+		return fmt.Errorf("%s already declares %s; remove it to generate the struct", wireFilePath, *structName)
+	}
+
+	analyzer := flags.newAnalyzer()
+	types, err := analyzer.DiscoverProvidedTypes(*handlers, *match)
+	if err != nil {
+		return err
+	}
+
+	aggregate := &generator.Aggregate{
+		PackageName: "main",
+		StructName:  *structName,
+		Pattern:     *handlers,
+		NameRule:    *match,
+		Types:       types,
+	}
+	if name, pkgPath, err := app.ResolvePackage(outDir); err == nil && name != "" {
+		aggregate.PackageName = name
+		aggregate.PackagePath = pkgPath
+	}
+	// wire.goが作業ディレクトリ直下にある場合は、go generateで同じ構造体を生成し直せるようにする
+	if filepath.Dir(flags.wireFile) == "." {
+		aggregate.Command = strings.Join([]string{
+			"go run github.com/rmocchy/convinient_wire aggregate",
+			"-handlers", *handlers, "-match", strconv.Quote(*match), "-struct", *structName,
+		}, " ")
+	}
+
+	src, err := aggregate.Render()
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		_, err := stdout.Write(src)
+		return err
+	}
+
+	filePath := filepath.Join(outDir, generator.AggregateFileName)
+	if err := os.WriteFile(filePath, src, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	fmt.Fprintf(stdout, "wrote %s (%d fields)\n", filePath, len(types))
+
+	return nil
+}
+
+// declaresType はファイルに指定された名前の型宣言があるかを判定する（ファイルがない場合はfalse）
+func declaresType(filePath, typeName string) bool {
+	node, err := parser.ParseFile(token.NewFileSet(), filePath, nil, 0)
+	if err != nil {
+		return false
+	}
+	for _, decl := range node.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			if spec.(*ast.TypeSpec).Name.Name == typeName {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun_Aggregate(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"aggregate", "-dir", "../sample/basic", "-wire", "cmd/wire.go", "-dry-run"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run() = %d, stderr = %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "\tuserHandler *handler.UserHandler\n") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	// wire.goに手書きの構造体がある場合は生成しない
+	stdout.Reset()
+	stderr.Reset()
+	code = Run([]string{"aggregate", "-dir", "../sample/basic", "-dry-run"}, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), "already declares ControllerSet") {
+		t.Errorf("Run() = %d, stderr = %s", code, stderr.String())
+	}
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+	"github.com/rmocchy/convinient_wire/ast_analyzer/cache"
+)
+
+// command はサブコマンドを表す
+type command struct {
+	summary string                                              // ヘルプに表示する説明
+	run     func(args []string, stdout, stderr io.Writer) error // 実行する関数
+}
+
+// commands はサブコマンドの一覧
+var commands = map[string]command{
+	"aggregate": {summary: "generate a struct with one field per discovered handler", run: runAggregate},
+	"init":      {summary: "scaffold a new wire.go from root types", run: runInit},
+	"graph":     {summary: "show the provider call graph of a generated wire_gen.go", run: runGraph},
+	"conflicts": {summary: "report values of the same unnamed type and suggest named types", run: runConflicts},
+	"explain":   {summary: "print every dependency path from each injector root to a type or provider", run: runExplain},
+	"gen":       {summary: "generate wire_gen.go without running the wire tool", run: runGen},
+	"lsp":       {summary: "run a language server on stdio with code actions and hover for wire.go", run: runLSP},
+	"report":    {summary: "report dependency problems as text or a SARIF 2.1.0 log", run: runReport},
+	"share":     {summary: "move providers shared by several injectors into a common wire.NewSet", run: runShare},
+	"sets":      {summary: "generate a wire.NewSet provider set in each package", run: runSets},
+	"verify":    {summary: "check that the committed wire_gen.go matches the analysis", run: runVerify},
+	"watch":     {summary: "re-analyze injectors affected by file changes and report outdated wire.go or wire_gen.go", run: runWatch},
+}
+
+// Run はコマンドライン引数を解釈してサブコマンドを実行し、終了コードを返す
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		printUsage(stderr)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command: %s\n\n", args[0])
+		printUsage(stderr)
+		return 2
+	}
+
+	if err := cmd.run(args[1:], stdout, stderr); err != nil {
+		if err == flag.ErrHelp {
+			return 2
+		}
+		fmt.Fprintf(stderr, "%s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// printUsage はサブコマンドの一覧を表示する
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: convinient_wire <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
+
+// analysisFlags は解析に共通するフラグ
+type analysisFlags struct {
+	dir      string       // 作業ディレクトリ
+	wireFile string       // wire.goのパス（作業ディレクトリからの相対パス）
+	pattern  string       // 検索対象のパッケージパターン
+	values   []app.Value  // wire.Valueで供給する値
+	cacheDir string       // 抽出済みの情報をキャッシュするディレクトリ（空の場合は既定のディレクトリ）
+	noCache  bool         // キャッシュを使わないかどうか
+	workers  int          // 型の情報の検索と依存グラフの組み立てを並行するワーカーの数（0の場合は既定値）
+	boundary app.Boundary // 再帰的に解析する範囲
+}
+
+// register はフラグセットに共通フラグを登録する
+func (f *analysisFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.dir, "dir", ".", "working directory (module containing wire.go)")
+	fs.StringVar(&f.wireFile, "wire", "wire.go", "path to wire.go, relative to -dir")
+	fs.StringVar(&f.pattern, "pattern", "./...", "package pattern searched for providers")
+	fs.Func("value", "value supplied with wire.Value, as TYPE=EXPR (e.g. *net/http.Client=net/http.DefaultClient); repeatable", func(spec string) error {
+		value, err := app.ParseValue(spec)
+		if err != nil {
+			return err
+		}
+		f.values = append(f.values, value)
+		return nil
+	})
+	fs.StringVar(&f.cacheDir, "cache-dir", "", "directory caching per-package analysis facts (default: $XDG_CACHE_HOME/convinient_wire)")
+	fs.BoolVar(&f.noCache, "no-cache", false, "reload every package instead of reusing cached analysis facts")
+	fs.BoolVar(&f.boundary.FollowStdlib, "follow-stdlib", false, "analyze standard library types recursively instead of treating them as injector inputs")
+	fs.BoolVar(&f.boundary.FollowThirdParty, "follow-third-party", false, "analyze third-party types recursively instead of treating them as injector inputs")
+	fs.Func("local-prefix", "package path prefix outside the main module analyzed like the module's own packages (e.g. example.com/shared); repeatable", func(prefix string) error {
+		if prefix == "" {
+			return fmt.Errorf("empty package path prefix")
+		}
+		f.boundary.LocalPrefixes = append(f.boundary.LocalPrefixes, prefix)
+		return nil
+	})
+	fs.IntVar(&f.workers, "workers", 0, "number of type lookups and injector graphs processed in parallel; shared trees are built one at a time (default: GOMAXPROCS)")
+}
+
+// wireFilePath はwire.goのパスを返す
+func (f *analysisFlags) wireFilePath() string {
+	if filepath.IsAbs(f.wireFile) {
+		return f.wireFile
+	}
+	return filepath.Join(f.dir, f.wireFile)
+}
+
+// newAnalyzer はフラグからWireAnalyzerを作成する
+func (f *analysisFlags) newAnalyzer() *app.WireAnalyzer {
+	return app.NewWireAnalyzer(f.dir, f.pattern, f.analyzerOptions()...)
+}
+
+// analyzerOptions はフラグからWireAnalyzerのオプションを作成する
+// キャッシュのディレクトリを決められない場合はキャッシュを使わずに解析する
+func (f *analysisFlags) analyzerOptions() []app.Option {
+	opts := []app.Option{app.WithValues(f.values...), app.WithBoundary(f.boundary)}
+	if f.workers > 0 {
+		opts = append(opts, app.WithConcurrency(f.workers))
+	}
+	if f.noCache {
+		return opts
+	}
+	dir := f.cacheDir
+	if dir == "" {
+		defaultDir, err := cache.DefaultDir()
+		if err != nil {
+			return opts
+		}
+		dir = defaultDir
+	}
+	return append(opts, app.WithCache(cache.New(dir)))
+}
+
+// newFlagSet はエラー出力先を設定したフラグセットを作成する
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
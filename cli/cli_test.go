@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+func TestMain(m *testing.M) {
+	// 解析結果のキャッシュをユーザーのキャッシュディレクトリに書き込まないようにする
+	dir, err := os.MkdirTemp("", "convinient_wire-cache")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CACHE_HOME", dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestRun_Usage(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "引数なし", args: nil, wantCode: 2, wantErr: "usage:"},
+		{name: "不明なコマンド", args: []string{"unknown"}, wantCode: 2, wantErr: "unknown command: unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := Run(tt.args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("Run() = %d, want %d", code, tt.wantCode)
+			}
+			if !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Errorf("stderr = %q, want to contain %q", stderr.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnalysisFlags_Boundary(t *testing.T) {
+	var flags analysisFlags
+	fs := newFlagSet("test", &bytes.Buffer{})
+	flags.register(fs)
+	args := []string{"-follow-stdlib", "-local-prefix", "example.com/shared", "-local-prefix", "example.com/vendorlib"}
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := app.Boundary{
+		FollowStdlib:  true,
+		LocalPrefixes: []string{"example.com/shared", "example.com/vendorlib"},
+	}
+	if !reflect.DeepEqual(flags.boundary, want) {
+		t.Errorf("boundary = %+v, want %+v", flags.boundary, want)
+	}
+
+	// 指定しない場合はメインモジュール内の型だけを辿る
+	var defaults analysisFlags
+	fs = newFlagSet("test", &bytes.Buffer{})
+	defaults.register(fs)
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !reflect.DeepEqual(defaults.boundary, app.DefaultBoundary()) {
+		t.Errorf("default boundary = %+v, want %+v", defaults.boundary, app.DefaultBoundary())
+	}
+}
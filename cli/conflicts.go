@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+	"github.com/rmocchy/convinient_wire/generator"
+)
+
+// runConflicts は同じ名前のない型を複数の意味で必要としている引数を報告し、名前付きの型への置き換えを提案する
+func runConflicts(args []string, stdout, stderr io.Writer) error {
+	var flags analysisFlags
+	fs := newFlagSet("conflicts", stderr)
+	flags.register(fs)
+	fix := fs.Bool("fix", false, "declare the suggested named types and rewrite the provider signatures")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	analyzer := flags.newAnalyzer()
+	injectors, err := analyzer.AnalyzeInjectors(flags.wireFilePath())
+	if err != nil {
+		return err
+	}
+
+	var conflicts []app.TypeConflict
+	for _, injector := range injectors {
+		if injector.Graph == nil {
+			continue
+		}
+		for _, conflict := range injector.Graph.Conflicts {
+			writeConflict(stdout, injector.Name, conflict)
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	if len(conflicts) == 0 {
+		fmt.Fprintln(stdout, "no conflicts found")
+		return nil
+	}
+	if !*fix {
+		return fmt.Errorf("%d conflicts found (use -fix to introduce the named types)", len(conflicts))
+	}
+
+	pkgs, err := analyzer.SearchPackages()
+	if err != nil {
+		return err
+	}
+	files, err := generator.FixTypeConflicts(pkgs, conflicts)
+	if err != nil {
+		return err
+	}
+
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		if err := os.WriteFile(filename, files[filename], 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filename, err)
+		}
+		fmt.Fprintf(stdout, "wrote %s\n", filename)
+	}
+	fmt.Fprintln(stdout, "regenerate wire_gen.go to pass the new types to the providers")
+
+	return nil
+}
+
+// checkConflicts は衝突・用意できない依存・提供関数が複数ある依存を表示し、いずれかがあればエラーを返す
+// 名前のない型を複数の意味で必要としていたり、ポインタかどうかが異なる値しか作れなかったり、
+// 同じ型を返す提供関数が複数あると、生成するコードに正しい値を渡せない
+func checkConflicts(w io.Writer, injectors []*app.InjectorInfo) error {
+	count, unsatisfied, ambiguous := 0, 0, 0
+	for _, injector := range injectors {
+		if injector.Graph == nil {
+			continue
+		}
+		for _, conflict := range injector.Graph.Conflicts {
+			writeConflict(w, injector.Name, conflict)
+			count++
+		}
+		for _, dependency := range injector.Graph.Unsatisfied {
+			fmt.Fprintf(w, "%s: %s\n", injector.Name, dependency)
+			unsatisfied++
+		}
+		for _, provider := range injector.Graph.Ambiguous {
+			fmt.Fprintf(w, "%s: %s\n", injector.Name, provider)
+			ambiguous++
+		}
+	}
+	if count > 0 {
+		return fmt.Errorf("%d conflicts found (run conflicts -fix to introduce the named types)", count)
+	}
+	if unsatisfied > 0 {
+		return fmt.Errorf("%d dependencies have no provider", unsatisfied)
+	}
+	if ambiguous > 0 {
+		return fmt.Errorf("%d dependencies have multiple providers", ambiguous)
+	}
+	return nil
+}
+
+// writeConflict は衝突と提案を表示する
+func writeConflict(w io.Writer, injector string, conflict app.TypeConflict) {
+	fmt.Fprintf(w, "%s: %s is needed with different meanings:\n", injector, conflict.Type.TypeString)
+	for _, use := range conflict.Uses {
+		if use.IsField() {
+			fmt.Fprintf(w, "\t%s.%s{%s %s}\n", use.Struct.PackageName, use.Struct.TypeName, use.Param, conflict.Type.TypeString)
+			continue
+		}
+		fmt.Fprintf(w, "\t%s.%s(%s %s)\n", use.Function.PackageName, use.Function.Name, use.Param, conflict.Type.TypeString)
+	}
+	fmt.Fprintln(w, "  suggested named types:")
+	for _, suggestion := range conflict.Suggestions {
+		fmt.Fprintf(w, "\t%s // in %s\n", suggestion.Declaration(), suggestion.PackagePath)
+	}
+}
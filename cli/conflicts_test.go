@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun_Conflicts(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"conflicts", "-dir", "../testdata/conflicts"}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("Run() = %d, want 1 (stderr = %s)", code, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		"InitializeApp: string is needed with different meanings:",
+		"db.NewDB(dsn string)",
+		"type APIKey string // in example.com/conflicts/client",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRun_GenerateWithConflicts(t *testing.T) {
+	// 衝突したままではどの値を渡すべきか決められないため、生成せずに衝突を報告する
+	for _, args := range [][]string{
+		{"gen", "-dir", "../testdata/conflicts", "-dry-run"},
+		{"sets", "-dir", "../testdata/conflicts", "-dry-run"},
+		{"share", "-dir", "../testdata/conflicts", "-dry-run"},
+		{"verify", "-dir", "../testdata/conflicts"},
+	} {
+		t.Run(args[0], func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := Run(args, &stdout, &stderr)
+			if code != 1 {
+				t.Fatalf("Run() = %d, want 1 (stderr = %s)", code, stderr.String())
+			}
+			for _, want := range []string{"InitializeApp: string is needed with different meanings:", "1 conflicts found"} {
+				if !strings.Contains(stderr.String(), want) {
+					t.Errorf("stderr does not contain %q:\n%s", want, stderr.String())
+				}
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("stdout = %s, want nothing generated", stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+// runExplain は注入関数のルートから型または提供関数までの依存関係の経路を全て表示する
+func runExplain(args []string, stdout, stderr io.Writer) error {
+	var flags analysisFlags
+	fs := newFlagSet("explain", stderr)
+	flags.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: convinient_wire explain [flags] <type or provider>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected exactly one type or provider")
+	}
+	query := fs.Arg(0)
+
+	injectors, err := flags.newAnalyzer().AnalyzeInjectors(flags.wireFilePath())
+	if err != nil {
+		return err
+	}
+
+	paths := app.Explain(injectors, query)
+	if len(paths) == 0 {
+		return fmt.Errorf("%s is not reachable from any injector", query)
+	}
+
+	injector := ""
+	for _, p := range paths {
+		if p.Injector != injector {
+			injector = p.Injector
+			fmt.Fprintf(stdout, "%s:\n", injector)
+		}
+		fmt.Fprintf(stdout, "  %s\n", p)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun_Explain(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"explain", "-dir", "../testdata/multi", "db.DB"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run() = %d, want 0 (stderr = %s)", code, stderr.String())
+	}
+
+	want := `InitializeAPI:
+  API.server -> Server.users -> UserRepository.db -> DB
+InitializeWorker:
+  Worker.worker -> Worker.users -> UserRepository.db -> DB
+`
+	if stdout.String() != want {
+		t.Errorf("output = %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestRun_Explain_NotReachable(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"explain", "-dir", "../testdata/multi", "NoSuchType"}, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), "NoSuchType is not reachable from any injector") {
+		t.Errorf("Run() = %d, stderr = %s, want 1 with not reachable", code, stderr.String())
+	}
+}
+
+func TestRun_Explain_MissingQuery(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"explain", "-dir", "../testdata/multi"}, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), "usage: convinient_wire explain") {
+		t.Errorf("Run() = %d, stderr = %s, want 1 with usage", code, stderr.String())
+	}
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rmocchy/convinient_wire/generator"
+)
+
+// runGen はwireコマンドを使わずにwire_gen.goを生成する
+func runGen(args []string, stdout, stderr io.Writer) error {
+	var flags analysisFlags
+	fs := newFlagSet("gen", stderr)
+	flags.register(fs)
+	dryRun := fs.Bool("dry-run", false, "print the generated file instead of writing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	analyzer := flags.newAnalyzer()
+	wireFilePath := flags.wireFilePath()
+	injectors, err := analyzer.AnalyzeInjectors(wireFilePath)
+	if err != nil {
+		return err
+	}
+
+	if err := checkConflicts(stderr, injectors); err != nil {
+		return err
+	}
+
+	src, err := generator.GenerateWireGen(wireFilePath, analyzer, injectors)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		_, err := stdout.Write(src)
+		return err
+	}
+
+	// wire.goと同じディレクトリに書き出す
+	filePath := filepath.Join(filepath.Dir(wireFilePath), generator.WireGenFileName)
+	if err := os.WriteFile(filePath, src, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	fmt.Fprintf(stdout, "wrote %s\n", filePath)
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun_Gen(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"gen", "-dir", "../testdata/inputs", "-dry-run"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run() = %d, stderr = %s", code, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		"//go:build !wireinject",
+		"db, cleanup, err := repo.NewDB(ctx, dsn)",
+		"type App struct {",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRun_Gen_Values(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"gen", "-dir", "../testdata/values", "-dry-run",
+		"-value", "*net/http.Client=net/http.DefaultClient",
+		"-value", "io.Writer=os.Stdout",
+	}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run() = %d, stderr = %s", code, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		"func InitializeApp() *App {",
+		"writer := os.Stdout",
+		"client := http.DefaultClient",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	file "github.com/rmocchy/convinient_wire/ast_analyzer/files"
+	"github.com/rmocchy/convinient_wire/generator"
+)
+
+// runGraph は生成済みのwire_gen.goから提供関数の呼び出しグラフを読み取って表示する
+func runGraph(args []string, stdout, stderr io.Writer) error {
+	var flags analysisFlags
+	fs := newFlagSet("graph", stderr)
+	flags.register(fs)
+	wireGen := fs.String("wire-gen", "", "path to the generated wire_gen.go (default: next to wire.go)")
+	format := fs.String("format", "text", "output format: text or dot")
+	compare := fs.Bool("compare", false, "compare the call graph with the analyzed dependency tree")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	wireFilePath := flags.wireFilePath()
+	wireGenPath := *wireGen
+	if wireGenPath == "" {
+		wireGenPath = filepath.Join(filepath.Dir(wireFilePath), generator.WireGenFileName)
+	}
+
+	genFile, err := file.ParseWireGenFile(wireGenPath, nil)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "text":
+		writeGraphText(stdout, genFile)
+	case "dot":
+		writeGraphDot(stdout, genFile)
+	default:
+		return fmt.Errorf("unknown format: %s", *format)
+	}
+
+	if !*compare {
+		return nil
+	}
+
+	analyzer := flags.newAnalyzer()
+	injectors, err := analyzer.AnalyzeInjectors(wireFilePath)
+	if err != nil {
+		return err
+	}
+
+	bodies := make(map[string]file.InjectorBody, len(genFile.Injectors))
+	for _, body := range genFile.Injectors {
+		bodies[body.Name] = body
+	}
+
+	fmt.Fprintln(stdout)
+	for _, injector := range injectors {
+		body, ok := bodies[injector.Name]
+		if !ok {
+			fmt.Fprintf(stdout, "%s: not found in %s\n", injector.Name, wireGenPath)
+			continue
+		}
+		mismatches := analyzer.CompareWireGen(injector, body)
+		if len(mismatches) == 0 {
+			fmt.Fprintf(stdout, "%s: matches the analyzed tree\n", injector.Name)
+			continue
+		}
+		for _, mismatch := range mismatches {
+			fmt.Fprintf(stdout, "%s: %s\n", injector.Name, mismatch)
+		}
+	}
+
+	return nil
+}
+
+// writeGraphText は呼び出しグラフを代入文の形で表示する
+func writeGraphText(w io.Writer, genFile *file.WireGenFile) {
+	for _, body := range genFile.Injectors {
+		fmt.Fprintf(w, "%s (generated by %s):\n", body.Name, genFile.Generator)
+		for _, call := range body.Calls {
+			args := make([]string, len(call.Args))
+			for i, arg := range call.Args {
+				args[i] = call.ArgVars[i]
+				if args[i] == "" {
+					args[i] = arg
+				}
+				if call.IsStruct {
+					args[i] = call.Fields[i] + ": " + args[i]
+				}
+			}
+			if call.IsStruct {
+				fmt.Fprintf(w, "\t%s := %s{%s}\n", call.Var, path.Base(call.Func), strings.Join(args, ", "))
+			} else {
+				fmt.Fprintf(w, "\t%s := %s(%s)\n", call.Var, path.Base(call.Func), strings.Join(args, ", "))
+			}
+		}
+	}
+}
+
+// writeGraphDot は呼び出しグラフをGraphvizのdot形式で出力する（値を渡す側から受け取る側への辺）
+func writeGraphDot(w io.Writer, genFile *file.WireGenFile) {
+	fmt.Fprintln(w, "digraph wire {")
+	for _, body := range genFile.Injectors {
+		fmt.Fprintf(w, "\tsubgraph %q {\n", "cluster_"+body.Name)
+		fmt.Fprintf(w, "\t\tlabel = %q;\n", body.Name)
+
+		node := func(name string) string { return body.Name + "." + name }
+		for _, param := range body.Params {
+			fmt.Fprintf(w, "\t\t%q [label=%q, shape=box];\n", node(param), param)
+		}
+		for _, call := range body.Calls {
+			fmt.Fprintf(w, "\t\t%q [label=%q];\n", node(call.Var), path.Base(call.Func))
+		}
+		for _, call := range body.Calls {
+			for _, argVar := range call.ArgVars {
+				if argVar != "" {
+					fmt.Fprintf(w, "\t\t%q -> %q;\n", node(argVar), node(call.Var))
+				}
+			}
+		}
+		fmt.Fprintln(w, "\t}")
+	}
+	fmt.Fprintln(w, "}")
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun_Graph(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"graph", "-dir", "../sample/basic", "-compare"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run() = %d, stderr = %s", code, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		"InitializeUserHandler (generated by wire):",
+		"userRepository := repository.NewUserRepository(config)",
+		"InitializeUserHandler: matches the analyzed tree",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+	"github.com/rmocchy/convinient_wire/generator"
+)
+
+// runInit はルートの型から新しいwire.goを作成する
+func runInit(args []string, stdout, stderr io.Writer) error {
+	var flags analysisFlags
+	fs := newFlagSet("init", stderr)
+	flags.register(fs)
+	roots := fs.String("root", "", "comma separated root types, e.g. handler.UserHandler,handler.AdminHandler")
+	handlers := fs.String("handlers", "", "package pattern whose types matching -match are added as root types")
+	match := fs.String("match", "*Handler", "type name rule used with -handlers (path.Match syntax)")
+	structName := fs.String("struct", "", "name of the struct combining the root types (required for several roots)")
+	pkgName := fs.String("package", "", "package name of the new wire.go (default: the package of its directory, or main)")
+	force := fs.Bool("force", false, "overwrite an existing wire.go")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	wireFilePath := flags.wireFilePath()
+	if _, err := os.Stat(wireFilePath); err == nil && !*force {
+		return fmt.Errorf("%s already exists (use -force to overwrite)", wireFilePath)
+	}
+
+	analyzer := flags.newAnalyzer()
+	scaffold := &generator.Scaffold{PackageName: *pkgName, StructName: *structName}
+	// 既にパッケージがあるディレクトリでは、そのパッケージ名を使う
+	if name, pkgPath, err := app.ResolvePackage(filepath.Dir(wireFilePath)); err == nil && name != "" {
+		if scaffold.PackageName == "" {
+			scaffold.PackageName = name
+		}
+		if scaffold.PackageName == name {
+			scaffold.PackagePath = pkgPath
+		}
+	}
+	if scaffold.PackageName == "" {
+		scaffold.PackageName = "main"
+	}
+	for _, spec := range strings.Split(*roots, ",") {
+		if spec = strings.TrimSpace(spec); spec == "" {
+			continue
+		}
+		root, err := analyzer.ResolveType(spec)
+		if err != nil {
+			return err
+		}
+		scaffold.Roots = append(scaffold.Roots, root)
+	}
+	if *handlers != "" {
+		discovered, err := analyzer.DiscoverProvidedTypes(*handlers, *match)
+		if err != nil {
+			return err
+		}
+		scaffold.Roots = append(scaffold.Roots, discovered...)
+	}
+
+	// 仮のwire.goを書き出して依存関係を解析する
+	draft, err := scaffold.RenderDraft()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(wireFilePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	// -forceで上書きする場合は、失敗したときに元のwire.goへ戻せるよう内容を保持する
+	original, err := os.ReadFile(wireFilePath)
+	existed := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", wireFilePath, err)
+	}
+	// restore は元のwire.goに戻す（戻せなかった場合は元の失敗と合わせて返す）
+	restore := func(cause error) error {
+		var err error
+		if existed {
+			err = os.WriteFile(wireFilePath, original, 0o644)
+		} else if err = os.Remove(wireFilePath); os.IsNotExist(err) {
+			err = nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w; failed to restore %s: %w", cause, wireFilePath, err)
+		}
+		return cause
+	}
+
+	if err := os.WriteFile(wireFilePath, draft, 0o644); err != nil {
+		return restore(fmt.Errorf("failed to write %s: %w", wireFilePath, err))
+	}
+
+	src, err := renderScaffold(scaffold, flags, wireFilePath, stderr)
+	if err != nil {
+		// 解析できなかった場合は仮のファイルを残さない
+		return restore(err)
+	}
+
+	if err := os.WriteFile(wireFilePath, src, 0o644); err != nil {
+		return restore(fmt.Errorf("failed to write %s: %w", wireFilePath, err))
+	}
+	fmt.Fprintf(stdout, "wrote %s\n", wireFilePath)
+
+	return nil
+}
+
+// renderScaffold は仮のwire.goを解析して、wire.Buildを推論したwire.goを生成する
+// 衝突などで提供関数を選べない場合は、他のコマンドと同じく問題をstderrに表示してエラーを返す
+func renderScaffold(scaffold *generator.Scaffold, flags analysisFlags, wireFilePath string, stderr io.Writer) ([]byte, error) {
+	// 仮のwire.goを含めて読み込み直すため、新しいWireAnalyzerを使う
+	analyzer := flags.newAnalyzer()
+	injectors, err := analyzer.AnalyzeInjectors(wireFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, injector := range injectors {
+		if injector.Name == scaffold.InjectorName() {
+			if err := checkConflicts(stderr, []*app.InjectorInfo{injector}); err != nil {
+				return nil, err
+			}
+			return scaffold.Render(injector)
+		}
+	}
+	return nil, fmt.Errorf("injector %s not found in the draft", scaffold.InjectorName())
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gopkgs "golang.org/x/tools/go/packages"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+	"github.com/rmocchy/convinient_wire/internal/testutil"
+)
+
+// typeCheck は生成したwire.goのパッケージをwireinjectタグ付きで型検査する
+func typeCheck(t *testing.T, dir string) {
+	t.Helper()
+	cfg := &gopkgs.Config{
+		Mode:       gopkgs.LoadAllSyntax,
+		Dir:        dir,
+		BuildFlags: packages.BuildFlags(),
+	}
+	pkgs, err := gopkgs.Load(cfg, ".")
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", dir, err)
+	}
+	for _, pkg := range pkgs {
+		for _, pkgErr := range pkg.Errors {
+			t.Errorf("%s: %v", pkg.PkgPath, pkgErr)
+		}
+	}
+}
+
+func TestRun_Init(t *testing.T) {
+	// モジュール内に作成する必要があるため、テスト後に削除する
+	dir := filepath.Join("..", "testdata", "inputs", "cmd", "scaffold")
+	t.Cleanup(func() { os.RemoveAll(filepath.Dir(dir)) })
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"init", "-dir", "../testdata/inputs", "-wire", "cmd/scaffold/wire.go", "-root", "handler.Handler"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run() = %d, stderr = %s", code, stderr.String())
+	}
+
+	src, err := os.ReadFile(filepath.Join(dir, "wire.go"))
+	if err != nil {
+		t.Fatalf("failed to read wire.go: %v", err)
+	}
+	for _, want := range []string{
+		"//go:build wireinject",
+		"func InitializeHandler(ctx context.Context, dsn string, timeout time.Duration, client *http.Client) (*handler.Handler, func(), error) {",
+		"\t\trepo.NewDB,\n",
+		"wire.Bind(new(notify.Notifier), new(*notify.EmailNotifier)),",
+		"return nil, nil, nil",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("wire.go does not contain %q:\n%s", want, src)
+		}
+	}
+
+	typeCheck(t, dir)
+
+	// 既存のwire.goは上書きしない
+	code = Run([]string{"init", "-dir", "../testdata/inputs", "-wire", "cmd/scaffold/wire.go", "-root", "handler.Handler"}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("Run() on an existing file = %d, want 1", code)
+	}
+}
+
+func TestRun_Init_ForceRestoresOnFailure(t *testing.T) {
+	// モジュール内に作成する必要があるため、テスト後に削除する
+	dir := filepath.Join("..", "testdata", "inputs", "cmd", "restore")
+	t.Cleanup(func() { os.RemoveAll(filepath.Dir(dir)) })
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	original := []byte("//go:build wireinject\n\npackage main\n\n// original\n")
+	wirePath := filepath.Join(dir, "wire.go")
+	if err := os.WriteFile(wirePath, original, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// インターフェースはルートとして解析できないため、仮のwire.goの解析に失敗する
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"init", "-dir", "../testdata/inputs", "-wire", "cmd/restore/wire.go", "-root", "notify.Notifier", "-force"}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("Run() = %d, want 1 (stderr = %s)", code, stderr.String())
+	}
+
+	src, err := os.ReadFile(wirePath)
+	if err != nil {
+		t.Fatalf("wire.go was removed: %v", err)
+	}
+	if !bytes.Equal(src, original) {
+		t.Errorf("wire.go = %q, want the original %q", src, original)
+	}
+}
+
+func TestRun_Init_AmbiguousProvider(t *testing.T) {
+	// NewSvcとNewSvcAltのどちらかを黙って選ばず、仮のwire.goも残さない
+	dir := testutil.CopyFixture(t, "ambiguous")
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"init", "-dir", dir, "-wire", "cmd/wire.go", "-root", "app.Handler"}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("Run() = %d, want 1 (stderr = %s)", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "multiple providers found for *app.Svc") {
+		t.Errorf("stderr does not report the ambiguous provider:\n%s", stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cmd", "wire.go")); !os.IsNotExist(err) {
+		t.Errorf("wire.go was left behind: %v", err)
+	}
+}
+
+func TestRun_Init_ParamsAvoidPackages(t *testing.T) {
+	// NewStore(db *sql.DB)の引数名dbがimportするパッケージdbを隠さず、wireinjectタグ付きで型検査できる
+	dir := testutil.CopyFixture(t, "shadow")
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"init", "-dir", dir, "-wire", "cmd/store/wire.go", "-root", "db.Store"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run() = %d, stderr = %s", code, stderr.String())
+	}
+
+	src, err := os.ReadFile(filepath.Join(dir, "cmd", "store", "wire.go"))
+	if err != nil {
+		t.Fatalf("failed to read wire.go: %v", err)
+	}
+	for _, want := range []string{"package main\n", "func InitializeStore(db2 *sql.DB) *db.Store {"} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("wire.go does not contain %q:\n%s", want, src)
+		}
+	}
+	typeCheck(t, filepath.Join(dir, "cmd", "store"))
+}
+
+func TestRun_Init_ExistingPackage(t *testing.T) {
+	// 既にパッケージがあるディレクトリでは、そのパッケージ名で作成し、同じパッケージの型はimportしない
+	dir := testutil.CopyFixture(t, "shadow")
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"init", "-dir", dir, "-wire", "db/wire.go", "-root", "db.Store"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run() = %d, stderr = %s", code, stderr.String())
+	}
+
+	src, err := os.ReadFile(filepath.Join(dir, "db", "wire.go"))
+	if err != nil {
+		t.Fatalf("failed to read wire.go: %v", err)
+	}
+	for _, want := range []string{"package db\n", "*Store {", "\t\tNewStore,\n"} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("wire.go does not contain %q:\n%s", want, src)
+		}
+	}
+	typeCheck(t, filepath.Join(dir, "db"))
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"io"
+	"os"
+
+	"github.com/rmocchy/convinient_wire/lsp"
+)
+
+// stdin はlspコマンドがリクエストを読み込む入力（テストで差し替える）
+var stdin io.Reader = os.Stdin
+
+// runLSP は標準入出力でLanguage Serverを起動する
+func runLSP(args []string, stdout, stderr io.Writer) error {
+	var flags analysisFlags
+	fs := newFlagSet("lsp", stderr)
+	flags.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	server := lsp.NewServer(flags.pattern, flags.analyzerOptions()...)
+	return server.Serve(stdin, stdout)
+}
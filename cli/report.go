@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+	"github.com/rmocchy/convinient_wire/sarif"
+)
+
+// runReport は注入関数から辿った依存関係の問題をテキストまたはSARIFで報告する
+func runReport(args []string, stdout, stderr io.Writer) error {
+	var flags analysisFlags
+	fs := newFlagSet("report", stderr)
+	flags.register(fs)
+	format := fs.String("format", "text", "output format: text or sarif")
+	output := fs.String("o", "", "write the report to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "sarif" {
+		return fmt.Errorf("unknown format %q (want text or sarif)", *format)
+	}
+
+	injectors, err := flags.newAnalyzer().AnalyzeInjectors(flags.wireFilePath())
+	if err != nil {
+		return err
+	}
+
+	w := stdout
+	// closeOutput は-outputのファイルを閉じる（書き込みが失敗していればエラーを返す）
+	closeOutput := func() error { return nil }
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *output, err)
+		}
+		// 途中で失敗した場合も閉じる（成功した場合はcloseOutputで閉じたあとなので何もしない）
+		defer f.Close()
+		w = f
+		closeOutput = func() error {
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write %s: %w", *output, err)
+			}
+			return nil
+		}
+	}
+
+	// SARIFは取り込む側で判定するため、問題があっても成功として終わる
+	if *format == "sarif" {
+		log, err := sarif.NewLog(injectors, flags.dir)
+		if err != nil {
+			return err
+		}
+		if err := sarif.Write(w, log); err != nil {
+			return err
+		}
+		if err := closeOutput(); err != nil {
+			return err
+		}
+		if *output != "" {
+			fmt.Fprintf(stdout, "wrote %s\n", *output)
+		}
+		return nil
+	}
+
+	var errors int
+	for _, injector := range injectors {
+		for _, diagnostic := range injector.Diagnostics {
+			writeDiagnostic(w, injector.Name, diagnostic)
+			if diagnostic.Severity == app.SeverityError {
+				errors++
+			}
+		}
+	}
+	if err := closeOutput(); err != nil {
+		return err
+	}
+	if errors > 0 {
+		return fmt.Errorf("%d errors found", errors)
+	}
+	return nil
+}
+
+// writeDiagnostic は診断を位置・コード・メッセージの1行と候補の行で表示する
+func writeDiagnostic(w io.Writer, injector string, diagnostic app.Diagnostic) {
+	location := "-"
+	if diagnostic.Position.IsValid() {
+		location = diagnostic.Position.String()
+	}
+	message := diagnostic.Message
+	if len(diagnostic.Types) > 0 {
+		message = diagnostic.Types[0].Key() + ": " + message
+	}
+	fmt.Fprintf(w, "%s: %s %s %s: %s (injector %s)\n", location, diagnostic.Severity, diagnostic.Code, diagnostic.Code.Name(), message, injector)
+	for _, candidate := range diagnostic.Candidates {
+		fmt.Fprintf(w, "\tcandidate %s", candidate.Type.Key())
+		if candidate.Function != "" {
+			fmt.Fprintf(w, " via %s", candidate.Function)
+		}
+		if candidate.Position.IsValid() {
+			fmt.Fprintf(w, " at %s", candidate.Position)
+		}
+		fmt.Fprintln(w)
+	}
+}
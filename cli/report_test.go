@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rmocchy/convinient_wire/sarif"
+)
+
+func TestRun_Report(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"report", "-dir", "../testdata/wirecheck"}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("Run() = %d, want 1 (stderr = %s)", code, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		"service.go:13:2: warning CW002 ambiguous-implementation: example.com/wirecheck/notify.Notifier: multiple implementing types found (2) (injector InitializeApp)",
+		"\tcandidate example.com/wirecheck/notify.EmailNotifier at ",
+		"service.go:14:2: error CW001 no-implementation: example.com/wirecheck/cache.Cache: no implementing types found (injector InitializeApp)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRun_Report_SARIF(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "report.sarif")
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"report", "-dir", "../testdata/wirecheck", "-format", "sarif", "-o", output}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run() = %d, want 0 (stderr = %s)", code, stderr.String())
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarif.Log
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("invalid SARIF log: %v", err)
+	}
+
+	var ruleIDs []string
+	for _, result := range log.Runs[0].Results {
+		ruleIDs = append(ruleIDs, result.RuleID)
+		uris := []string{result.Locations[0].PhysicalLocation.ArtifactLocation.URI}
+		for _, related := range result.RelatedLocations {
+			uris = append(uris, related.PhysicalLocation.ArtifactLocation.URI)
+		}
+		// フィールドの宣言・候補の型・wire.goの注入関数を指す
+		want := map[string][]string{
+			"CW001": {"service/service.go", "wire.go"},
+			"CW002": {"service/service.go", "notify/notify.go", "notify/notify.go", "wire.go"},
+		}[result.RuleID]
+		if strings.Join(uris, " ") != strings.Join(want, " ") {
+			t.Errorf("%s locations = %v, want %v", result.RuleID, uris, want)
+		}
+	}
+	if strings.Join(ruleIDs, " ") != "CW002 CW001" {
+		t.Errorf("rule IDs = %v, want [CW002 CW001]", ruleIDs)
+	}
+}
+
+func TestRun_Report_UnknownFormat(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"report", "-dir", "../testdata/wirecheck", "-format", "xml"}, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), `unknown format "xml"`) {
+		t.Errorf("Run() = %d, stderr = %s, want 1 with unknown format", code, stderr.String())
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/generator"
+)
+
+// runSets はパッケージごとのプロバイダーセットを生成する
+func runSets(args []string, stdout, stderr io.Writer) error {
+	var flags analysisFlags
+	fs := newFlagSet("sets", stderr)
+	flags.register(fs)
+	dryRun := fs.Bool("dry-run", false, "print the generated files instead of writing them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	analyzer := flags.newAnalyzer()
+	injectors, err := analyzer.AnalyzeInjectors(flags.wireFilePath())
+	if err != nil {
+		return err
+	}
+
+	if err := checkConflicts(stderr, injectors); err != nil {
+		return err
+	}
+
+	sets := generator.CollectProviderSets(analyzer, injectors)
+
+	// 手書きのProviderSetと同じパッケージに書き出すと再宣言になる
+	pkgs, err := analyzer.SearchPackages()
+	if err != nil {
+		return err
+	}
+	if err := generator.CheckProviderSets(pkgs, sets); err != nil {
+		return err
+	}
+
+	if *dryRun {
+		for _, set := range sets {
+			src, err := set.Render()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "// %s/%s\n%s\n", set.Dir, generator.ProviderSetFileName, src)
+		}
+	} else {
+		written, err := generator.WriteProviderSets(sets)
+		for _, filePath := range written {
+			fmt.Fprintf(stdout, "wrote %s\n", filePath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// 注入関数から参照する形のwire.Buildを表示
+	for _, injector := range injectors {
+		fmt.Fprintf(stdout, "\n%s:\n\twire.Build(\n\t\t%s,\n\t)\n",
+			injector.Name, strings.Join(generator.BuildArgs(injector, sets), ",\n\t\t"))
+	}
+
+	return nil
+}
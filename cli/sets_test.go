@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun_Sets(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"sets", "-dir", "../testdata/inputs", "-dry-run"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run() = %d, stderr = %s", code, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		"package repo",
+		"var ProviderSet = wire.NewSet(",
+		"wire.Bind(new(Notifier), new(*EmailNotifier))",
+		"repo.ProviderSet,",
+		`wire.Struct(new(App), "*"),`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRun_Sets_DeclaredProviderSet(t *testing.T) {
+	// 手書きのProviderSetがあるパッケージには書き出さずにエラーにする
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"sets", "-dir", "../testdata/pkgsets"}, &stdout, &stderr)
+	if code == 0 {
+		t.Fatalf("Run() = 0, want an error; stdout = %s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "ProviderSet is already declared in example.com/pkgsets/db (db.go)") {
+		t.Errorf("stderr = %s", stderr.String())
+	}
+	if strings.Contains(stdout.String(), "wrote") {
+		t.Errorf("files were written:\n%s", stdout.String())
+	}
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rmocchy/convinient_wire/generator"
+)
+
+// runShare はwire.goの複数の注入関数で共通する提供関数を共有のプロバイダーセットにまとめる
+func runShare(args []string, stdout, stderr io.Writer) error {
+	var flags analysisFlags
+	fs := newFlagSet("share", stderr)
+	flags.register(fs)
+	dryRun := fs.Bool("dry-run", false, "print the rewritten wire.go instead of writing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	analyzer := flags.newAnalyzer()
+	wireFilePath := flags.wireFilePath()
+	injectors, err := analyzer.AnalyzeInjectors(wireFilePath)
+	if err != nil {
+		return err
+	}
+
+	if err := checkConflicts(stderr, injectors); err != nil {
+		return err
+	}
+
+	sets, err := generator.SplitInjectorSets(wireFilePath, injectors)
+	if err != nil {
+		return err
+	}
+	src, err := generator.RewriteWireFile(wireFilePath, sets)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		_, err := stdout.Write(src)
+		return err
+	}
+
+	if err := os.WriteFile(wireFilePath, src, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", wireFilePath, err)
+	}
+	fmt.Fprintf(stdout, "wrote %s (%d shared, %d injectors)\n", wireFilePath, len(sets.Shared), len(sets.Injectors))
+
+	return nil
+}
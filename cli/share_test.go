@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun_Share(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"share", "-dir", "../testdata/multi", "-dry-run"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run() = %d, stderr = %s", code, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		"var SharedSet = wire.NewSet(",
+		"\t\tSharedSet,\n\t\tapi.NewServer,",
+		"\t\tSharedSet,\n\t\tworker.NewWorker,",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/rmocchy/convinient_wire/generator"
+)
+
+// runVerify は生成済みのwire_gen.goが最新かどうかを確認する
+func runVerify(args []string, stdout, stderr io.Writer) error {
+	var flags analysisFlags
+	fs := newFlagSet("verify", stderr)
+	flags.register(fs)
+	wireGen := fs.String("wire-gen", "", "path to the committed wire_gen.go (default: next to wire.go)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	wireFilePath := flags.wireFilePath()
+	wireGenPath := *wireGen
+	if wireGenPath == "" {
+		wireGenPath = filepath.Join(filepath.Dir(wireFilePath), generator.WireGenFileName)
+	}
+
+	analyzer := flags.newAnalyzer()
+	injectors, err := analyzer.AnalyzeInjectors(wireFilePath)
+	if err != nil {
+		return err
+	}
+
+	if err := checkConflicts(stderr, injectors); err != nil {
+		return err
+	}
+
+	diffs, err := generator.VerifyWireGen(wireFilePath, wireGenPath, analyzer, injectors)
+	if err != nil {
+		return err
+	}
+
+	if len(diffs) == 0 {
+		fmt.Fprintf(stdout, "%s is up to date\n", wireGenPath)
+		return nil
+	}
+	for _, diff := range diffs {
+		fmt.Fprintln(stdout, diff)
+	}
+	return fmt.Errorf("%s is out of date (%d differences)", wireGenPath, len(diffs))
+}
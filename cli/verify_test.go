@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun_Verify(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"verify", "-dir", "../sample/basic"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Run() = %d, stderr = %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "is up to date") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/rmocchy/convinient_wire/watch"
+)
+
+// runWatch はGoファイルの変更を監視し、影響を受けた注入関数を解析し直して報告する
+func runWatch(args []string, stdout, stderr io.Writer) error {
+	var flags analysisFlags
+	fs := newFlagSet("watch", stderr)
+	flags.register(fs)
+	interval := fs.Duration("interval", watch.DefaultInterval, "how often to check files for changes")
+	fix := fs.Bool("fix", false, "rewrite wire.Build in wire.go and regenerate an existing wire_gen.go when they are out of date")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Ctrl-Cで監視を終了する
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	w := watch.New(flags.dir, flags.wireFilePath(), flags.pattern, stdout,
+		watch.WithInterval(*interval),
+		watch.WithFix(*fix),
+		watch.WithAnalyzerOptions(flags.analyzerOptions()...),
+	)
+	return w.Run(ctx)
+}
@@ -0,0 +1,15 @@
+// wirecheck はwire.goの依存関係の問題を報告する
+//
+//	GOFLAGS=-tags=wireinject go run github.com/rmocchy/convinient_wire/cmd/wirecheck ./...
+//	go vet -vettool=$(which wirecheck) -tags=wireinject ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/wirecheck"
+)
+
+func main() {
+	singlechecker.Main(wirecheck.Analyzer)
+}
@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+// AggregateFileName は集約構造体を出力するファイル名
+const AggregateFileName = "wire_struct.go"
+
+// Aggregate はパッケージパターンから見つけた型を1つにまとめる構造体を表す
+// wire.Struct(new(ControllerSet), "*") で組み立てる構造体を、型の追加・削除に合わせて生成し直すために使う
+type Aggregate struct {
+	PackageName string        // 構造体を出力するパッケージ名
+	PackagePath string        // 構造体を出力するパッケージパス
+	StructName  string        // 構造体名（例: ControllerSet）
+	Pattern     string        // 型を探したパッケージパターン（例: ./handler/...）
+	NameRule    string        // 型名の規則（例: *Handler）
+	Types       []app.TypeRef // フィールドにする型
+	Command     string        // go:generateで実行するコマンド（空の場合は出力しない）
+}
+
+// Render は集約構造体のGoソースを生成する
+func (a *Aggregate) Render() ([]byte, error) {
+	if len(a.Types) == 0 {
+		return nil, fmt.Errorf("no types matching %s found in %s", a.NameRule, a.Pattern)
+	}
+
+	imports := newImportSet(a.PackagePath)
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "// %s は %s の %s 型をまとめた構造体\n", a.StructName, a.Pattern, a.NameRule)
+	writeAggregateStruct(&body, imports, a.StructName, a.Types)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n\n", generatedHeader)
+	if a.Command != "" {
+		fmt.Fprintf(&buf, "//go:generate %s\n\n", a.Command)
+	}
+	fmt.Fprintf(&buf, "package %s\n\n", a.PackageName)
+	imports.write(&buf)
+	buf.Write(body.Bytes())
+
+	return format.Source(buf.Bytes())
+}
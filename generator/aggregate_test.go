@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+func TestAggregate_Render(t *testing.T) {
+	aggregate := &Aggregate{
+		PackageName: "main",
+		PackagePath: "example.com/app",
+		StructName:  "ControllerSet",
+		Pattern:     "./handler/...",
+		NameRule:    "*Handler",
+		Types: []app.TypeRef{
+			{TypeName: "AdminHandler", PackagePath: "example.com/app/handler", IsPointer: true},
+			{TypeName: "UserHandler", PackagePath: "example.com/app/handler", IsPointer: true},
+			{TypeName: "OrderHandler", PackagePath: "example.com/app/handler/order"},
+		},
+		Command: `go run github.com/rmocchy/convinient_wire aggregate -handlers ./handler/... -match "*Handler" -struct ControllerSet`,
+	}
+
+	src, err := aggregate.Render()
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	want := `// Code generated by convinient_wire. DO NOT EDIT.
+
+//go:generate go run github.com/rmocchy/convinient_wire aggregate -handlers ./handler/... -match "*Handler" -struct ControllerSet
+
+package main
+
+import (
+	"example.com/app/handler"
+	"example.com/app/handler/order"
+)
+
+// ControllerSet は ./handler/... の *Handler 型をまとめた構造体
+type ControllerSet struct {
+	adminHandler *handler.AdminHandler
+	userHandler  *handler.UserHandler
+	orderHandler order.OrderHandler
+}
+`
+	if string(src) != want {
+		t.Errorf("Render() =\n%s\nwant:\n%s", src, want)
+	}
+
+	// 型が見つからない場合はエラー
+	empty := &Aggregate{StructName: "ControllerSet", Pattern: "./handler/...", NameRule: "*Handler"}
+	if _, err := empty.Render(); err == nil {
+		t.Error("expected an error for no types, got nil")
+	}
+}
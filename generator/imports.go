@@ -0,0 +1,188 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+// generatedHeader は生成したファイルの先頭に付けるコメント
+const generatedHeader = "// Code generated by convinient_wire. DO NOT EDIT."
+
+// importSet は生成するファイルのimportを管理する
+type importSet struct {
+	selfPath string            // 生成するファイルのパッケージパス
+	names    map[string]string // importパス -> 参照に使う名前
+	packages map[string]string // importパス -> パッケージ名（解析結果から分かっているもの）
+	lookup   PackageLookup     // 解析結果にないパッケージの名前を引く（nilの場合は使わない）
+	used     map[string]bool   // 使用済みの名前
+}
+
+// newImportSet は指定されたパッケージのファイル用のimportSetを作成する
+func newImportSet(selfPath string) *importSet {
+	return &importSet{
+		selfPath: selfPath,
+		names:    make(map[string]string),
+		packages: make(map[string]string),
+		used:     make(map[string]bool),
+	}
+}
+
+// add はimportを追加し、参照に使う名前を返す（名前が衝突する場合は別名を付ける）
+func (s *importSet) add(importPath string) string {
+	return s.addNamed(importPath, s.packageName(importPath))
+}
+
+// addPackage はパッケージ名の分かっているimportを追加し、参照に使う名前を返す
+func (s *importSet) addPackage(importPath, pkgName string) string {
+	s.setPackageName(importPath, pkgName)
+	return s.add(importPath)
+}
+
+// setPackageName はimportパスのパッケージ名を記録する（空の場合は何もしない）
+func (s *importSet) setPackageName(importPath, pkgName string) {
+	if pkgName != "" {
+		s.packages[importPath] = pkgName
+	}
+}
+
+// packageName はimportパスのパッケージ名を返す
+// 解析結果にもlookupにもない場合はimportパスの末尾で代用する
+func (s *importSet) packageName(importPath string) string {
+	if name, ok := s.packages[importPath]; ok {
+		return name
+	}
+	if s.lookup != nil {
+		if name, _, ok := s.lookup.LookupPackage(importPath); ok && name != "" {
+			s.packages[importPath] = name
+			return name
+		}
+	}
+	return path.Base(importPath)
+}
+
+// learnPackages は注入関数の依存グラフに現れるパッケージの名前を記録する
+func (s *importSet) learnPackages(injectors []*app.InjectorInfo) {
+	learnType := func(ref app.TypeRef) {
+		s.setPackageName(ref.PackagePath, ref.PackageName)
+	}
+	for _, injector := range injectors {
+		if injector.Root != nil {
+			s.setPackageName(injector.Root.PackagePath, injector.Root.PackageName)
+		}
+		if injector.Graph == nil {
+			continue
+		}
+		for _, provider := range injector.Graph.Providers {
+			s.setPackageName(provider.Function.PackagePath, provider.Function.PackageName)
+			learnType(provider.Provides)
+			for _, arg := range provider.Args {
+				learnType(arg)
+			}
+		}
+		for _, binding := range injector.Graph.Bindings {
+			learnType(binding.Interface)
+			learnType(binding.Impl)
+		}
+		for _, input := range injector.Graph.Inputs {
+			learnType(input.Type)
+		}
+		for _, field := range injector.Graph.StructFields {
+			learnType(field.Type)
+		}
+		for _, fieldsOf := range injector.Graph.FieldsOf {
+			learnType(fieldsOf.Struct)
+			for _, field := range fieldsOf.Fields {
+				learnType(field.Type)
+			}
+		}
+		for _, value := range injector.Graph.Values {
+			learnType(value.Type)
+			s.setPackageName(value.ExprPackage, value.ExprPackageName)
+		}
+	}
+}
+
+// addNamed は参照に使う名前を指定してimportを追加する（名前が衝突する場合は別名を付ける）
+func (s *importSet) addNamed(importPath, base string) string {
+	if name, ok := s.names[importPath]; ok {
+		return name
+	}
+
+	name := base
+	for i := 2; s.used[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	s.names[importPath] = name
+	s.used[name] = true
+	return name
+}
+
+// qualify は型をこのファイルから参照する形の文字列にする
+func (s *importSet) qualify(ref app.TypeRef, pointer bool) string {
+	if ref.PackagePath == "" {
+		return ref.TypeString
+	}
+
+	name := ref.TypeName
+	if ref.PackagePath != s.selfPath {
+		name = s.addPackage(ref.PackagePath, ref.PackageName) + "." + name
+	}
+	if pointer {
+		name = "*" + name
+	}
+	return name
+}
+
+// write はimport宣言を書き出す
+func (s *importSet) write(buf *bytes.Buffer) {
+	if len(s.names) == 0 {
+		return
+	}
+
+	paths := make([]string, 0, len(s.names))
+	for importPath := range s.names {
+		paths = append(paths, importPath)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if isStdlib(paths[i]) != isStdlib(paths[j]) {
+			return isStdlib(paths[i])
+		}
+		return paths[i] < paths[j]
+	})
+
+	if len(paths) == 1 {
+		fmt.Fprintf(buf, "import %s\n\n", s.spec(paths[0]))
+		return
+	}
+
+	// 標準ライブラリとそれ以外を空行で分ける
+	buf.WriteString("import (\n")
+	for i, importPath := range paths {
+		if i > 0 && isStdlib(paths[i-1]) && !isStdlib(importPath) {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "\t%s\n", s.spec(importPath))
+	}
+	buf.WriteString(")\n\n")
+}
+
+// spec はimport宣言の1行分を返す（パッケージ名と異なる名前の場合は別名を付ける）
+func (s *importSet) spec(importPath string) string {
+	name := s.names[importPath]
+	if name == s.packageName(importPath) {
+		return strconv.Quote(importPath)
+	}
+	return name + " " + strconv.Quote(importPath)
+}
+
+// isStdlib はimportパスが標準ライブラリかどうかを判定する（先頭の要素にドットを含まない）
+func isStdlib(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
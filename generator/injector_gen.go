@@ -0,0 +1,543 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+// WireGenFileName は注入関数の実装を出力するファイル名
+const WireGenFileName = "wire_gen.go"
+
+// wireGenHeader はwire_gen.goの先頭に付けるコメントとビルド制約
+const wireGenHeader = generatedHeader + `
+
+//go:generate go run github.com/rmocchy/convinient_wire gen
+//go:build !wireinject
+// +build !wireinject
+`
+
+// GenerateWireGen はwire.goと注入関数の依存グラフから、wireコマンドを使わずにwire_gen.go相当のソースを生成する
+// 提供関数は依存グラフのトポロジカル順に呼び出し、errorのチェックとクリーンアップ関数の連鎖を行う
+func GenerateWireGen(wireFilePath string, lookup PackageLookup, injectors []*app.InjectorInfo) ([]byte, error) {
+	fset := token.NewFileSet()
+	wireFile, err := parser.ParseFile(fset, wireFilePath, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse wire file: %w", err)
+	}
+
+	byName := make(map[string]*app.InjectorInfo, len(injectors))
+	for _, injector := range injectors {
+		byName[injector.Name] = injector
+	}
+
+	pkgPath := ""
+	if len(injectors) > 0 {
+		pkgPath = injectors[0].PackagePath
+	}
+
+	imports := newImportSet(pkgPath)
+	imports.lookup = lookup
+	imports.learnPackages(injectors)
+	gen := &wireGenerator{
+		fset:        fset,
+		wireFile:    wireFile,
+		wireImports: wireFileImports(wireFile, imports.packageName),
+		imports:     imports,
+	}
+
+	// 注入関数とそれ以外の宣言に分ける
+	var injectorDecls []*ast.FuncDecl
+	var otherDecls []ast.Decl
+	for _, decl := range wireFile.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			continue
+		}
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv == nil {
+			if byName[funcDecl.Name.Name] != nil {
+				injectorDecls = append(injectorDecls, funcDecl)
+				continue
+			}
+			// 解析結果のない注入関数をそのまま写すと、wire.Buildを呼ぶだけの実装になってしまう
+			if findWireBuild(wireFile, funcDecl) != nil {
+				return nil, fmt.Errorf("injector %s was not analyzed", funcDecl.Name.Name)
+			}
+		}
+		otherDecls = append(otherDecls, decl)
+	}
+
+	var body bytes.Buffer
+	body.WriteString("// Injectors from wire.go:\n\n")
+	for _, funcDecl := range injectorDecls {
+		if err := gen.writeInjector(&body, funcDecl, byName[funcDecl.Name.Name]); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(otherDecls) > 0 {
+		body.WriteString("// wire.go:\n\n")
+		for _, decl := range otherDecls {
+			gen.useImportsIn(decl)
+			body.WriteString(gen.nodeString(decl))
+			body.WriteString("\n\n")
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(wireGenHeader)
+	fmt.Fprintf(&buf, "\npackage %s\n\n", wireFile.Name.Name)
+	gen.imports.write(&buf)
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w\n%s", err, buf.String())
+	}
+	return src, nil
+}
+
+// wireGenerator はwire_gen.goの生成に必要な状態を保持する
+type wireGenerator struct {
+	fset        *token.FileSet
+	wireFile    *ast.File
+	wireImports map[string]string // wire.goのimport名 -> importパス
+	imports     *importSet
+}
+
+// injectorParam は注入関数の引数を表す
+type injectorParam struct {
+	name     string // 引数名
+	typeExpr string // 型の式
+}
+
+// writeInjector は1つの注入関数の実装を書き出す
+func (g *wireGenerator) writeInjector(buf *bytes.Buffer, funcDecl *ast.FuncDecl, injector *app.InjectorInfo) error {
+	if injector.Graph == nil {
+		return fmt.Errorf("injector %s could not be analyzed: %s", injector.Name, injector.SkipReason())
+	}
+	graph := injector.Graph
+	// 名前のない型を複数の意味で必要としている場合は、どの値を渡すべきか決められない
+	if len(graph.Conflicts) > 0 {
+		return fmt.Errorf("injector %s: %s is needed with different meanings (run conflicts to introduce named types)",
+			injector.Name, graph.Conflicts[0].Type.TypeString)
+	}
+	// ポインタかどうかが異なる値を渡すとコンパイルできない
+	if len(graph.Unsatisfied) > 0 {
+		return fmt.Errorf("injector %s: %s", injector.Name, graph.Unsatisfied[0])
+	}
+	// 同じ型を返す提供関数が複数ある場合は、どれを呼び出すべきか決められない
+	if len(graph.Ambiguous) > 0 {
+		return fmt.Errorf("injector %s: %s", injector.Name, graph.Ambiguous[0])
+	}
+
+	// 変数名の衝突を避けるため、予約済みの名前を登録する
+	names := newNameSet()
+	names.reserve("err")
+
+	// 返り値
+	results, err := g.injectorResults(funcDecl, injector)
+	if err != nil {
+		return err
+	}
+	zero := zeroValues(results)
+
+	// 引数名がパッケージ名を隠さないように、使うパッケージを先にimportして名前を予約する
+	for _, input := range graph.Inputs {
+		g.imports.qualify(input.Type, input.Type.IsPointer)
+	}
+	for _, provider := range graph.Providers {
+		g.funcName(provider.Function, injector.PackagePath)
+	}
+	valueExprs := make([]string, 0, len(graph.Values))
+	for _, value := range graph.Values {
+		valueExprs = append(valueExprs, literalExpr(value, injector.PackagePath, g.importName))
+	}
+	for _, field := range funcDecl.Type.Params.List {
+		g.useImportsIn(field.Type)
+	}
+	for name := range g.imports.used {
+		names.reserve(name)
+	}
+
+	// 引数（wire.goで宣言されていればそれを優先する）
+	params, err := g.injectorParams(funcDecl, injector, names)
+	if err != nil {
+		return err
+	}
+
+	// 型のキー -> その値を保持する式と、値がポインタかどうか
+	values := make(map[string]string)
+	pointers := make(map[string]bool)
+	setValue := func(ref app.TypeRef, expr string) {
+		values[ref.Key()] = expr
+		pointers[ref.Key()] = ref.IsPointer
+	}
+	for i, input := range graph.Inputs {
+		setValue(input.Type, params[i].name)
+	}
+
+	if funcDecl.Doc != nil {
+		for _, comment := range funcDecl.Doc.List {
+			buf.WriteString(comment.Text + "\n")
+		}
+	}
+
+	paramList := make([]string, 0, len(params))
+	for _, param := range params {
+		paramList = append(paramList, param.name+" "+param.typeExpr)
+	}
+	fmt.Fprintf(buf, "func %s(%s) %s {\n", injector.Name, strings.Join(paramList, ", "), resultList(results))
+
+	// インターフェースの値は実装型の値を使う
+	bound := make(map[string]app.TypeRef)
+	for _, binding := range graph.Bindings {
+		bound[binding.Interface.Key()] = binding.Impl
+	}
+	valueOf := func(ref app.TypeRef) (string, error) {
+		want := ref
+		if impl, ok := bound[ref.Key()]; ok {
+			want = impl
+		}
+		value, ok := values[want.Key()]
+		if !ok {
+			return "", fmt.Errorf("injector %s: no value for %s", injector.Name, ref.TypeString)
+		}
+		// Configの値を*Configの引数に渡すことはできない（インターフェースはポインタかどうかを問わない）
+		if !want.IsInterface && pointers[want.Key()] != want.IsPointer {
+			return "", fmt.Errorf("injector %s: no provider found for %s", injector.Name, want.TypeString)
+		}
+		return value, nil
+	}
+
+	// 設定された値を変数に代入する（wire.Value, wire.InterfaceValue）
+	for i, value := range graph.Values {
+		varName := names.declare(app.VariableName(value.Type))
+		fmt.Fprintf(buf, "\t%s := %s\n", varName, valueExprs[i])
+		setValue(value.Type, varName)
+	}
+
+	// 提供関数をトポロジカル順に呼び出す
+	var cleanups []string
+	for _, provider := range graph.Providers {
+		args := make([]string, 0, len(provider.Args))
+		for _, arg := range provider.Args {
+			value, err := valueOf(arg)
+			if err != nil {
+				return err
+			}
+			args = append(args, value)
+		}
+
+		varName := names.declare(app.VariableName(provider.Provides))
+		lhs := []string{varName}
+		if provider.Function.HasCleanup {
+			cleanup := names.declare("cleanup")
+			lhs = append(lhs, cleanup)
+			cleanups = append(cleanups, cleanup)
+		}
+		if provider.Function.ReturnsError {
+			lhs = append(lhs, "err")
+		}
+
+		fmt.Fprintf(buf, "\t%s := %s(%s)\n", strings.Join(lhs, ", "), g.funcName(provider.Function, injector.PackagePath), strings.Join(args, ", "))
+		if provider.Function.ReturnsError {
+			buf.WriteString("\tif err != nil {\n")
+			// 既に確保したリソースは逆順に解放する
+			for i := len(cleanups) - 1; i >= 0; i-- {
+				if provider.Function.HasCleanup && i == len(cleanups)-1 {
+					continue
+				}
+				fmt.Fprintf(buf, "\t\t%s()\n", cleanups[i])
+			}
+			fmt.Fprintf(buf, "\t\treturn %s\n", strings.Join(append(zero[:len(zero)-1:len(zero)-1], "err"), ", "))
+			buf.WriteString("\t}\n")
+		}
+
+		setValue(provider.Provides, varName)
+
+		// wire.FieldsOfで供給するフィールドを取り出す
+		for _, fieldsOf := range graph.FieldsOf {
+			if fieldsOf.Struct.Key() != provider.Provides.Key() {
+				continue
+			}
+			for _, field := range fieldsOf.Fields {
+				fieldVar := names.declare(field.VariableName())
+				fmt.Fprintf(buf, "\t%s := %s.%s\n", fieldVar, varName, field.Name)
+				setValue(field.Type, fieldVar)
+			}
+		}
+	}
+
+	// ルートの値
+	rootRef := app.TypeRef{TypeName: injector.Root.StructName, PackagePath: injector.Root.PackagePath, PackageName: injector.Root.PackageName}
+	rootValue, ok := values[rootRef.Key()]
+	if ok && pointers[rootRef.Key()] != injector.RootIsPointer {
+		return fmt.Errorf("injector %s: no provider found for %s", injector.Name, results[0])
+	}
+	if !ok {
+		// wire.Structと同様にフィールドを埋めた構造体リテラルを作る
+		rootValue = names.declare(app.VariableName(rootRef))
+		literal := rootRef.Qualified(injector.PackagePath) + "{"
+		if injector.RootIsPointer {
+			literal = "&" + literal
+		}
+		fmt.Fprintf(buf, "\t%s := %s\n", rootValue, literal)
+		for _, field := range graph.StructFields {
+			value, err := valueOf(field.Type)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(buf, "\t\t%s: %s,\n", field.Name, value)
+		}
+		buf.WriteString("\t}\n")
+	}
+
+	// 返り値（クリーンアップ関数は確保した順と逆に呼び出す）
+	returns := []string{rootValue}
+	if returnsCleanup(results) {
+		var cleanupFunc strings.Builder
+		cleanupFunc.WriteString("func() {\n")
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			fmt.Fprintf(&cleanupFunc, "\t\t%s()\n", cleanups[i])
+		}
+		cleanupFunc.WriteString("\t}")
+		returns = append(returns, cleanupFunc.String())
+	}
+	if returnsError(results) {
+		returns = append(returns, "nil")
+	}
+	fmt.Fprintf(buf, "\treturn %s\n}\n\n", strings.Join(returns, ", "))
+
+	return nil
+}
+
+// injectorParams は注入関数の引数を決める
+// wire.goで引数が宣言されている場合は、宣言の順序と名前を使い、型が一致する入力に対応づける
+// 引数も返り値も宣言されていない場合に限り、入力から引数を組み立てる
+func (g *wireGenerator) injectorParams(funcDecl *ast.FuncDecl, injector *app.InjectorInfo, names *nameSet) ([]injectorParam, error) {
+	inputs := injector.Graph.Inputs
+
+	var declared []injectorParam
+	for _, field := range funcDecl.Type.Params.List {
+		g.useImportsIn(field.Type)
+		typeExpr := g.nodeString(field.Type)
+		for _, name := range field.Names {
+			declared = append(declared, injectorParam{name: name.Name, typeExpr: typeExpr})
+		}
+	}
+
+	params := make([]injectorParam, len(inputs))
+	if len(declared) == 0 && !declaresResults(funcDecl) {
+		for i, input := range inputs {
+			params[i] = injectorParam{
+				name:     names.declare(input.Name),
+				typeExpr: g.imports.qualify(input.Type, input.Type.IsPointer),
+			}
+		}
+		return params, nil
+	}
+
+	// 宣言された引数と入力を型で対応づける
+	used := make([]bool, len(declared))
+	for i, input := range inputs {
+		want := g.imports.qualify(input.Type, input.Type.IsPointer)
+		found := false
+		for j, param := range declared {
+			if !used[j] && param.typeExpr == want {
+				params[i] = param
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("injector %s: missing argument of type %s", injector.Name, want)
+		}
+	}
+	// パッケージ名と重なる引数名は付け替える
+	for i := range params {
+		params[i].name = names.declare(params[i].name)
+	}
+
+	return params, nil
+}
+
+// injectorResults は注入関数の返り値の型を決める
+// wire.goで返り値が宣言されていない場合に限り、解析結果から組み立てる
+// 宣言が依存グラフの要求するクリーンアップ関数やerrorを返さない場合はエラーを返す（wireと同じ）
+func (g *wireGenerator) injectorResults(funcDecl *ast.FuncDecl, injector *app.InjectorInfo) ([]string, error) {
+	if !declaresResults(funcDecl) {
+		root := app.TypeRef{
+			TypeName:    injector.Root.StructName,
+			PackagePath: injector.Root.PackagePath,
+			PackageName: injector.Root.PackageName,
+			IsPointer:   injector.RootIsPointer,
+		}
+		results := []string{g.imports.qualify(root, root.IsPointer)}
+		if injector.HasCleanup {
+			results = append(results, "func()")
+		}
+		if injector.ReturnsError {
+			results = append(results, "error")
+		}
+		return results, nil
+	}
+
+	var results []string
+	for _, field := range funcDecl.Type.Results.List {
+		g.useImportsIn(field.Type)
+		typeExpr := g.nodeString(field.Type)
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			results = append(results, typeExpr)
+		}
+	}
+
+	if injector.HasCleanup && !returnsCleanup(results) {
+		return nil, fmt.Errorf("%s declares %s but providers require cleanup", injector.Name, resultList(results))
+	}
+	if injector.ReturnsError && !returnsError(results) {
+		return nil, fmt.Errorf("%s declares %s but providers return an error", injector.Name, resultList(results))
+	}
+	return results, nil
+}
+
+// declaresResults はwire.goの注入関数が返り値を宣言しているかを判定する
+func declaresResults(funcDecl *ast.FuncDecl) bool {
+	return funcDecl.Type.Results != nil && len(funcDecl.Type.Results.List) > 0
+}
+
+// resultList は返り値の型を関数宣言の形式で返す（例: (*App, error)）
+func resultList(results []string) string {
+	list := strings.Join(results, ", ")
+	if len(results) > 1 {
+		list = "(" + list + ")"
+	}
+	return list
+}
+
+// returnsCleanup は返り値にクリーンアップ関数が含まれるかを判定する
+func returnsCleanup(results []string) bool {
+	return len(results) > 1 && results[1] == "func()"
+}
+
+// returnsError は返り値の最後がerrorかどうかを判定する
+func returnsError(results []string) bool {
+	return len(results) > 1 && results[len(results)-1] == "error"
+}
+
+// zeroValues は返り値の型に対応するゼロ値の式を返す
+func zeroValues(results []string) []string {
+	zero := make([]string, len(results))
+	for i, result := range results {
+		switch {
+		case strings.HasPrefix(result, "*"), strings.HasPrefix(result, "func"), result == "error":
+			zero[i] = "nil"
+		default:
+			zero[i] = result + "{}"
+		}
+	}
+	return zero
+}
+
+// funcName は提供関数を呼び出す式を返す
+func (g *wireGenerator) funcName(fn app.InitFunctionInfo, selfPath string) string {
+	if fn.PackagePath == selfPath {
+		return fn.Name
+	}
+	return g.importName(fn.PackagePath) + "." + fn.Name
+}
+
+// importName はパッケージをimportし、参照に使う名前を返す（wire.goと同じ名前を優先する）
+func (g *wireGenerator) importName(pkgPath string) string {
+	for name, importPath := range g.wireImports {
+		if importPath == pkgPath {
+			return g.imports.addNamed(pkgPath, name)
+		}
+	}
+	return g.imports.add(pkgPath)
+}
+
+// useImportsIn はASTで参照されているwire.goのimportを登録する
+func (g *wireGenerator) useImportsIn(node ast.Node) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			if importPath, ok := g.wireImports[ident.Name]; ok {
+				g.imports.addNamed(importPath, ident.Name)
+			}
+		}
+		return true
+	})
+}
+
+// nodeString はASTノードをソースコードの文字列にする
+func (g *wireGenerator) nodeString(node any) string {
+	var buf bytes.Buffer
+	if decl, ok := node.(ast.Decl); ok {
+		// 宣言はドキュメントコメントを含めて出力する
+		printer.Fprint(&buf, g.fset, &printer.CommentedNode{Node: decl, Comments: g.wireFile.Comments})
+		return buf.String()
+	}
+	printer.Fprint(&buf, g.fset, node)
+	return buf.String()
+}
+
+// wireFileImports はwire.goのimport名とimportパスの対応を返す
+// 名前を付けずにimportしたパッケージはpackageNameで引いたパッケージ名で参照する
+func wireFileImports(file *ast.File, packageName func(importPath string) string) map[string]string {
+	imports := make(map[string]string)
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := packageName(importPath)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+// nameSet は関数内で使用済みの識別子を管理する
+type nameSet struct {
+	used map[string]bool
+}
+
+// newNameSet は空のnameSetを作成する
+func newNameSet() *nameSet {
+	return &nameSet{used: make(map[string]bool)}
+}
+
+// reserve は識別子を使用済みとして登録する
+func (s *nameSet) reserve(names ...string) {
+	for _, name := range names {
+		s.used[name] = true
+	}
+}
+
+// declare は重複しない識別子を決めて登録する（例: cleanup, cleanup2）
+func (s *nameSet) declare(name string) string {
+	candidate := name
+	for i := 2; s.used[candidate] || token.IsKeyword(candidate); i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	s.used[candidate] = true
+	return candidate
+}
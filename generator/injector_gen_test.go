@@ -0,0 +1,284 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+func TestGenerateWireGen(t *testing.T) {
+	// wireコマンドが生成したwire_gen.goと、ヘッダー以外が一致する
+	dir := "../sample/basic"
+	analyzer, injectors := analyzeInjectors(t, dir)
+
+	src, err := GenerateWireGen(filepath.Join(dir, "wire.go"), analyzer, injectors)
+	if err != nil {
+		t.Fatalf("GenerateWireGen failed: %v", err)
+	}
+
+	want, err := os.ReadFile(filepath.Join(dir, "wire_gen.go"))
+	if err != nil {
+		t.Fatalf("failed to read wire_gen.go: %v", err)
+	}
+
+	got := string(src)
+	if !strings.HasPrefix(got, wireGenHeader) {
+		t.Errorf("generated source does not start with the header:\n%s", got)
+	}
+	if body(got) != body(string(want)) {
+		t.Errorf("generated source mismatch\ngot:\n%s\nwant:\n%s", body(got), body(string(want)))
+	}
+}
+
+func TestGenerateWireGen_Cleanup(t *testing.T) {
+	// 引数・クリーンアップ関数・wire.Bindを含む注入関数
+	dir := "../testdata/inputs"
+	analyzer, injectors := analyzeInjectors(t, dir)
+
+	src, err := GenerateWireGen(filepath.Join(dir, "wire.go"), analyzer, injectors)
+	if err != nil {
+		t.Fatalf("GenerateWireGen failed: %v", err)
+	}
+
+	want := `// InitializeApp はAppを初期化する
+func InitializeApp(ctx context.Context, dsn string, timeout time.Duration, client *http.Client) (*App, func(), error) {
+	db, cleanup, err := repo.NewDB(ctx, dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	userRepository := repo.NewUserRepository(db, timeout)
+	emailNotifier := notify.NewEmailNotifier()
+	handler2 := handler.NewHandler(userRepository, client, emailNotifier)
+	app := &App{
+		handler: handler2,
+	}
+	return app, func() {
+		cleanup()
+	}, nil
+}
+`
+	if !strings.Contains(string(src), want) {
+		t.Errorf("generated source does not contain the injector:\n%s", src)
+	}
+}
+
+func TestGenerateWireGen_SignatureMismatch(t *testing.T) {
+	// 宣言された返り値や引数が依存グラフの要求を満たさない場合は書き換えずにエラーにする
+	dir := "../testdata/inputs"
+	analyzer, injectors := analyzeInjectors(t, dir)
+	original, err := os.ReadFile(filepath.Join(dir, "wire.go"))
+	if err != nil {
+		t.Fatalf("failed to read wire.go: %v", err)
+	}
+	const signature = "InitializeApp(ctx context.Context, dsn string, timeout time.Duration, client *http.Client) (*App, func(), error) {"
+
+	tests := []struct {
+		name      string
+		signature string
+		want      string
+	}{
+		{
+			name:      "クリーンアップ関数がない",
+			signature: "InitializeApp(ctx context.Context, dsn string, timeout time.Duration, client *http.Client) (*App, error) {",
+			want:      "InitializeApp declares (*App, error) but providers require cleanup",
+		},
+		{
+			name:      "errorがない",
+			signature: "InitializeApp(ctx context.Context, dsn string, timeout time.Duration, client *http.Client) (*App, func()) {",
+			want:      "InitializeApp declares (*App, func()) but providers return an error",
+		},
+		{
+			name:      "引数がない",
+			signature: "InitializeApp() (*App, func(), error) {",
+			want:      "injector InitializeApp: missing argument of type context.Context",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wirePath := filepath.Join(t.TempDir(), "wire.go")
+			src := strings.Replace(string(original), signature, tt.signature, 1)
+			if err := os.WriteFile(wirePath, []byte(src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := GenerateWireGen(wirePath, analyzer, injectors)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("GenerateWireGen() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateWireGen_Conflicts(t *testing.T) {
+	// 同じstringをdsnとapiKeyの両方に渡すコードは生成しない
+	dir := "../testdata/conflicts"
+	analyzer, injectors := analyzeInjectors(t, dir)
+
+	_, err := GenerateWireGen(filepath.Join(dir, "wire.go"), analyzer, injectors)
+	if err == nil || !strings.Contains(err.Error(), "string is needed with different meanings") {
+		t.Errorf("GenerateWireGen() error = %v, want the conflict", err)
+	}
+}
+
+func TestGenerateWireGen_PointerMismatch(t *testing.T) {
+	// Configの値を*Configの引数に渡すコードは生成しない
+	dir := "../testdata/pointers"
+	analyzer, injectors := analyzeInjectors(t, dir)
+
+	_, err := GenerateWireGen(filepath.Join(dir, "wire.go"), analyzer, injectors)
+	if err == nil || !strings.Contains(err.Error(), "no provider found for *app.Config, needed by app.NewRepo") {
+		t.Errorf("GenerateWireGen() error = %v, want no provider for *app.Config", err)
+	}
+}
+
+func TestGenerateWireGen_AmbiguousProvider(t *testing.T) {
+	// NewSvcとNewSvcAltのどちらを呼び出すか決められないため生成しない
+	dir := "../testdata/ambiguous"
+	analyzer, injectors := analyzeInjectors(t, dir)
+
+	_, err := GenerateWireGen(filepath.Join(dir, "wire.go"), analyzer, injectors)
+	if err == nil || !strings.Contains(err.Error(), "multiple providers found for *app.Svc") {
+		t.Errorf("GenerateWireGen() error = %v, want multiple providers for *app.Svc", err)
+	}
+}
+
+func TestGenerateWireGen_MissingInjector(t *testing.T) {
+	// 解析結果のない注入関数をwire.Buildを呼ぶだけの実装として書き出さない
+	dir := "../testdata/multi"
+	analyzer, injectors := analyzeInjectors(t, dir)
+
+	_, err := GenerateWireGen(filepath.Join(dir, "wire.go"), analyzer, injectors[:1])
+	if err == nil || !strings.Contains(err.Error(), "injector InitializeWorker was not analyzed") {
+		t.Errorf("GenerateWireGen() error = %v, want InitializeWorker not analyzed", err)
+	}
+}
+
+func TestGenerateWireGen_PackageNames(t *testing.T) {
+	// importパスの末尾（go-mail、v2）ではなくパッケージ名で参照する
+	dir := "../testdata/pkgnames"
+	analyzer, injectors := analyzeInjectors(t, dir)
+
+	src, err := GenerateWireGen(filepath.Join(dir, "wire.go"), analyzer, injectors)
+	if err != nil {
+		t.Fatalf("GenerateWireGen failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"\t\"example.com/pkgnames/go-mail\"\n",
+		"\t\"example.com/pkgnames/store/v2\"\n",
+		"mail.NewMailer()",
+		"store.NewStore(",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated source does not contain %q:\n%s", want, src)
+		}
+	}
+
+	sets := CollectProviderSets(analyzer, injectors)
+	args := BuildArgs(injectors[0], sets)
+	want := []string{"mail.ProviderSet", "store.ProviderSet", "wire.Struct(new(App), \"*\")"}
+	if !slices.Equal(args, want) {
+		t.Errorf("BuildArgs() = %v, want %v", args, want)
+	}
+}
+
+func TestGenerateWireGen_ParamsAvoidImports(t *testing.T) {
+	// 宣言された引数名dbはimportするパッケージdbと重なるため付け替える
+	dir := "../testdata/shadow"
+	analyzer, injectors := analyzeInjectors(t, dir)
+
+	src, err := GenerateWireGen(filepath.Join(dir, "wire.go"), analyzer, injectors)
+	if err != nil {
+		t.Fatalf("GenerateWireGen failed: %v", err)
+	}
+
+	want := `func InitializeStoreFromSet(db2 *sql.DB) *db.Store {
+	store := db.NewStore(db2)
+	return store
+}
+`
+	if !strings.Contains(string(src), want) {
+		t.Errorf("generated source does not contain the renamed parameter:\n%s", src)
+	}
+}
+
+// body はpackage宣言以降のソースを返す
+func body(src string) string {
+	if i := strings.Index(src, "package "); i >= 0 {
+		return src[i:]
+	}
+	return src
+}
+
+func TestGenerateWireGen_FieldsOf(t *testing.T) {
+	// 構造体のフィールドを取り出してから提供関数に渡す
+	dir := "../testdata/fieldsof"
+	analyzer, injectors := analyzeInjectors(t, dir)
+
+	src, err := GenerateWireGen(filepath.Join(dir, "wire.go"), analyzer, injectors)
+	if err != nil {
+		t.Fatalf("GenerateWireGen failed: %v", err)
+	}
+
+	want := `func InitializeApp() *App {
+	config2 := config.NewConfig()
+	dsn := config2.DSN
+	maxPoolSize := config2.MaxPoolSize
+	db2 := db.NewDB(dsn, maxPoolSize)
+	repository := repo.NewRepository(db2, config2)
+`
+	if !strings.Contains(string(src), want) {
+		t.Errorf("generated source does not contain the field accesses:\n%s", src)
+	}
+
+	sets := CollectProviderSets(analyzer, injectors)
+	setSrc, err := sets[0].Render()
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(string(setSrc), `wire.FieldsOf(new(*Config), "DSN", "MaxPoolSize"),`) {
+		t.Errorf("provider set does not contain wire.FieldsOf:\n%s", setSrc)
+	}
+}
+
+func TestGenerateWireGen_Values(t *testing.T) {
+	// 設定された値は変数に代入してから提供関数に渡す
+	dir := "../testdata/values"
+	var values []app.Value
+	for _, spec := range []string{"*net/http.Client=net/http.DefaultClient", "io.Writer=os.Stdout"} {
+		value, err := app.ParseValue(spec)
+		if err != nil {
+			t.Fatalf("ParseValue failed: %v", err)
+		}
+		values = append(values, value)
+	}
+	analyzer := app.NewWireAnalyzer(dir, "./...", app.WithValues(values...))
+	injectors, err := analyzer.AnalyzeInjectors(filepath.Join(dir, "wire.go"))
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+
+	src, err := GenerateWireGen(filepath.Join(dir, "wire.go"), analyzer, injectors)
+	if err != nil {
+		t.Fatalf("GenerateWireGen failed: %v", err)
+	}
+	want := `func InitializeApp() *App {
+	writer := os.Stdout
+	client := http.DefaultClient
+	logger2 := logger.NewLogger(writer)
+	client2 := api.NewClient(client)
+`
+	if !strings.Contains(string(src), want) {
+		t.Errorf("generated source does not contain the values:\n%s", src)
+	}
+
+	args := BuildArgs(injectors[0], CollectProviderSets(analyzer, injectors))
+	for _, want := range []string{"wire.InterfaceValue(new(io.Writer), os.Stdout)", "wire.Value(http.DefaultClient)"} {
+		if !slices.Contains(args, want) {
+			t.Errorf("BuildArgs() = %v, want %s", args, want)
+		}
+	}
+}
@@ -0,0 +1,621 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+	"golang.org/x/tools/go/packages"
+)
+
+// sourceEdit はソースの置き換えを表す（start == endの場合は挿入）
+type sourceEdit struct {
+	start, end int
+	text       string
+}
+
+// FixTypeConflicts は衝突している引数やフィールドを提案された名前付きの型に置き換える変更を作る
+// 型宣言を提供関数や構造体の前に追加し、型を置き換え、置き換えた値の使用箇所は元の型に変換する
+// 元の型は置き換えるファイルのimport名で書く（例: map[string]*foo.Bar）
+// 戻り値は変更するファイルのパスと変更後のソースの対応
+func FixTypeConflicts(pkgs []*packages.Package, conflicts []app.TypeConflict) (map[string][]byte, error) {
+	byPath := make(map[string]*packages.Package, len(pkgs))
+	for _, pkg := range pkgs {
+		byPath[pkg.PkgPath] = pkg
+	}
+
+	edits := make(map[string][]sourceEdit)
+	declared := make(map[string]bool) // "パッケージパス.型名"
+	replaced := make(map[string]bool) // "パッケージパス.関数名#引数の位置" または "パッケージパス.構造体名{フィールド名}"
+
+	for _, conflict := range conflicts {
+		for _, suggestion := range conflict.Suggestions {
+			pkg, ok := byPath[suggestion.PackagePath]
+			if !ok {
+				return nil, fmt.Errorf("package %s not found", suggestion.PackagePath)
+			}
+
+			// 既に同じ名前の宣言がある場合は、同じ型であれば再利用する
+			needDecl := !declared[suggestion.PackagePath+"."+suggestion.Name]
+			existing := pkg.Types.Scope().Lookup(suggestion.Name)
+			if existing != nil {
+				if _, ok := existing.(*types.TypeName); !ok {
+					return nil, fmt.Errorf("%s.%s is already declared", pkg.Name, suggestion.Name)
+				}
+				needDecl = false
+			}
+			declared[suggestion.PackagePath+"."+suggestion.Name] = true
+
+			for _, use := range suggestion.Uses {
+				useKey := fmt.Sprintf("%s.%s#%d", use.Function.PackagePath, use.Function.Name, use.Index)
+				if use.IsField() {
+					useKey = fmt.Sprintf("%s.%s{%s}", use.Struct.PackagePath, use.Struct.TypeName, use.Param)
+				}
+				if replaced[useKey] {
+					continue
+				}
+				replaced[useKey] = true
+
+				var replacement typeReplacement
+				var err error
+				if use.IsField() {
+					replacement, err = replaceFieldType(pkgs, pkg, use, suggestion)
+				} else {
+					replacement, err = replaceParamType(pkgs, pkg, use, suggestion)
+				}
+				if err != nil {
+					return nil, err
+				}
+				if existing != nil && !types.Identical(existing.Type().Underlying(), replacement.underlying) {
+					return nil, fmt.Errorf("%s.%s is already declared", pkg.Name, suggestion.Name)
+				}
+				if needDecl {
+					// 型宣言は最初に置き換える提供関数や構造体の前に置く
+					replacement.edits[replacement.filename] = append(replacement.edits[replacement.filename], replacement.declaration)
+					needDecl = false
+				}
+				for filename, fileEdits := range replacement.edits {
+					edits[filename] = append(edits[filename], fileEdits...)
+				}
+			}
+		}
+	}
+
+	result := make(map[string][]byte, len(edits))
+	for filename, fileEdits := range edits {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+		formatted, err := format.Source(applyEdits(src, fileEdits))
+		if err != nil {
+			return nil, fmt.Errorf("failed to format %s: %w", filename, err)
+		}
+		result[filename] = formatted
+	}
+
+	return result, nil
+}
+
+// typeReplacement は1つの引数やフィールドの型を名前付きの型に置き換える変更
+type typeReplacement struct {
+	filename    string                  // 引数やフィールドを宣言しているファイル
+	edits       map[string][]sourceEdit // ファイルごとの置き換え
+	declaration sourceEdit              // 型宣言の挿入（filenameのファイルに適用する）
+	underlying  types.Type              // 置き換える前の型
+}
+
+// replaceParamType は提供関数の引数の型を置き換え、関数内の使用箇所を元の型に変換する
+// 読み込んだパッケージ内の呼び出しは、置き換えた引数に渡す値を名前付きの型に変換する
+func replaceParamType(pkgs []*packages.Package, pkg *packages.Package, use app.TypeUse, suggestion app.NamedTypeSuggestion) (typeReplacement, error) {
+	funcDecl, file := findFuncDecl(pkg, use.Function.Name)
+	if funcDecl == nil {
+		return typeReplacement{}, fmt.Errorf("function %s not found in %s", use.Function.Name, pkg.PkgPath)
+	}
+
+	// 引数の位置からフィールドを探す
+	var field *ast.Field
+	index := 0
+	for _, f := range funcDecl.Type.Params.List {
+		n := max(len(f.Names), 1)
+		if use.Index < index+n {
+			field = f
+			break
+		}
+		index += n
+	}
+	if field == nil {
+		return typeReplacement{}, fmt.Errorf("parameter %d of %s not found", use.Index, use.Function.Name)
+	}
+	if len(field.Names) != 1 {
+		return typeReplacement{}, fmt.Errorf("%s: parameter %s shares its type with other parameters; split it before fixing", use.Function.Name, use.Param)
+	}
+	param := pkg.TypesInfo.Defs[field.Names[0]]
+	if param == nil {
+		return typeReplacement{}, fmt.Errorf("%s: parameter %s has no type information", use.Function.Name, use.Param)
+	}
+
+	fn := pkg.TypesInfo.Defs[funcDecl.Name]
+	callers, err := callerEdits(pkgs, pkg, fn, use.Index, suggestion.Name)
+	if err != nil {
+		return typeReplacement{}, err
+	}
+
+	fset := pkg.Fset
+	filename := fset.Position(file.Pos()).Filename
+	underlying := types.TypeString(param.Type(), fileQualifier(pkg, file))
+	edits := []sourceEdit{{
+		start: fset.Position(field.Type.Pos()).Offset,
+		end:   fset.Position(field.Type.End()).Offset,
+		text:  suggestion.Name,
+	}}
+
+	// 関数内では元の型として使えるように変換する（例: dsn -> string(dsn)）
+	// 代入先や&のオペランドは変換すると代入やアドレスの取得ができなくなるため、値として使う箇所だけを変換する
+	conversion := conversionType(underlying)
+	if funcDecl.Body != nil {
+		addressed := addressedIdents(funcDecl.Body)
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if ok && !addressed[ident] && pkg.TypesInfo.Uses[ident] == param {
+				edits = append(edits, sourceEdit{
+					start: fset.Position(ident.Pos()).Offset,
+					end:   fset.Position(ident.End()).Offset,
+					text:  conversion + "(" + ident.Name + ")",
+				})
+			}
+			return true
+		})
+	}
+
+	pos := funcDecl.Pos()
+	if funcDecl.Doc != nil {
+		pos = funcDecl.Doc.Pos()
+	}
+	callers[filename] = append(callers[filename], edits...)
+	return typeReplacement{
+		filename:    filename,
+		edits:       callers,
+		declaration: declarationEdit(fset.Position(pos).Offset, use.Function.Name, use.Param, suggestion.Name, underlying),
+		underlying:  param.Type(),
+	}, nil
+}
+
+// replaceFieldType はwire.Structで埋める構造体のフィールドの型を置き換え、読み込んだパッケージ内の使用箇所を変換する
+// フィールドを値として読む箇所は元の型に変換し、構造体リテラルで与える値は名前付きの型に変換する
+func replaceFieldType(pkgs []*packages.Package, pkg *packages.Package, use app.TypeUse, suggestion app.NamedTypeSuggestion) (typeReplacement, error) {
+	genDecl, spec, file := findTypeSpec(pkg, use.Struct.TypeName)
+	if spec == nil {
+		return typeReplacement{}, fmt.Errorf("type %s not found in %s", use.Struct.TypeName, pkg.PkgPath)
+	}
+	structType, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return typeReplacement{}, fmt.Errorf("%s is not a struct", use.Struct.TypeName)
+	}
+
+	var field *ast.Field
+	for _, f := range structType.Fields.List {
+		for _, name := range f.Names {
+			if name.Name == use.Param {
+				field = f
+			}
+		}
+	}
+	if field == nil {
+		return typeReplacement{}, fmt.Errorf("field %s of %s not found", use.Param, use.Struct.TypeName)
+	}
+	if len(field.Names) != 1 {
+		return typeReplacement{}, fmt.Errorf("%s: field %s shares its type with other fields; split it before fixing", use.Struct.TypeName, use.Param)
+	}
+	fieldVar := pkg.TypesInfo.Defs[field.Names[0]]
+	if fieldVar == nil {
+		return typeReplacement{}, fmt.Errorf("%s: field %s has no type information", use.Struct.TypeName, use.Param)
+	}
+
+	fset := pkg.Fset
+	filename := fset.Position(file.Pos()).Filename
+	edits := map[string][]sourceEdit{
+		filename: {{
+			start: fset.Position(field.Type.Pos()).Offset,
+			end:   fset.Position(field.Type.End()).Offset,
+			text:  suggestion.Name,
+		}},
+	}
+
+	for _, p := range pkgs {
+		for _, f := range p.Syntax {
+			name := fset.Position(f.Pos()).Filename
+			qualifier := fileQualifier(p, f)
+			conversion := conversionType(types.TypeString(fieldVar.Type(), qualifier))
+			named := suggestion.Name
+			if q := qualifier(pkg.Types); q != "" {
+				named = q + "." + suggestion.Name
+			}
+			wrap := func(expr ast.Expr, conv string) {
+				edits[name] = append(edits[name],
+					sourceEdit{start: fset.Position(expr.Pos()).Offset, end: fset.Position(expr.Pos()).Offset, text: conv + "("},
+					sourceEdit{start: fset.Position(expr.End()).Offset, end: fset.Position(expr.End()).Offset, text: ")"},
+				)
+			}
+			addressed := addressedSelectors(f)
+			ast.Inspect(f, func(n ast.Node) bool {
+				switch e := n.(type) {
+				case *ast.SelectorExpr:
+					// 例: cfg.DSN -> string(cfg.DSN)
+					if !addressed[e] && p.TypesInfo.Uses[e.Sel] == fieldVar {
+						wrap(e, conversion)
+					}
+				case *ast.KeyValueExpr:
+					// 例: Config{DSN: dsn} -> Config{DSN: DSN(dsn)}
+					if key, ok := e.Key.(*ast.Ident); ok && p.TypesInfo.Uses[key] == fieldVar {
+						wrap(e.Value, named)
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	pos := genDecl.Pos()
+	if genDecl.Doc != nil {
+		pos = genDecl.Doc.Pos()
+	}
+	underlying := types.TypeString(fieldVar.Type(), fileQualifier(pkg, file))
+	return typeReplacement{
+		filename:    filename,
+		edits:       edits,
+		declaration: declarationEdit(fset.Position(pos).Offset, use.Struct.TypeName, use.Param, suggestion.Name, underlying),
+		underlying:  fieldVar.Type(),
+	}, nil
+}
+
+// callerEdits は読み込んだパッケージ内の提供関数の呼び出しで、index番目の引数に渡す値を名前付きの型に変換する（例: db.NewDB(dsn) -> db.NewDB(db.DSN(dsn))）
+// 呼び出し以外で関数を値として使う箇所（wireの関数の引数を除く）と、読み込んでいないテストファイルの呼び出しは変換できないため、
+// その位置を列挙してエラーを返す（テストファイルでリテラルを渡す呼び出しは変換しなくてもコンパイルできる）
+func callerEdits(pkgs []*packages.Package, pkg *packages.Package, fn types.Object, index int, typeName string) (map[string][]sourceEdit, error) {
+	edits := make(map[string][]sourceEdit)
+	var unsupported []string
+	fset := pkg.Fset
+	for _, p := range pkgs {
+		for _, f := range p.Syntax {
+			name := fset.Position(f.Pos()).Filename
+			named := typeName
+			if q := fileQualifier(p, f)(pkg.Types); q != "" {
+				named = q + "." + typeName
+			}
+
+			handled := make(map[*ast.Ident]bool)
+			var refs []*ast.Ident
+			ast.Inspect(f, func(n ast.Node) bool {
+				switch e := n.(type) {
+				case *ast.CallExpr:
+					callee := calleeIdent(e.Fun)
+					if callee == nil {
+						return true
+					}
+					switch obj := p.TypesInfo.Uses[callee]; {
+					case obj == fn:
+						handled[callee] = true
+						if index < len(e.Args) && !e.Ellipsis.IsValid() {
+							arg := e.Args[index]
+							edits[name] = append(edits[name],
+								sourceEdit{start: fset.Position(arg.Pos()).Offset, end: fset.Position(arg.Pos()).Offset, text: named + "("},
+								sourceEdit{start: fset.Position(arg.End()).Offset, end: fset.Position(arg.End()).Offset, text: ")"},
+							)
+						}
+					case obj != nil && obj.Pkg() != nil && obj.Pkg().Path() == wireImportPath:
+						// wire.NewSetやwire.Buildに渡す提供関数は呼び出さないため変換は不要
+						for _, arg := range e.Args {
+							if ident := calleeIdent(arg); ident != nil {
+								handled[ident] = true
+							}
+						}
+					}
+				case *ast.Ident:
+					if p.TypesInfo.Uses[e] == fn {
+						refs = append(refs, e)
+					}
+				}
+				return true
+			})
+			for _, ref := range refs {
+				if !handled[ref] {
+					unsupported = append(unsupported, fset.Position(ref.Pos()).String())
+				}
+			}
+		}
+	}
+
+	testCalls, err := testFileCalls(pkgs, pkg, fn.Name(), index)
+	if err != nil {
+		return nil, err
+	}
+	unsupported = append(unsupported, testCalls...)
+	if len(unsupported) > 0 {
+		return nil, fmt.Errorf("%s.%s is used where its argument cannot be converted to %s; convert it by hand at:\n\t%s",
+			pkg.Name, fn.Name(), typeName, strings.Join(unsupported, "\n\t"))
+	}
+	return edits, nil
+}
+
+// testFileCalls は読み込んだパッケージのディレクトリのテストファイルから、提供関数のindex番目の引数にリテラル以外を渡す呼び出しや
+// 関数を値として使う箇所の位置を返す（テストファイルは型情報がないため、importと名前で照合する）
+func testFileCalls(pkgs []*packages.Package, pkg *packages.Package, funcName string, index int) ([]string, error) {
+	dirs := make(map[string]bool)
+	for _, p := range pkgs {
+		if len(p.GoFiles) > 0 {
+			dirs[filepath.Dir(p.GoFiles[0])] = true
+		}
+	}
+	pkgDir := ""
+	if len(pkg.GoFiles) > 0 {
+		pkgDir = filepath.Dir(pkg.GoFiles[0])
+	}
+
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Strings(sorted)
+
+	var sites []string
+	fset := token.NewFileSet()
+	for _, dir := range sorted {
+		filenames, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+		if err != nil {
+			return nil, err
+		}
+		for _, filename := range filenames {
+			f, err := parser.ParseFile(fset, filename, nil, parser.SkipObjectResolution)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+			}
+
+			// 同じパッケージのテストは修飾なし、外部テストパッケージや他のパッケージはimport名で修飾して呼び出す
+			qualifier, found := "", dir == pkgDir && f.Name.Name == pkg.Name
+			for _, imp := range f.Imports {
+				if path, _ := strconv.Unquote(imp.Path.Value); path == pkg.PkgPath {
+					qualifier, found = pkg.Name, true
+					if imp.Name != nil {
+						qualifier = imp.Name.Name
+					}
+				}
+			}
+			if !found {
+				continue
+			}
+			matches := func(expr ast.Expr) bool {
+				switch e := expr.(type) {
+				case *ast.Ident:
+					return qualifier == "" && e.Name == funcName
+				case *ast.SelectorExpr:
+					x, ok := e.X.(*ast.Ident)
+					return ok && qualifier != "" && x.Name == qualifier && e.Sel.Name == funcName
+				}
+				return false
+			}
+
+			ast.Inspect(f, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok && matches(call.Fun) {
+					if index < len(call.Args) {
+						if _, literal := call.Args[index].(*ast.BasicLit); !literal {
+							sites = append(sites, fset.Position(call.Args[index].Pos()).String())
+						}
+					}
+					for _, arg := range call.Args {
+						ast.Inspect(arg, func(n ast.Node) bool {
+							if expr, ok := n.(ast.Expr); ok && matches(expr) {
+								sites = append(sites, fset.Position(expr.Pos()).String())
+								return false
+							}
+							return true
+						})
+					}
+					return false
+				}
+				if expr, ok := n.(ast.Expr); ok && matches(expr) {
+					sites = append(sites, fset.Position(expr.Pos()).String())
+					return false
+				}
+				return true
+			})
+		}
+	}
+	return sites, nil
+}
+
+// calleeIdent は関数を表す式（fやpkg.f）の識別子を返す
+func calleeIdent(expr ast.Expr) *ast.Ident {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return e
+	case *ast.SelectorExpr:
+		return e.Sel
+	}
+	return nil
+}
+
+// conversionType は型変換に使う型の式を返す（関数型・ポインタ型・受信専用チャネル型は括弧で囲む）
+func conversionType(typeExpr string) string {
+	if strings.HasPrefix(typeExpr, "func") || strings.HasPrefix(typeExpr, "*") || strings.HasPrefix(typeExpr, "<-") {
+		return "(" + typeExpr + ")"
+	}
+	return typeExpr
+}
+
+// fileQualifier はファイルのimport名で型を修飾するQualifierを返す（同じパッケージの型は修飾しない）
+func fileQualifier(pkg *packages.Package, file *ast.File) types.Qualifier {
+	names := make(map[string]string)
+	for _, imp := range file.Imports {
+		obj := pkg.TypesInfo.Implicits[imp]
+		if imp.Name != nil {
+			obj = pkg.TypesInfo.Defs[imp.Name]
+		}
+		if pkgName, ok := obj.(*types.PkgName); ok {
+			names[pkgName.Imported().Path()] = pkgName.Name()
+		}
+	}
+	return func(other *types.Package) string {
+		if other.Path() == pkg.PkgPath {
+			return ""
+		}
+		if name, ok := names[other.Path()]; ok {
+			return name
+		}
+		return other.Name()
+	}
+}
+
+// addressedIdents は代入先・インクリメント・&のオペランドとして使われる変数の識別子を集める
+// p.f = x や p[i]++ のように変数の一部を変更する場合も、その変数を含める
+func addressedIdents(body *ast.BlockStmt) map[*ast.Ident]bool {
+	addressed := make(map[*ast.Ident]bool)
+	add := func(expr ast.Expr) {
+		if ident := rootIdent(expr); ident != nil {
+			addressed[ident] = true
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range stmt.Lhs {
+				add(lhs)
+			}
+		case *ast.IncDecStmt:
+			add(stmt.X)
+		case *ast.RangeStmt:
+			if stmt.Tok == token.ASSIGN {
+				add(stmt.Key)
+				add(stmt.Value)
+			}
+		case *ast.UnaryExpr:
+			if stmt.Op == token.AND {
+				add(stmt.X)
+			}
+		}
+		return true
+	})
+	return addressed
+}
+
+// addressedSelectors は代入先・インクリメント・&のオペランドとして使われるセレクタを集める
+// p.f.g = x のように途中のセレクタを通して変更する場合も、途中のセレクタを含める
+func addressedSelectors(file *ast.File) map[*ast.SelectorExpr]bool {
+	addressed := make(map[*ast.SelectorExpr]bool)
+	add := func(expr ast.Expr) {
+		for expr != nil {
+			switch e := expr.(type) {
+			case *ast.SelectorExpr:
+				addressed[e] = true
+				expr = e.X
+			case *ast.IndexExpr:
+				expr = e.X
+			case *ast.ParenExpr:
+				expr = e.X
+			default:
+				return
+			}
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range stmt.Lhs {
+				add(lhs)
+			}
+		case *ast.IncDecStmt:
+			add(stmt.X)
+		case *ast.RangeStmt:
+			if stmt.Tok == token.ASSIGN {
+				add(stmt.Key)
+				add(stmt.Value)
+			}
+		case *ast.UnaryExpr:
+			if stmt.Op == token.AND {
+				add(stmt.X)
+			}
+		}
+		return true
+	})
+	return addressed
+}
+
+// rootIdent はセレクタ・インデックス・間接参照を辿った先の変数の識別子を返す
+func rootIdent(expr ast.Expr) *ast.Ident {
+	for {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return e
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		default:
+			return nil
+		}
+	}
+}
+
+// declarationEdit はoffsetの位置（提供関数や構造体の宣言の前）に名前付きの型の宣言を挿入する
+func declarationEdit(offset int, owner, param, name, underlying string) sourceEdit {
+	text := fmt.Sprintf("// %s は %s の %s を同じ型の他の値と区別するための型\ntype %s %s\n\n",
+		name, owner, param, name, underlying)
+	return sourceEdit{start: offset, end: offset, text: text}
+}
+
+// findFuncDecl はパッケージからレシーバのない関数の宣言を探す
+func findFuncDecl(pkg *packages.Package, name string) (*ast.FuncDecl, *ast.File) {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv == nil && funcDecl.Name.Name == name {
+				return funcDecl, file
+			}
+		}
+	}
+	return nil, nil
+}
+
+// findTypeSpec はパッケージから型の宣言を探す
+func findTypeSpec(pkg *packages.Package, name string) (*ast.GenDecl, *ast.TypeSpec, *ast.File) {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok && typeSpec.Name.Name == name {
+					return genDecl, typeSpec, file
+				}
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+// applyEdits はソースに置き換えを適用する（置き換えは互いに重ならないこと）
+func applyEdits(src []byte, edits []sourceEdit) []byte {
+	// 同じ位置への挿入は追加した順に並べる
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var out []byte
+	last := 0
+	for _, edit := range edits {
+		out = append(out, src[last:edit.start]...)
+		out = append(out, edit.text...)
+		last = edit.end
+	}
+	return append(out, src[last:]...)
+}
@@ -0,0 +1,187 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rmocchy/convinient_wire/internal/testutil"
+)
+
+func TestFixTypeConflicts(t *testing.T) {
+	analyzer, injectors := analyzeInjectors(t, "../testdata/conflicts")
+	pkgs, err := analyzer.SearchPackages()
+	if err != nil {
+		t.Fatalf("SearchPackages failed: %v", err)
+	}
+
+	files, err := FixTypeConflicts(pkgs, injectors[0].Graph.Conflicts)
+	if err != nil {
+		t.Fatalf("FixTypeConflicts failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+
+	var dbSrc, clientSrc string
+	for filename, src := range files {
+		switch filepath.Base(filepath.Dir(filename)) {
+		case "db":
+			dbSrc = string(src)
+		case "client":
+			clientSrc = string(src)
+		}
+	}
+
+	want := `// DSN は NewDB の dsn を同じ型の他の値と区別するための型
+type DSN string
+
+// NewDB はDBを作成する
+func NewDB(dsn DSN) *DB {
+	fmt.Println("connecting to", string(dsn))
+	return &DB{dsn: string(dsn)}
+}
+`
+	if !strings.HasSuffix(dbSrc, want) {
+		t.Errorf("db.go =\n%s\nwant suffix:\n%s", dbSrc, want)
+	}
+
+	// 代入先の引数は変換せず、値として使う箇所だけを変換する
+	want = `func NewClient(apiKey APIKey) *Client {
+	if string(apiKey) == "" {
+		apiKey = "anonymous"
+	}
+	return &Client{apiKey: string(apiKey)}
+}
+`
+	if !strings.HasSuffix(clientSrc, want) {
+		t.Errorf("client.go =\n%s\nwant suffix:\n%s", clientSrc, want)
+	}
+}
+
+func TestFixTypeConflicts_StructFields(t *testing.T) {
+	analyzer, injectors := analyzeInjectors(t, "../testdata/structfields")
+	pkgs, err := analyzer.SearchPackages()
+	if err != nil {
+		t.Fatalf("SearchPackages failed: %v", err)
+	}
+
+	files, err := FixTypeConflicts(pkgs, injectors[0].Graph.Conflicts)
+	if err != nil {
+		t.Fatalf("FixTypeConflicts failed: %v", err)
+	}
+
+	var appSrc, mainSrc string
+	for filename, src := range files {
+		switch filepath.Base(filename) {
+		case "app.go":
+			appSrc = string(src)
+		case "main.go":
+			mainSrc = string(src)
+		}
+	}
+
+	// 元の型はファイルのimport名で書き、フィールドを読む箇所は元の型に変換する
+	want := `// DSN は Config の DSN を同じ型の他の値と区別するための型
+type DSN string
+
+// APIKey は Config の APIKey を同じ型の他の値と区別するための型
+type APIKey string
+
+// AdminRoutes は Config の AdminRoutes を同じ型の他の値と区別するための型
+type AdminRoutes map[string]*r.Handler
+
+// UserRoutes は Config の UserRoutes を同じ型の他の値と区別するための型
+type UserRoutes map[string]*r.Handler
+
+// Config はwire.Structで埋める設定
+type Config struct {
+	DSN         DSN
+	APIKey      APIKey
+	AdminRoutes AdminRoutes
+	UserRoutes  UserRoutes
+}
+
+// Describe は設定の概要を返す
+func (c *Config) Describe() string {
+	if string(c.APIKey) == "" {
+		c.APIKey = "anonymous"
+	}
+	return string(c.DSN) + " " + string(c.APIKey)
+}
+`
+	if !strings.HasSuffix(appSrc, want) {
+		t.Errorf("app.go =\n%s\nwant suffix:\n%s", appSrc, want)
+	}
+
+	// 他のパッケージの構造体リテラルで与える値は名前付きの型に変換する
+	if want := `app.Config{DSN: app.DSN("postgres://localhost")}`; !strings.Contains(mainSrc, want) {
+		t.Errorf("main.go =\n%s\nwant to contain %s", mainSrc, want)
+	}
+}
+
+func TestFixTypeConflicts_Callers(t *testing.T) {
+	analyzer, injectors := analyzeInjectors(t, "../testdata/callers")
+	pkgs, err := analyzer.SearchPackages()
+	if err != nil {
+		t.Fatalf("SearchPackages failed: %v", err)
+	}
+
+	files, err := FixTypeConflicts(pkgs, injectors[0].Graph.Conflicts)
+	if err != nil {
+		t.Fatalf("FixTypeConflicts failed: %v", err)
+	}
+
+	var serverSrc string
+	for filename, src := range files {
+		if filepath.Base(filename) == "server.go" {
+			serverSrc = string(src)
+		}
+	}
+
+	// 他のパッケージの呼び出しは、置き換えた引数に渡す値をファイルのimport名で名前付きの型に変換する
+	if want := `s.replica = database.NewDB(database.DSN(cfg.Replica))`; !strings.Contains(serverSrc, want) {
+		t.Errorf("server.go =\n%s\nwant to contain %s", serverSrc, want)
+	}
+}
+
+func TestFixTypeConflicts_TestFileCaller(t *testing.T) {
+	// 型情報のないテストファイルでリテラル以外を渡す呼び出しは変換せず、位置を列挙して拒否する
+	dir := testutil.CopyFixture(t, "callers")
+	caller := `package db_test
+
+import (
+	"testing"
+
+	"example.com/callers/db"
+)
+
+func TestNewDB_FromEnv(t *testing.T) {
+	dsn := "postgres://replica"
+	if db.NewDB(dsn) == nil {
+		t.Fatal("NewDB returned nil")
+	}
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "db", "caller_test.go"), []byte(caller), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	analyzer, injectors := analyzeInjectors(t, dir)
+	pkgs, err := analyzer.SearchPackages()
+	if err != nil {
+		t.Fatalf("SearchPackages failed: %v", err)
+	}
+
+	_, err = FixTypeConflicts(pkgs, injectors[0].Graph.Conflicts)
+	if err == nil {
+		t.Fatal("FixTypeConflicts succeeded, want an error listing the test caller")
+	}
+	if !strings.Contains(err.Error(), "caller_test.go:11:") {
+		t.Errorf("error = %v, want the position of the call in caller_test.go", err)
+	}
+	if strings.Contains(err.Error(), "db_test.go") {
+		t.Errorf("error = %v, want calls with literals to be accepted", err)
+	}
+}
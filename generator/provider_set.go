@@ -0,0 +1,311 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+	"golang.org/x/tools/go/packages"
+)
+
+// ProviderSetFileName はプロバイダーセットを出力するファイル名
+const ProviderSetFileName = "wire_set.go"
+
+// ProviderSetName はパッケージごとに生成するプロバイダーセットの変数名
+const ProviderSetName = "ProviderSet"
+
+// ProviderSet はパッケージごとのプロバイダーセットを表す
+type ProviderSet struct {
+	PackagePath string         // パッケージパス
+	PackageName string         // パッケージ名
+	Dir         string         // パッケージのディレクトリ
+	Providers   []string       // 提供関数名
+	Bindings    []app.Binding  // wire.Bindで結びつけるインターフェースと実装型
+	FieldsOf    []app.FieldsOf // wire.FieldsOfで供給する構造体のフィールド
+}
+
+// PackageLookup はパッケージパスからパッケージ名とディレクトリを引く
+type PackageLookup interface {
+	LookupPackage(packagePath string) (name, dir string, ok bool)
+}
+
+// CollectProviderSets は注入関数の依存グラフから、パッケージごとのプロバイダーセットを集める
+// 提供関数は定義されているパッケージに、wire.Bindは実装型が定義されているパッケージに配置する
+func CollectProviderSets(lookup PackageLookup, injectors []*app.InjectorInfo) []*ProviderSet {
+	names := newImportSet("")
+	names.lookup = lookup
+	names.learnPackages(injectors)
+
+	sets := make(map[string]*ProviderSet)
+	getSet := func(packagePath string) *ProviderSet {
+		if set, ok := sets[packagePath]; ok {
+			return set
+		}
+		set := &ProviderSet{PackagePath: packagePath, PackageName: names.packageName(packagePath)}
+		if _, dir, ok := lookup.LookupPackage(packagePath); ok {
+			set.Dir = dir
+		}
+		sets[packagePath] = set
+		return set
+	}
+
+	for _, injector := range injectors {
+		if injector.Graph == nil {
+			continue
+		}
+
+		for _, provider := range injector.Graph.Providers {
+			set := getSet(provider.Function.PackagePath)
+			set.Providers = appendUnique(set.Providers, provider.Function.Name)
+		}
+
+		for _, binding := range injector.Graph.Bindings {
+			set := getSet(binding.Impl.PackagePath)
+			if !containsBinding(set.Bindings, binding) {
+				set.Bindings = append(set.Bindings, binding)
+			}
+		}
+
+		// wire.FieldsOfは構造体が定義されているパッケージに配置する
+		for _, fieldsOf := range injector.Graph.FieldsOf {
+			set := getSet(fieldsOf.Struct.PackagePath)
+			set.FieldsOf = mergeFieldsOf(set.FieldsOf, fieldsOf)
+		}
+	}
+
+	// 出力順を安定させる
+	result := make([]*ProviderSet, 0, len(sets))
+	for _, set := range sets {
+		sort.Strings(set.Providers)
+		sort.Slice(set.Bindings, func(i, j int) bool {
+			return set.Bindings[i].Interface.Key() < set.Bindings[j].Interface.Key()
+		})
+		result = append(result, set)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].PackagePath < result[j].PackagePath
+	})
+
+	return result
+}
+
+// Render はプロバイダーセットのGoソースを生成する
+func (set *ProviderSet) Render() ([]byte, error) {
+	imports := newImportSet(set.PackagePath)
+	imports.add("github.com/google/wire")
+
+	var elems []string
+	elems = append(elems, set.Providers...)
+	for _, binding := range set.Bindings {
+		elems = append(elems, fmt.Sprintf("wire.Bind(new(%s), new(%s))",
+			imports.qualify(binding.Interface, false),
+			imports.qualify(binding.Impl, true)))
+	}
+	for _, fieldsOf := range set.FieldsOf {
+		elems = append(elems, fieldsOfExpr(fieldsOf, imports))
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n\n", generatedHeader)
+	fmt.Fprintf(&buf, "package %s\n\n", set.PackageName)
+	imports.write(&buf)
+	fmt.Fprintf(&buf, "// %s は %s パッケージの提供関数をまとめたプロバイダーセット\n", ProviderSetName, set.PackageName)
+	fmt.Fprintf(&buf, "var %s = wire.NewSet(\n", ProviderSetName)
+	for _, elem := range elems {
+		fmt.Fprintf(&buf, "\t%s,\n", elem)
+	}
+	buf.WriteString(")\n")
+
+	return format.Source(buf.Bytes())
+}
+
+// CheckProviderSets は生成するプロバイダーセットの変数名が、パッケージの既存の宣言と重ならないかを確認する
+// 以前に生成したwire_set.goの宣言は上書きするため対象外とし、それ以外のファイルで宣言されている場合はエラーを返す
+func CheckProviderSets(pkgs []*packages.Package, sets []*ProviderSet) error {
+	byPath := make(map[string]*packages.Package, len(pkgs))
+	for _, pkg := range pkgs {
+		byPath[pkg.PkgPath] = pkg
+	}
+
+	var declared []string
+	for _, set := range sets {
+		pkg, ok := byPath[set.PackagePath]
+		if !ok || pkg.Types == nil {
+			continue
+		}
+		obj := pkg.Types.Scope().Lookup(ProviderSetName)
+		if obj == nil {
+			continue
+		}
+		filename := filepath.Base(pkg.Fset.Position(obj.Pos()).Filename)
+		if filename != ProviderSetFileName {
+			declared = append(declared, fmt.Sprintf("%s (%s)", set.PackagePath, filename))
+		}
+	}
+	if len(declared) > 0 {
+		return fmt.Errorf("%s is already declared in %s; rename or remove it to generate %s",
+			ProviderSetName, strings.Join(declared, ", "), ProviderSetFileName)
+	}
+
+	return nil
+}
+
+// WriteProviderSets はプロバイダーセットを各パッケージのディレクトリに書き出す
+func WriteProviderSets(sets []*ProviderSet) ([]string, error) {
+	var written []string
+
+	for _, set := range sets {
+		if set.Dir == "" {
+			return written, fmt.Errorf("directory not found for package %s", set.PackagePath)
+		}
+
+		src, err := set.Render()
+		if err != nil {
+			return written, fmt.Errorf("failed to render provider set for %s: %w", set.PackagePath, err)
+		}
+
+		filePath := filepath.Join(set.Dir, ProviderSetFileName)
+		if err := os.WriteFile(filePath, src, 0o644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", filePath, err)
+		}
+		written = append(written, filePath)
+	}
+
+	return written, nil
+}
+
+// BuildArgs は注入関数のwire.Buildに渡す引数を、プロバイダーセットを参照する形で生成する
+// 例: ["handler.ProviderSet", "repository.ProviderSet", "wire.Struct(new(ControllerSet), \"*\")"]
+func BuildArgs(injector *app.InjectorInfo, sets []*ProviderSet) []string {
+	used := make(map[string]bool)
+	if injector.Graph != nil {
+		for _, provider := range injector.Graph.Providers {
+			used[provider.Function.PackagePath] = true
+		}
+		for _, binding := range injector.Graph.Bindings {
+			used[binding.Impl.PackagePath] = true
+		}
+		for _, fieldsOf := range injector.Graph.FieldsOf {
+			used[fieldsOf.Struct.PackagePath] = true
+		}
+	}
+
+	var args []string
+	for _, set := range sets {
+		if !used[set.PackagePath] {
+			continue
+		}
+		if set.PackagePath == injector.PackagePath {
+			args = append(args, ProviderSetName)
+		} else {
+			args = append(args, set.PackageName+"."+ProviderSetName)
+		}
+	}
+
+	// 設定された値は注入関数ごとに異なるため、プロバイダーセットに含めず直接渡す
+	if injector.Graph != nil {
+		imports := newImportSet(injector.PackagePath)
+		for _, value := range injector.Graph.Values {
+			args = append(args, valueExpr(value, imports))
+		}
+	}
+
+	// ルートを返す提供関数がない場合はフィールドから組み立てる
+	if !providesRoot(injector) {
+		args = append(args, fmt.Sprintf("wire.Struct(new(%s), \"*\")", rootTypeName(injector)))
+	}
+
+	return args
+}
+
+// providesRoot はルートの構造体を返す提供関数が依存グラフに含まれるかを判定する
+func providesRoot(injector *app.InjectorInfo) bool {
+	if injector.Graph == nil {
+		return false
+	}
+	for _, provider := range injector.Graph.Providers {
+		if provider.Provides.PackagePath == injector.Root.PackagePath &&
+			provider.Provides.TypeName == injector.Root.StructName {
+			return true
+		}
+	}
+	return false
+}
+
+// rootTypeName は注入関数のパッケージから見たルートの構造体名を返す
+func rootTypeName(injector *app.InjectorInfo) string {
+	ref := app.TypeRef{TypeName: injector.Root.StructName, PackagePath: injector.Root.PackagePath, PackageName: injector.Root.PackageName}
+	return ref.Qualified(injector.PackagePath)
+}
+
+// appendUnique は重複しない場合のみ要素を追加する
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// containsBinding は同じインターフェースと実装型の組が含まれているかを判定する
+func containsBinding(bindings []app.Binding, binding app.Binding) bool {
+	for _, b := range bindings {
+		if b.Interface.Key() == binding.Interface.Key() && b.Impl.Key() == binding.Impl.Key() {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldsOfExpr はwire.FieldsOfの式を生成する
+// 例: wire.FieldsOf(new(*Config), "DSN", "MaxPoolSize")
+func fieldsOfExpr(fieldsOf app.FieldsOf, imports *importSet) string {
+	args := []string{fmt.Sprintf("new(%s)", imports.qualify(fieldsOf.Struct, fieldsOf.Struct.IsPointer))}
+	for _, field := range fieldsOf.Fields {
+		args = append(args, strconv.Quote(field.Name))
+	}
+	return "wire.FieldsOf(" + strings.Join(args, ", ") + ")"
+}
+
+// valueExpr はwire.Valueまたはwire.InterfaceValueの式を生成する
+// 例: wire.Value(http.DefaultClient), wire.InterfaceValue(new(io.Writer), os.Stdout)
+func valueExpr(value app.Value, imports *importSet) string {
+	imports.setPackageName(value.ExprPackage, value.ExprPackageName)
+	expr := literalExpr(value, imports.selfPath, imports.add)
+	if value.Type.IsInterface {
+		return fmt.Sprintf("wire.InterfaceValue(new(%s), %s)", imports.qualify(value.Type, false), expr)
+	}
+	return "wire.Value(" + expr + ")"
+}
+
+// literalExpr は設定された値をselfPathのパッケージから参照する式を返す
+func literalExpr(value app.Value, selfPath string, importName func(string) string) string {
+	if value.ExprPackage == "" || value.ExprPackage == selfPath {
+		return value.Expr
+	}
+	return importName(value.ExprPackage) + "." + value.Expr
+}
+
+// mergeFieldsOf は同じ構造体のwire.FieldsOfをまとめ、フィールドを重複なく追加する
+func mergeFieldsOf(list []app.FieldsOf, fieldsOf app.FieldsOf) []app.FieldsOf {
+	for i := range list {
+		if list[i].Struct.Key() != fieldsOf.Struct.Key() {
+			continue
+		}
+		for _, field := range fieldsOf.Fields {
+			if !slices.ContainsFunc(list[i].Fields, func(f app.StructField) bool { return f.Name == field.Name }) {
+				list[i].Fields = append(list[i].Fields, field)
+			}
+		}
+		return list
+	}
+	return append(list, app.FieldsOf{Struct: fieldsOf.Struct, Fields: slices.Clone(fieldsOf.Fields)})
+}
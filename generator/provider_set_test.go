@@ -0,0 +1,163 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+	"github.com/rmocchy/convinient_wire/internal/testutil"
+)
+
+// analyzeInjectors はテスト用にwire.goを解析する
+func analyzeInjectors(t *testing.T, workDir string) (*app.WireAnalyzer, []*app.InjectorInfo) {
+	t.Helper()
+
+	analyzer := app.NewWireAnalyzer(workDir, "./...")
+	injectors, err := analyzer.AnalyzeInjectors(filepath.Join(workDir, "wire.go"))
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+	return analyzer, injectors
+}
+
+func TestCollectProviderSets(t *testing.T) {
+	analyzer, injectors := analyzeInjectors(t, "../testdata/inputs")
+
+	sets := CollectProviderSets(analyzer, injectors)
+
+	want := map[string][]string{
+		"example.com/inputs/handler": {"NewHandler"},
+		"example.com/inputs/notify":  {"NewEmailNotifier"},
+		"example.com/inputs/repo":    {"NewDB", "NewUserRepository"},
+	}
+
+	if len(sets) != len(want) {
+		t.Fatalf("got %d sets, want %d", len(sets), len(want))
+	}
+	for _, set := range sets {
+		if !reflect.DeepEqual(set.Providers, want[set.PackagePath]) {
+			t.Errorf("%s: Providers = %v, want %v", set.PackagePath, set.Providers, want[set.PackagePath])
+		}
+		if set.Dir == "" {
+			t.Errorf("%s: Dir is empty", set.PackagePath)
+		}
+	}
+
+	// 実装型のパッケージにwire.Bindが配置される
+	notifySet := sets[1]
+	if len(notifySet.Bindings) != 1 {
+		t.Fatalf("notify: Bindings = %+v", notifySet.Bindings)
+	}
+
+	src, err := notifySet.Render()
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	wantSrc := `// Code generated by convinient_wire. DO NOT EDIT.
+
+package notify
+
+import "github.com/google/wire"
+
+// ProviderSet は notify パッケージの提供関数をまとめたプロバイダーセット
+var ProviderSet = wire.NewSet(
+	NewEmailNotifier,
+	wire.Bind(new(Notifier), new(*EmailNotifier)),
+)
+`
+	if string(src) != wantSrc {
+		t.Errorf("Render() =\n%s\nwant\n%s", src, wantSrc)
+	}
+
+	// 注入関数はプロバイダーセットを参照する
+	args := BuildArgs(injectors[0], sets)
+	wantArgs := []string{
+		"handler.ProviderSet",
+		"notify.ProviderSet",
+		"repo.ProviderSet",
+		`wire.Struct(new(App), "*")`,
+	}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("BuildArgs() = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestWriteProviderSets(t *testing.T) {
+	dir := t.TempDir()
+
+	sets := []*ProviderSet{{
+		PackagePath: "example.com/app/repository",
+		PackageName: "repository",
+		Dir:         dir,
+		Providers:   []string{"NewConfig", "NewUserRepository"},
+		Bindings: []app.Binding{{
+			Interface: app.TypeRef{TypeName: "Store", PackagePath: "example.com/app/store"},
+			Impl:      app.TypeRef{TypeName: "sqlStore", PackagePath: "example.com/app/repository", IsPointer: true},
+		}},
+	}}
+
+	written, err := WriteProviderSets(sets)
+	if err != nil {
+		t.Fatalf("WriteProviderSets failed: %v", err)
+	}
+	if len(written) != 1 || written[0] != filepath.Join(dir, ProviderSetFileName) {
+		t.Fatalf("written = %v", written)
+	}
+
+	got, err := os.ReadFile(written[0])
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	want := `// Code generated by convinient_wire. DO NOT EDIT.
+
+package repository
+
+import (
+	"example.com/app/store"
+	"github.com/google/wire"
+)
+
+// ProviderSet は repository パッケージの提供関数をまとめたプロバイダーセット
+var ProviderSet = wire.NewSet(
+	NewConfig,
+	NewUserRepository,
+	wire.Bind(new(store.Store), new(*sqlStore)),
+)
+`
+	if string(got) != want {
+		t.Errorf("generated file =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCheckProviderSets(t *testing.T) {
+	// 手書きのProviderSetがあるパッケージには書き出せない
+	analyzer, injectors := analyzeInjectors(t, "../testdata/pkgsets")
+	pkgs, err := analyzer.SearchPackages()
+	if err != nil {
+		t.Fatalf("SearchPackages failed: %v", err)
+	}
+
+	err = CheckProviderSets(pkgs, CollectProviderSets(analyzer, injectors))
+	want := "ProviderSet is already declared in example.com/pkgsets/db (db.go), example.com/pkgsets/repo (repo.go); rename or remove it to generate wire_set.go"
+	if err == nil || err.Error() != want {
+		t.Errorf("CheckProviderSets() error = %v, want %s", err, want)
+	}
+
+	// 以前に生成したwire_set.goは上書きする
+	dir := testutil.CopyFixture(t, "inputs")
+	analyzer, injectors = analyzeInjectors(t, dir)
+	if _, err := WriteProviderSets(CollectProviderSets(analyzer, injectors)); err != nil {
+		t.Fatalf("WriteProviderSets failed: %v", err)
+	}
+	analyzer, injectors = analyzeInjectors(t, dir)
+	if pkgs, err = analyzer.SearchPackages(); err != nil {
+		t.Fatalf("SearchPackages failed: %v", err)
+	}
+	if err := CheckProviderSets(pkgs, CollectProviderSets(analyzer, injectors)); err != nil {
+		t.Errorf("CheckProviderSets() error = %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+// wireInjectHeader はwire.goの先頭に付けるビルド制約
+const wireInjectHeader = `//go:build wireinject
+// +build wireinject
+`
+
+// Scaffold は新しく作成するwire.goの内容を表す
+type Scaffold struct {
+	PackageName string        // wire.goのパッケージ名
+	PackagePath string        // wire.goのパッケージパス（同じパッケージの型はimportせずに参照する）
+	StructName  string        // ルートをまとめる構造体名（空の場合はルートを直接返す）
+	Roots       []app.TypeRef // 注入関数が組み立てるルートの型
+}
+
+// InjectorName は注入関数名を返す（例: InitializeControllerSet）
+func (s *Scaffold) InjectorName() string {
+	return "Initialize" + s.rootName()
+}
+
+// rootName は注入関数が返す型の名前を返す
+func (s *Scaffold) rootName() string {
+	if s.StructName != "" {
+		return s.StructName
+	}
+	return s.Roots[0].TypeName
+}
+
+// Validate はルートの指定が正しいかを確認する
+func (s *Scaffold) Validate() error {
+	if len(s.Roots) == 0 {
+		return fmt.Errorf("no root type given")
+	}
+	if len(s.Roots) > 1 && s.StructName == "" {
+		return fmt.Errorf("a struct name is required to combine %d root types", len(s.Roots))
+	}
+	return nil
+}
+
+// RenderDraft は依存関係を解析するための仮のwire.goを生成する
+// 注入関数のシグネチャはルートの返り値のみで、errorの有無は解析結果から決める
+func (s *Scaffold) RenderDraft() ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	imports := newImportSet(s.PackagePath)
+	imports.add("github.com/google/wire")
+	root := s.rootType(imports)
+
+	var body bytes.Buffer
+	s.writeStruct(&body, imports)
+	fmt.Fprintf(&body, "func %s() %s {\n\tpanic(wire.Build())\n}\n", s.InjectorName(), root)
+
+	return s.render(imports, body.Bytes())
+}
+
+// Render は解析した注入関数から、引数とwire.Buildを推論したwire.goを生成する
+func (s *Scaffold) Render(injector *app.InjectorInfo) ([]byte, error) {
+	if injector.Graph == nil {
+		return nil, fmt.Errorf("injector %s could not be analyzed: %s", injector.Name, injector.SkipReason())
+	}
+	// 提供関数を選べない依存があると、wire.Buildに並べる提供関数を決められない
+	graph := injector.Graph
+	if len(graph.Conflicts) > 0 {
+		return nil, fmt.Errorf("injector %s: %s is needed with different meanings (run conflicts to introduce named types)",
+			injector.Name, graph.Conflicts[0].Type.TypeString)
+	}
+	if len(graph.Unsatisfied) > 0 {
+		return nil, fmt.Errorf("injector %s: %s", injector.Name, graph.Unsatisfied[0])
+	}
+	if len(graph.Ambiguous) > 0 {
+		return nil, fmt.Errorf("injector %s: %s", injector.Name, graph.Ambiguous[0])
+	}
+
+	imports := newImportSet(injector.PackagePath)
+	imports.add("github.com/google/wire")
+
+	// wire.Buildの引数（提供関数は依存される側から順に並べる）
+	elems := buildElems(injector, imports)
+
+	// 引数（パッケージ名を隠さないように、importする名前を予約してから決める）
+	paramTypes := make([]string, 0, len(injector.Inputs))
+	for _, input := range injector.Inputs {
+		paramTypes = append(paramTypes, imports.qualify(input.Type, input.Type.IsPointer))
+	}
+	root := s.rootType(imports)
+	names := newNameSet()
+	for name := range imports.used {
+		names.reserve(name)
+	}
+	params := make([]string, 0, len(injector.Inputs))
+	for i, input := range injector.Inputs {
+		params = append(params, names.declare(input.Name)+" "+paramTypes[i])
+	}
+
+	// 返り値
+	results := []string{root}
+	if injector.HasCleanup {
+		results = append(results, "func()")
+	}
+	if injector.ReturnsError {
+		results = append(results, "error")
+	}
+	resultList := strings.Join(results, ", ")
+	if len(results) > 1 {
+		resultList = "(" + resultList + ")"
+	}
+
+	var body bytes.Buffer
+	s.writeStruct(&body, imports)
+	fmt.Fprintf(&body, "// %s は全ての依存関係を解決して%sを初期化\n", s.InjectorName(), s.rootName())
+	fmt.Fprintf(&body, "func %s(%s) %s {\n", s.InjectorName(), strings.Join(params, ", "), resultList)
+	body.WriteString("\twire.Build(\n")
+	for _, elem := range elems {
+		fmt.Fprintf(&body, "\t\t%s,\n", elem)
+	}
+	body.WriteString("\t)\n")
+	fmt.Fprintf(&body, "\treturn %s\n}\n", strings.Join(zeroValues(results), ", "))
+
+	return s.render(imports, body.Bytes())
+}
+
+// buildElems は依存グラフからwire.Buildに渡す要素を生成する
+// 提供関数、wire.Bind、wire.FieldsOf、wire.Valueの順に並べ、ルートを返す提供関数がない場合はwire.Structを加える
+func buildElems(injector *app.InjectorInfo, imports *importSet) []string {
+	var elems []string
+	for _, provider := range injector.Graph.Providers {
+		fn := provider.Function.Name
+		if provider.Function.PackagePath != injector.PackagePath {
+			fn = imports.addPackage(provider.Function.PackagePath, provider.Function.PackageName) + "." + fn
+		}
+		elems = appendUnique(elems, fn)
+	}
+	for _, binding := range injector.Graph.Bindings {
+		elems = appendUnique(elems, fmt.Sprintf("wire.Bind(new(%s), new(%s))",
+			imports.qualify(binding.Interface, false),
+			imports.qualify(binding.Impl, true)))
+	}
+	for _, fieldsOf := range injector.Graph.FieldsOf {
+		elems = append(elems, fieldsOfExpr(fieldsOf, imports))
+	}
+	for _, value := range injector.Graph.Values {
+		elems = append(elems, valueExpr(value, imports))
+	}
+	if !providesRoot(injector) {
+		root := app.TypeRef{TypeName: injector.Root.StructName, PackagePath: injector.Root.PackagePath, PackageName: injector.Root.PackageName}
+		elems = append(elems, fmt.Sprintf("wire.Struct(new(%s), \"*\")", imports.qualify(root, false)))
+	}
+	return elems
+}
+
+// rootType は注入関数の返り値の型を返す
+func (s *Scaffold) rootType(imports *importSet) string {
+	if s.StructName != "" {
+		return "*" + s.StructName
+	}
+	return imports.qualify(s.Roots[0], s.Roots[0].IsPointer)
+}
+
+// writeStruct はルートをまとめる構造体を書き出す
+func (s *Scaffold) writeStruct(buf *bytes.Buffer, imports *importSet) {
+	if s.StructName == "" {
+		return
+	}
+	writeAggregateStruct(buf, imports, s.StructName, s.Roots)
+	buf.WriteString("\n")
+}
+
+// writeAggregateStruct は型ごとに1つのフィールドを持つ構造体を書き出す（フィールド名は型名から作る）
+func writeAggregateStruct(buf *bytes.Buffer, imports *importSet, structName string, fields []app.TypeRef) {
+	names := newNameSet()
+	fmt.Fprintf(buf, "type %s struct {\n", structName)
+	for _, field := range fields {
+		fmt.Fprintf(buf, "\t%s %s\n", names.declare(app.VariableName(field)), imports.qualify(field, field.IsPointer))
+	}
+	buf.WriteString("}\n")
+}
+
+// render はヘッダー・package宣言・importを付けて整形する
+func (s *Scaffold) render(imports *importSet, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(wireInjectHeader)
+	fmt.Fprintf(&buf, "\npackage %s\n\n", s.PackageName)
+	imports.write(&buf)
+	buf.Write(body)
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w\n%s", err, buf.String())
+	}
+	return src, nil
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+func TestScaffold_RenderDraft(t *testing.T) {
+	scaffold := &Scaffold{
+		PackageName: "main",
+		StructName:  "ControllerSet",
+		Roots: []app.TypeRef{
+			{TypeName: "UserHandler", PackagePath: "example.com/app/handler", IsPointer: true},
+			{TypeName: "AdminHandler", PackagePath: "example.com/app/handler", IsPointer: true},
+		},
+	}
+
+	src, err := scaffold.RenderDraft()
+	if err != nil {
+		t.Fatalf("RenderDraft failed: %v", err)
+	}
+
+	want := `//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"example.com/app/handler"
+	"github.com/google/wire"
+)
+
+type ControllerSet struct {
+	userHandler  *handler.UserHandler
+	adminHandler *handler.AdminHandler
+}
+
+func InitializeControllerSet() *ControllerSet {
+	panic(wire.Build())
+}
+`
+	if string(src) != want {
+		t.Errorf("RenderDraft() =\n%s\nwant:\n%s", src, want)
+	}
+}
+
+func TestScaffold_Validate(t *testing.T) {
+	root := app.TypeRef{TypeName: "UserHandler", PackagePath: "example.com/app/handler"}
+
+	tests := []struct {
+		name     string
+		scaffold Scaffold
+		wantErr  string
+	}{
+		{name: "single root", scaffold: Scaffold{Roots: []app.TypeRef{root}}},
+		{name: "no root", scaffold: Scaffold{}, wantErr: "no root type"},
+		{name: "several roots without struct", scaffold: Scaffold{Roots: []app.TypeRef{root, root}}, wantErr: "struct name is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.scaffold.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package generator
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// setPackage はwire.NewSetの宣言を探すパッケージ
+type setPackage struct {
+	path  string
+	names map[string]bool     // パッケージレベルで宣言された識別子
+	sets  map[string]*setDecl // wire.NewSetで初期化された変数名 -> 宣言
+	vars  map[string]bool     // wire.NewSet以外で初期化された変数名
+}
+
+// setDecl は1つのwire.NewSetの宣言
+type setDecl struct {
+	key     string            // パッケージパスと変数名（循環の検出に使う）
+	args    []ast.Expr        // wire.NewSetの引数
+	imports map[string]string // 宣言したファイルのimport名 -> importパス
+}
+
+// expandedElem はwire.Buildの要素を展開した1つの要素
+type expandedElem struct {
+	text    string // wire.goのimport名で書いた要素
+	inSet   bool   // 他のパッケージのプロバイダーセットから展開した要素
+	opaque  bool   // wire.goから参照できる形に展開できない要素
+	origin  string // 展開元のwire.Buildの引数
+	foreign bool   // 展開元が他のパッケージのプロバイダーセットかどうか
+}
+
+// setResolver はwire.Buildの要素に含まれるプロバイダーセットを、宣言したパッケージのwire.NewSetから展開する
+// 要素はwire.goのimport名で書き直し、wire.goから参照できないもの（非公開の識別子）を含む要素は展開できない要素とする
+type setResolver struct {
+	lookup    PackageLookup
+	selfPath  string            // wire.goのパッケージパス
+	imports   map[string]string // wire.goのimport名 -> importパス
+	names     *importSet        // 要素の参照に使うimport名（wire.goにないパッケージは追加する）
+	local     *setPackage       // wire.goで宣言されたプロバイダーセット
+	packages  map[string]*setPackage
+	expanded  map[string][]expandedElem // 展開済みのプロバイダーセット
+	expanding map[string]bool
+}
+
+// newSetResolver はwire.goのプロバイダーセットの宣言と、要素の参照に使うimport名からsetResolverを作成する
+// lookupがnilの場合は他のパッケージのプロバイダーセットを展開しない
+func newSetResolver(file *ast.File, names *importSet, lookup PackageLookup) *setResolver {
+	// 展開したパッケージのimportもパッケージ名で参照できるようにする
+	if names.lookup == nil {
+		names.lookup = lookup
+	}
+	return &setResolver{
+		lookup:    lookup,
+		selfPath:  names.selfPath,
+		imports:   wireFileImports(file, names.packageName),
+		names:     names,
+		local:     newSetPackage(names.selfPath, []*ast.File{file}, names.packageName),
+		packages:  make(map[string]*setPackage),
+		expanded:  make(map[string][]expandedElem),
+		expanding: make(map[string]bool),
+	}
+}
+
+// newSetPackage はファイルのパッケージレベルの宣言からsetPackageを作成する
+func newSetPackage(pkgPath string, files []*ast.File, packageName func(importPath string) string) *setPackage {
+	pkg := &setPackage{
+		path:  pkgPath,
+		names: make(map[string]bool),
+		sets:  make(map[string]*setDecl),
+		vars:  make(map[string]bool),
+	}
+	for _, file := range files {
+		imports := wireFileImports(file, packageName)
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					pkg.names[d.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						pkg.names[s.Name.Name] = true
+					case *ast.ValueSpec:
+						for i, name := range s.Names {
+							pkg.names[name.Name] = true
+							if d.Tok != token.VAR {
+								continue
+							}
+							if args, ok := newSetArgs(s, i); ok {
+								pkg.sets[name.Name] = &setDecl{key: pkgPath + "." + name.Name, args: args, imports: imports}
+							} else {
+								pkg.vars[name.Name] = true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return pkg
+}
+
+// newSetArgs はvar宣言のi番目の値がwire.NewSetの呼び出しであれば、その引数を返す
+func newSetArgs(spec *ast.ValueSpec, i int) ([]ast.Expr, bool) {
+	if i >= len(spec.Values) {
+		return nil, false
+	}
+	call, ok := spec.Values[i].(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "NewSet" {
+		return call.Args, true
+	}
+	return nil, false
+}
+
+// packageOf はパッケージのwire.NewSetの宣言を読み込む（見つからない場合はnil）
+func (r *setResolver) packageOf(pkgPath string) *setPackage {
+	if pkgPath == r.selfPath {
+		return r.local
+	}
+	if pkg, ok := r.packages[pkgPath]; ok {
+		return pkg
+	}
+	r.packages[pkgPath] = nil
+	if r.lookup == nil {
+		return nil
+	}
+	_, dir, ok := r.lookup.LookupPackage(pkgPath)
+	if !ok {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil
+		}
+		files = append(files, file)
+	}
+	pkg := newSetPackage(pkgPath, files, r.names.packageName)
+	r.packages[pkgPath] = pkg
+	return pkg
+}
+
+// expandBuild はwire.goのwire.Buildの引数を展開する
+func (r *setResolver) expandBuild(args []ast.Expr) []expandedElem {
+	var elems []expandedElem
+	for _, arg := range args {
+		origin := types.ExprString(arg)
+		expanded, foreign := r.expandArg(arg, r.local, r.imports, false)
+		for _, elem := range expanded {
+			elem.origin = origin
+			elem.foreign = foreign
+			elems = append(elems, elem)
+		}
+	}
+	return elems
+}
+
+// expandArg は1つの要素を展開する
+// プロバイダーセットは宣言を辿って展開し、他のパッケージのプロバイダーセットを展開した場合はforeignをtrueにする
+func (r *setResolver) expandArg(arg ast.Expr, pkg *setPackage, imports map[string]string, inSet bool) (elems []expandedElem, foreign bool) {
+	switch e := arg.(type) {
+	case *ast.Ident:
+		if decl, ok := pkg.sets[e.Name]; ok {
+			return r.expandSet(decl, pkg, inSet), false
+		}
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			if importPath, ok := imports[x.Name]; ok && importPath != wireImportPath {
+				target := r.packageOf(importPath)
+				if target != nil {
+					if decl, ok := target.sets[e.Sel.Name]; ok {
+						return r.expandSet(decl, target, true), true
+					}
+					// wire.NewSet以外で初期化された変数は何を提供するか分からない
+					if target.vars[e.Sel.Name] {
+						return []expandedElem{{text: types.ExprString(arg), inSet: inSet, opaque: true}}, true
+					}
+				}
+			}
+		}
+	}
+
+	text, ok := r.qualify(arg, pkg, imports)
+	return []expandedElem{{text: text, inSet: inSet, opaque: !ok}}, false
+}
+
+// expandSet はプロバイダーセットの宣言を展開する（循環している場合は展開しない）
+func (r *setResolver) expandSet(decl *setDecl, pkg *setPackage, inSet bool) []expandedElem {
+	if elems, ok := r.expanded[decl.key]; ok {
+		return withInSet(elems, inSet)
+	}
+	if r.expanding[decl.key] {
+		return nil
+	}
+	r.expanding[decl.key] = true
+	defer delete(r.expanding, decl.key)
+
+	var elems []expandedElem
+	for _, arg := range decl.args {
+		expanded, _ := r.expandArg(arg, pkg, decl.imports, inSet)
+		elems = append(elems, expanded...)
+	}
+	// 宣言のASTは書き直しているため、展開した結果を使い回す
+	r.expanded[decl.key] = elems
+	return elems
+}
+
+// withInSet は展開済みの要素のinSetを設定し直す
+func withInSet(elems []expandedElem, inSet bool) []expandedElem {
+	result := make([]expandedElem, len(elems))
+	for i, elem := range elems {
+		elem.inSet = elem.inSet || inSet
+		result[i] = elem
+	}
+	return result
+}
+
+// qualify は宣言したパッケージの識別子をwire.goのimport名で書き直した要素を返す
+// wire.goから参照できない識別子を含む場合はokをfalseにする
+func (r *setResolver) qualify(expr ast.Expr, pkg *setPackage, imports map[string]string) (text string, ok bool) {
+	ok = true
+	result := astutil.Apply(expr, func(c *astutil.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.SelectorExpr:
+			x, isIdent := n.X.(*ast.Ident)
+			if !isIdent {
+				return true
+			}
+			importPath, found := imports[x.Name]
+			if !found {
+				return true
+			}
+			qualified, found := r.qualifiedName(importPath, n.Sel.Name)
+			if !found {
+				ok = false
+				return false
+			}
+			c.Replace(qualified)
+			return false
+		case *ast.Ident:
+			if c.Name() == "Sel" || !pkg.names[n.Name] {
+				return true
+			}
+			qualified, found := r.qualifiedName(pkg.path, n.Name)
+			if !found {
+				ok = false
+				return false
+			}
+			c.Replace(qualified)
+		}
+		return true
+	}, nil)
+	return types.ExprString(result.(ast.Expr)), ok
+}
+
+// qualifiedName はパッケージの識別子をwire.goから参照する式を返す
+func (r *setResolver) qualifiedName(pkgPath, name string) (ast.Expr, bool) {
+	if pkgPath == r.selfPath {
+		return ast.NewIdent(name), true
+	}
+	if !token.IsExported(name) {
+		return nil, false
+	}
+	return &ast.SelectorExpr{X: ast.NewIdent(r.names.add(pkgPath)), Sel: ast.NewIdent(name)}, true
+}
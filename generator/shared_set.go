@@ -0,0 +1,292 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+// SharedSetName は複数の注入関数で共有するプロバイダーセットの変数名
+const SharedSetName = "SharedSet"
+
+// wireImportPath はwireパッケージのimportパス
+const wireImportPath = "github.com/google/wire"
+
+// InjectorBuild は1つの注入関数のwire.Buildに渡す要素
+type InjectorBuild struct {
+	Name       string   // 注入関数名
+	UsesShared bool     // 共有のプロバイダーセットを使うかどうか
+	Extras     []string // この注入関数だけが使う要素
+}
+
+// Args はwire.Buildに渡す引数を返す
+func (b InjectorBuild) Args() []string {
+	if !b.UsesShared {
+		return b.Extras
+	}
+	return append([]string{SharedSetName}, b.Extras...)
+}
+
+// InjectorSets は1つのwire.goの注入関数で共有する要素と、注入関数ごとの要素
+type InjectorSets struct {
+	PackagePath string          // wire.goのパッケージパス
+	Shared      []string        // 2つ以上の注入関数で使う要素（wire.NewSetの引数）
+	Injectors   []InjectorBuild // 注入関数ごとのwire.Buildの要素
+	keepShared  bool            // 以前に生成した共有のプロバイダーセットの宣言を残すかどうか（一部の注入関数だけを書き換える場合）
+	imports     *importSet
+}
+
+// SplitInjectorSets は注入関数ごとのwire.Buildの要素から、2つ以上の注入関数で使うものを共有のプロバイダーセットにまとめる
+// wire.goのimportを引き継ぐため、wire.goのパスを受け取る
+func SplitInjectorSets(wireFilePath string, injectors []*app.InjectorInfo) (*InjectorSets, error) {
+	sets, elems, err := collectBuildElems(wireFilePath, injectors)
+	if err != nil {
+		return nil, err
+	}
+
+	count := make(map[string]int)
+	for _, injectorElems := range elems {
+		for _, elem := range injectorElems {
+			count[elem]++
+		}
+	}
+
+	// 共有する要素は最初に使う注入関数での順序（依存される側から）に並べる
+	for _, injectorElems := range elems {
+		for _, elem := range injectorElems {
+			if count[elem] > 1 {
+				sets.Shared = appendUnique(sets.Shared, elem)
+			}
+		}
+	}
+
+	for i, injector := range injectors {
+		build := InjectorBuild{Name: injector.Name}
+		for _, elem := range elems[i] {
+			if count[elem] > 1 {
+				build.UsesShared = true
+			} else {
+				build.Extras = append(build.Extras, elem)
+			}
+		}
+		sets.Injectors = append(sets.Injectors, build)
+	}
+
+	return sets, nil
+}
+
+// FillInjectorSets は共有のプロバイダーセットを使わず、解析結果の要素を全て注入関数ごとに渡すInjectorSetsを作る
+func FillInjectorSets(wireFilePath string, injectors []*app.InjectorInfo) (*InjectorSets, error) {
+	sets, elems, err := collectBuildElems(wireFilePath, injectors)
+	if err != nil {
+		return nil, err
+	}
+	for i, injector := range injectors {
+		sets.Injectors = append(sets.Injectors, InjectorBuild{Name: injector.Name, Extras: elems[i]})
+	}
+	// 書き換えない注入関数が共有のプロバイダーセットを使っている可能性があるため残す
+	sets.keepShared = true
+	return sets, nil
+}
+
+// collectBuildElems は注入関数ごとのwire.Buildの要素を、wire.goと同じimport名で生成する
+func collectBuildElems(wireFilePath string, injectors []*app.InjectorInfo) (*InjectorSets, [][]string, error) {
+	if len(injectors) == 0 {
+		return nil, nil, fmt.Errorf("no injectors found in %s", wireFilePath)
+	}
+
+	wireFile, err := parser.ParseFile(token.NewFileSet(), wireFilePath, nil, parser.ImportsOnly)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse wire file: %w", err)
+	}
+
+	sets := &InjectorSets{
+		PackagePath: injectors[0].PackagePath,
+		imports:     newImportSet(injectors[0].PackagePath),
+	}
+	sets.imports.learnPackages(injectors)
+	// wire.goと同じimport名で参照する
+	for name, importPath := range wireFileImports(wireFile, sets.imports.packageName) {
+		sets.imports.addNamed(importPath, name)
+	}
+	sets.imports.add(wireImportPath)
+
+	elems := make([][]string, 0, len(injectors))
+	for _, injector := range injectors {
+		if injector.Graph == nil {
+			return nil, nil, fmt.Errorf("injector %s could not be analyzed: %s", injector.Name, injector.SkipReason())
+		}
+		elems = append(elems, buildElems(injector, sets.imports))
+	}
+
+	return sets, elems, nil
+}
+
+// RewriteWireFile はwire.goの各注入関数のwire.Buildを、共有のプロバイダーセットと注入関数ごとの要素に書き換える
+// 共有のプロバイダーセットの宣言は最初の注入関数の前に追加する（既にある場合は置き換える）
+func RewriteWireFile(wireFilePath string, sets *InjectorSets) ([]byte, error) {
+	src, err := os.ReadFile(wireFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", wireFilePath, err)
+	}
+	return RewriteWireSource(wireFilePath, src, sets)
+}
+
+// RewriteWireSource はRewriteWireFileと同様に、エディタで編集中のwire.goのソースを書き換える
+func RewriteWireSource(wireFilePath string, src []byte, sets *InjectorSets) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, wireFilePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse wire file: %w", err)
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+	builds := make(map[string]InjectorBuild, len(sets.Injectors))
+	for _, build := range sets.Injectors {
+		builds[build.Name] = build
+	}
+
+	var edits []sourceEdit
+	var firstInjector ast.Node
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			// 以前に生成した共有のプロバイダーセットは新しい宣言に置き換える（共有する要素がなければ取り除く）
+			if !sets.keepShared && declaresVar(d, SharedSetName) {
+				start := d.Pos()
+				if d.Doc != nil {
+					start = d.Doc.Pos()
+				}
+				edits = append(edits, sourceEdit{start: offset(start), end: offset(d.End()) + 1})
+			}
+
+		case *ast.FuncDecl:
+			build, ok := builds[d.Name.Name]
+			if !ok {
+				continue
+			}
+			call := findWireBuild(file, d)
+			if call == nil {
+				return nil, fmt.Errorf("wire.Build not found in %s", d.Name.Name)
+			}
+			edits = append(edits, sourceEdit{
+				start: offset(call.Lparen) + 1,
+				end:   offset(call.Rparen),
+				text:  elemList(build.Args(), "\t\t", "\t"),
+			})
+			if firstInjector == nil {
+				firstInjector = d
+				if d.Doc != nil {
+					firstInjector = d.Doc
+				}
+			}
+		}
+	}
+
+	if len(sets.Shared) > 0 && firstInjector != nil {
+		text := fmt.Sprintf("// %s は複数の注入関数で共通して使う提供関数をまとめたプロバイダーセット\nvar %s = wire.NewSet(%s)\n\n",
+			SharedSetName, SharedSetName, elemList(sets.Shared, "\t", ""))
+		edits = append(edits, sourceEdit{start: offset(firstInjector.Pos()), end: offset(firstInjector.Pos()), text: text})
+	}
+
+	return sets.fixImports(applyEdits(src, edits))
+}
+
+// fixImports は書き換えたwire.goに必要なimportを追加し、使われなくなったimportを取り除く
+func (sets *InjectorSets) fixImports(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "wire.go", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rewritten wire file: %w", err)
+	}
+
+	// 既にimportされている場合は何もしない
+	for importPath, name := range sets.imports.names {
+		alias := ""
+		if name != sets.imports.packageName(importPath) {
+			alias = name
+		}
+		astutil.AddNamedImport(fset, file, alias, importPath)
+	}
+	for _, spec := range slices.Clone(file.Imports) {
+		name := ""
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		// ブランクimportとドットimportは使用箇所から判断できないため残す
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if name != "_" && name != "." && !astutil.UsesImport(file, importPath) {
+			astutil.DeleteNamedImport(fset, file, name, importPath)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, fmt.Errorf("failed to format wire file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// findWireBuild は注入関数の中のwire.Buildの呼び出しを探す
+func findWireBuild(file *ast.File, funcDecl *ast.FuncDecl) *ast.CallExpr {
+	wireName := "wire"
+	for name, importPath := range wireFileImports(file, path.Base) {
+		if importPath == wireImportPath {
+			wireName = name
+		}
+	}
+
+	var found *ast.CallExpr
+	ast.Inspect(funcDecl, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || found != nil {
+			return found == nil
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Build" {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == wireName {
+				found = call
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// declaresVar はvar宣言が指定した名前の変数を宣言しているかを判定する
+func declaresVar(decl *ast.GenDecl, name string) bool {
+	if decl.Tok != token.VAR {
+		return false
+	}
+	for _, spec := range decl.Specs {
+		for _, ident := range spec.(*ast.ValueSpec).Names {
+			if ident.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// elemList は要素を1行に1つずつ並べた引数リストを返す
+func elemList(elems []string, indent, closeIndent string) string {
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, elem := range elems {
+		fmt.Fprintf(&b, "%s%s,\n", indent, elem)
+	}
+	b.WriteString(closeIndent)
+	return b.String()
+}
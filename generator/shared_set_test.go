@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitInjectorSets(t *testing.T) {
+	dir := "../testdata/multi"
+	_, injectors := analyzeInjectors(t, dir)
+
+	sets, err := SplitInjectorSets(filepath.Join(dir, "wire.go"), injectors)
+	if err != nil {
+		t.Fatalf("SplitInjectorSets failed: %v", err)
+	}
+
+	if want := []string{"db.NewDB", "repo.NewUserRepository"}; !reflect.DeepEqual(sets.Shared, want) {
+		t.Errorf("Shared = %v, want %v", sets.Shared, want)
+	}
+	want := []InjectorBuild{
+		{Name: "InitializeAPI", UsesShared: true, Extras: []string{"api.NewServer", `wire.Struct(new(API), "*")`}},
+		{Name: "InitializeWorker", UsesShared: true, Extras: []string{"worker.NewWorker", `wire.Struct(new(Worker), "*")`}},
+	}
+	if !reflect.DeepEqual(sets.Injectors, want) {
+		t.Errorf("Injectors = %+v, want %+v", sets.Injectors, want)
+	}
+}
+
+func TestRewriteWireFile(t *testing.T) {
+	dir := "../testdata/multi"
+	_, injectors := analyzeInjectors(t, dir)
+	wireFilePath := filepath.Join(dir, "wire.go")
+
+	sets, err := SplitInjectorSets(wireFilePath, injectors)
+	if err != nil {
+		t.Fatalf("SplitInjectorSets failed: %v", err)
+	}
+	src, err := RewriteWireFile(wireFilePath, sets)
+	if err != nil {
+		t.Fatalf("RewriteWireFile failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"var SharedSet = wire.NewSet(\n\tdb.NewDB,\n\trepo.NewUserRepository,\n)",
+		"\twire.Build(\n\t\tSharedSet,\n\t\tapi.NewServer,\n\t\twire.Struct(new(API), \"*\"),\n\t)",
+		"\twire.Build(\n\t\tSharedSet,\n\t\tworker.NewWorker,\n\t\twire.Struct(new(Worker), \"*\"),\n\t)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("rewritten wire.go does not contain %q:\n%s", want, src)
+		}
+	}
+	// 共有のプロバイダーセットは最初の注入関数の前に宣言する
+	if strings.Index(string(src), "var SharedSet") > strings.Index(string(src), "func InitializeAPI") {
+		t.Errorf("SharedSet is declared after the first injector:\n%s", src)
+	}
+}
+
+func TestRewriteWireSource_StaleSharedSet(t *testing.T) {
+	dir := "../testdata/multi"
+	_, injectors := analyzeInjectors(t, dir)
+	wireFilePath := filepath.Join(dir, "wire.go")
+
+	sets, err := SplitInjectorSets(wireFilePath, injectors)
+	if err != nil {
+		t.Fatalf("SplitInjectorSets failed: %v", err)
+	}
+	shared, err := RewriteWireFile(wireFilePath, sets)
+	if err != nil {
+		t.Fatalf("RewriteWireFile failed: %v", err)
+	}
+
+	// 共有する要素がなくなった場合は以前に生成した共有のプロバイダーセットを取り除く
+	single, err := SplitInjectorSets(wireFilePath, injectors[:1])
+	if err != nil {
+		t.Fatalf("SplitInjectorSets failed: %v", err)
+	}
+	src, err := RewriteWireSource(wireFilePath, shared, single)
+	if err != nil {
+		t.Fatalf("RewriteWireSource failed: %v", err)
+	}
+	if strings.Contains(string(src), "var SharedSet") {
+		t.Errorf("stale SharedSet is not removed:\n%s", src)
+	}
+
+	// 一部の注入関数だけを埋める場合は、他の注入関数が使う共有のプロバイダーセットを残す
+	filled, err := FillInjectorSets(wireFilePath, injectors[:1])
+	if err != nil {
+		t.Fatalf("FillInjectorSets failed: %v", err)
+	}
+	src, err = RewriteWireSource(wireFilePath, shared, filled)
+	if err != nil {
+		t.Fatalf("RewriteWireSource failed: %v", err)
+	}
+	if !strings.Contains(string(src), "var SharedSet") {
+		t.Errorf("SharedSet used by the other injector is removed:\n%s", src)
+	}
+}
@@ -0,0 +1,211 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+	file "github.com/rmocchy/convinient_wire/ast_analyzer/files"
+)
+
+// DifferenceKind は差分の種類
+type DifferenceKind string
+
+const (
+	DifferenceMissingInjector DifferenceKind = "missing-injector" // 注入関数がwire_gen.goにない
+	DifferenceExtraInjector   DifferenceKind = "extra-injector"   // wire.goにない注入関数がwire_gen.goに残っている
+	DifferenceSignature       DifferenceKind = "signature"        // 引数や返り値の型が異なる
+	DifferenceMissing         DifferenceKind = "missing"          // 必要な提供関数の呼び出しがない
+	DifferenceUnexpected      DifferenceKind = "unexpected"       // 依存グラフにない呼び出しがある
+	DifferenceReordered       DifferenceKind = "reordered"        // 呼び出し順が異なる
+	DifferenceArguments       DifferenceKind = "arguments"        // 引数に渡している値が異なる
+)
+
+// Difference は生成済みのwire_gen.goと再生成した結果の差分を表す
+type Difference struct {
+	Injector string         // 注入関数名
+	Kind     DifferenceKind // 差分の種類
+	Call     string         // 対象の提供関数（構造体リテラルの場合は型名）
+	Detail   string         // 補足情報
+}
+
+// String は差分を1行の文字列にする
+func (d Difference) String() string {
+	s := fmt.Sprintf("%s: %s", d.Injector, d.Kind)
+	if d.Call != "" {
+		s += " " + d.Call
+	}
+	if d.Detail != "" {
+		s += " (" + d.Detail + ")"
+	}
+	return s
+}
+
+// VerifyWireGen はwire.goと解析結果から注入関数をメモリ上で再生成し、生成済みのwire_gen.goと比較する
+// 書式や変数名の違いは無視し、注入関数の有無・引数と返り値の型・提供関数の呼び出しの有無・順序・引数の違いを差分として返す
+func VerifyWireGen(wireFilePath, wireGenPath string, lookup PackageLookup, injectors []*app.InjectorInfo) ([]Difference, error) {
+	src, err := GenerateWireGen(wireFilePath, lookup, injectors)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate injectors: %w", err)
+	}
+
+	expected, err := file.ParseInjectorBodies(WireGenFileName, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse generated injectors: %w", err)
+	}
+
+	actual, err := file.ParseWireGenFile(wireGenPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", wireGenPath, err)
+	}
+
+	actualByName := make(map[string]file.InjectorBody, len(actual.Injectors))
+	for _, body := range actual.Injectors {
+		actualByName[body.Name] = body
+	}
+
+	var diffs []Difference
+	expectedNames := make(map[string]bool, len(expected))
+	for _, want := range expected {
+		expectedNames[want.Name] = true
+		got, ok := actualByName[want.Name]
+		if !ok {
+			diffs = append(diffs, Difference{Injector: want.Name, Kind: DifferenceMissingInjector})
+			continue
+		}
+		diffs = append(diffs, compareSignature(want, got)...)
+		diffs = append(diffs, compareCalls(want.Name, want.Calls, got.Calls)...)
+	}
+
+	// wire.goから削除された注入関数がwire_gen.goに残っている
+	for _, got := range actual.Injectors {
+		if !expectedNames[got.Name] {
+			diffs = append(diffs, Difference{Injector: got.Name, Kind: DifferenceExtraInjector, Detail: "not declared in wire.go"})
+		}
+	}
+
+	return diffs, nil
+}
+
+// compareSignature は注入関数の引数と返り値の型を比較する（引数名の違いは無視する）
+func compareSignature(want, got file.InjectorBody) []Difference {
+	if equalStrings(want.ParamTypes, got.ParamTypes) && equalStrings(want.Results, got.Results) {
+		return nil
+	}
+	return []Difference{{
+		Injector: want.Name,
+		Kind:     DifferenceSignature,
+		Detail:   fmt.Sprintf("want %s, got %s", signatureString(want), signatureString(got)),
+	}}
+}
+
+// signatureString は引数と返り値の型を func(...) (...) の形で表す
+func signatureString(body file.InjectorBody) string {
+	s := "func(" + strings.Join(body.ParamTypes, ", ") + ")"
+	switch len(body.Results) {
+	case 0:
+	case 1:
+		s += " " + body.Results[0]
+	default:
+		s += " (" + strings.Join(body.Results, ", ") + ")"
+	}
+	return s
+}
+
+// compareCalls は1つの注入関数について、期待する呼び出しと実際の呼び出しを比較する
+func compareCalls(injector string, want, got []file.ProviderCall) []Difference {
+	var diffs []Difference
+
+	wantByFunc := make(map[string]file.ProviderCall, len(want))
+	for _, call := range want {
+		wantByFunc[call.Func] = call
+	}
+	gotByFunc := make(map[string]file.ProviderCall, len(got))
+	for _, call := range got {
+		gotByFunc[call.Func] = call
+	}
+
+	// 両方に含まれる呼び出しの順序
+	var wantOrder, gotOrder []string
+	for _, call := range want {
+		if _, ok := gotByFunc[call.Func]; ok {
+			wantOrder = append(wantOrder, call.Func)
+		} else {
+			diffs = append(diffs, Difference{Injector: injector, Kind: DifferenceMissing, Call: call.Func})
+		}
+	}
+	for _, call := range got {
+		if _, ok := wantByFunc[call.Func]; ok {
+			gotOrder = append(gotOrder, call.Func)
+		} else {
+			diffs = append(diffs, Difference{Injector: injector, Kind: DifferenceUnexpected, Call: call.Func})
+		}
+	}
+
+	// 最長共通部分列に含まれない呼び出しを順序が異なるものとして報告する
+	inOrder := longestCommonSubsequence(wantOrder, gotOrder)
+	for _, fn := range wantOrder {
+		if !inOrder[fn] {
+			diffs = append(diffs, Difference{Injector: injector, Kind: DifferenceReordered, Call: fn})
+		}
+	}
+
+	for _, fn := range wantOrder {
+		w, g := wantByFunc[fn], gotByFunc[fn]
+		if !equalStrings(w.Args, g.Args) || !equalStrings(w.Fields, g.Fields) {
+			diffs = append(diffs, Difference{
+				Injector: injector,
+				Kind:     DifferenceArguments,
+				Call:     fn,
+				Detail:   fmt.Sprintf("want (%s), got (%s)", strings.Join(w.Args, ", "), strings.Join(g.Args, ", ")),
+			})
+		}
+	}
+
+	return diffs
+}
+
+// longestCommonSubsequence は2つの列の最長共通部分列に含まれる要素を返す
+func longestCommonSubsequence(a, b []string) map[string]bool {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	common := make(map[string]bool)
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			common[a[i]] = true
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return common
+}
+
+// equalStrings は2つのスライスの要素が順序も含めて等しいかを判定する
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
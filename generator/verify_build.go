@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+// VerifyWireBuild はwire.goの各注入関数のwire.Buildの要素を解析結果と比較する
+// wire.NewSetとして宣言したプロバイダーセットは、wire.goと他のパッケージのどちらの宣言も要素を展開して比較し、
+// 書式の違いは無視して、不足している要素と解析結果にない要素を差分として返す
+// 他のパッケージのプロバイダーセットは複数の注入関数で使うため、その要素は解析結果になくても報告しない
+// 展開できない要素（非公開の提供関数などwire.goから参照できない要素）は何を提供するか分からないため、
+// その注入関数では不足している要素を報告しない
+func VerifyWireBuild(wireFilePath string, lookup PackageLookup, injectors []*app.InjectorInfo) ([]Difference, error) {
+	sets, err := FillInjectorSets(wireFilePath, injectors)
+	if err != nil {
+		return nil, err
+	}
+
+	file, funcDecls, err := parseWireFuncs(wireFilePath)
+	if err != nil {
+		return nil, err
+	}
+	resolver := newSetResolver(file, sets.imports, lookup)
+
+	var diffs []Difference
+	for _, build := range sets.Injectors {
+		funcDecl, ok := funcDecls[build.Name]
+		if !ok {
+			diffs = append(diffs, Difference{Injector: build.Name, Kind: DifferenceMissingInjector})
+			continue
+		}
+		call := findWireBuild(file, funcDecl)
+		if call == nil {
+			return nil, fmt.Errorf("wire.Build not found in %s", build.Name)
+		}
+
+		actualElems := resolver.expandBuild(call.Args)
+		actual := make(map[string]bool, len(actualElems))
+		hasOpaque := false
+		for _, elem := range actualElems {
+			actual[elem.text] = true
+			hasOpaque = hasOpaque || elem.opaque
+		}
+		expected := make(map[string]bool)
+		for _, elem := range build.Args() {
+			expected[normalizeElem(elem)] = true
+		}
+
+		if !hasOpaque {
+			for _, elem := range build.Args() {
+				if key := normalizeElem(elem); !actual[key] {
+					diffs = append(diffs, Difference{Injector: build.Name, Kind: DifferenceMissing, Call: key, Detail: "not in wire.Build"})
+				}
+			}
+		}
+		for _, elem := range actualElems {
+			if !elem.opaque && !elem.inSet && !expected[elem.text] {
+				diffs = append(diffs, Difference{Injector: build.Name, Kind: DifferenceUnexpected, Call: elem.text, Detail: "not used by the dependency graph"})
+			}
+		}
+	}
+
+	return diffs, nil
+}
+
+// PreserveProviderSets は書き換え後のwire.Buildに、wire.goで参照している他のパッケージのプロバイダーセットを残す
+// プロバイダーセットが提供する要素は注入関数ごとの要素から取り除き、展開できない要素を含む注入関数は書き換えの対象から外す
+func PreserveProviderSets(wireFilePath string, lookup PackageLookup, sets *InjectorSets) error {
+	file, funcDecls, err := parseWireFuncs(wireFilePath)
+	if err != nil {
+		return err
+	}
+	resolver := newSetResolver(file, sets.imports, lookup)
+
+	var builds []InjectorBuild
+	for _, build := range sets.Injectors {
+		funcDecl, ok := funcDecls[build.Name]
+		if !ok {
+			builds = append(builds, build)
+			continue
+		}
+		call := findWireBuild(file, funcDecl)
+		if call == nil {
+			return fmt.Errorf("wire.Build not found in %s", build.Name)
+		}
+
+		var kept []string
+		provided := make(map[string]bool)
+		opaque := false
+		for _, elem := range resolver.expandBuild(call.Args) {
+			opaque = opaque || elem.opaque
+			if elem.foreign {
+				kept = appendUnique(kept, elem.origin)
+				provided[elem.text] = true
+			}
+		}
+		if opaque {
+			continue
+		}
+
+		extras := kept
+		for _, elem := range build.Extras {
+			if !provided[normalizeElem(elem)] {
+				extras = append(extras, elem)
+			}
+		}
+		build.Extras = extras
+		builds = append(builds, build)
+	}
+	sets.Injectors = builds
+	return nil
+}
+
+// parseWireFuncs はwire.goを読み込み、関数名 -> 関数宣言を返す
+func parseWireFuncs(wireFilePath string) (*ast.File, map[string]*ast.FuncDecl, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), wireFilePath, nil, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse wire file: %w", err)
+	}
+	funcDecls := make(map[string]*ast.FuncDecl)
+	for _, decl := range file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+			funcDecls[funcDecl.Name.Name] = funcDecl
+		}
+	}
+	return file, funcDecls, nil
+}
+
+// normalizeElem は生成したwire.Buildの要素をwire.goの引数と同じ書式にする
+func normalizeElem(elem string) string {
+	expr, err := parser.ParseExpr(elem)
+	if err != nil {
+		return elem
+	}
+	return types.ExprString(expr)
+}
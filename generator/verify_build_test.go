@@ -0,0 +1,160 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVerifyWireBuild(t *testing.T) {
+	t.Run("up to date", func(t *testing.T) {
+		dir := "../sample/basic"
+		analyzer, injectors := analyzeInjectors(t, dir)
+
+		diffs, err := VerifyWireBuild(filepath.Join(dir, "wire.go"), analyzer, injectors)
+		if err != nil {
+			t.Fatalf("VerifyWireBuild failed: %v", err)
+		}
+		if len(diffs) != 0 {
+			t.Errorf("diffs = %v, want none", diffs)
+		}
+	})
+
+	t.Run("missing elements", func(t *testing.T) {
+		dir := "../testdata/lsp"
+		analyzer, injectors := analyzeInjectors(t, dir)
+		wireFilePath := filepath.Join(dir, "wire.go")
+
+		diffs, err := VerifyWireBuild(wireFilePath, analyzer, injectors)
+		if err != nil {
+			t.Fatalf("VerifyWireBuild failed: %v", err)
+		}
+		want := []Difference{
+			{Injector: "InitializeApp", Kind: DifferenceMissing, Call: "repo.NewSQLUserRepository", Detail: "not in wire.Build"},
+			{Injector: "InitializeApp", Kind: DifferenceMissing, Call: "wire.Bind(new(repo.UserRepository), new(*repo.SQLUserRepository))", Detail: "not in wire.Build"},
+			{Injector: "InitializeApp", Kind: DifferenceMissing, Call: `wire.Struct(new(App), "*")`, Detail: "not in wire.Build"},
+		}
+		if !reflect.DeepEqual(diffs, want) {
+			t.Errorf("diffs = %v, want %v", diffs, want)
+		}
+
+		// 依存グラフにない要素は不要な要素として報告する
+		src, err := os.ReadFile(wireFilePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		extraPath := filepath.Join(t.TempDir(), "wire.go")
+		extra := strings.Replace(string(src), "wire.Build(service.NewUserService)", "wire.Build(service.NewUserService, service.NewAdminService)", 1)
+		if err := os.WriteFile(extraPath, []byte(extra), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		diffs, err = VerifyWireBuild(extraPath, analyzer, injectors)
+		if err != nil {
+			t.Fatalf("VerifyWireBuild failed: %v", err)
+		}
+		unexpected := Difference{Injector: "InitializeApp", Kind: DifferenceUnexpected, Call: "service.NewAdminService", Detail: "not used by the dependency graph"}
+		if diffs[len(diffs)-1] != unexpected {
+			t.Errorf("last diff = %v, want %v", diffs[len(diffs)-1], unexpected)
+		}
+	})
+
+	t.Run("shared provider set", func(t *testing.T) {
+		dir := "../testdata/multi"
+		analyzer, injectors := analyzeInjectors(t, dir)
+		wireFilePath := filepath.Join(dir, "wire.go")
+
+		// 共有のプロバイダーセットにまとめたwire.goはセットを展開して比較する
+		sets, err := SplitInjectorSets(wireFilePath, injectors)
+		if err != nil {
+			t.Fatalf("SplitInjectorSets failed: %v", err)
+		}
+		src, err := RewriteWireFile(wireFilePath, sets)
+		if err != nil {
+			t.Fatalf("RewriteWireFile failed: %v", err)
+		}
+		sharedPath := filepath.Join(t.TempDir(), "wire.go")
+		if err := os.WriteFile(sharedPath, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		diffs, err := VerifyWireBuild(sharedPath, analyzer, injectors)
+		if err != nil {
+			t.Fatalf("VerifyWireBuild failed: %v", err)
+		}
+		if len(diffs) != 0 {
+			t.Errorf("diffs = %v, want none", diffs)
+		}
+	})
+
+	t.Run("provider set in another package", func(t *testing.T) {
+		dir := "../testdata/pkgsets"
+		analyzer, injectors := analyzeInjectors(t, dir)
+		wireFilePath := filepath.Join(dir, "wire.go")
+
+		// 他のパッケージのプロバイダーセットは入れ子のセットも含めて展開して比較する
+		diffs, err := VerifyWireBuild(wireFilePath, analyzer, injectors)
+		if err != nil {
+			t.Fatalf("VerifyWireBuild failed: %v", err)
+		}
+		if len(diffs) != 0 {
+			t.Errorf("diffs = %v, want none", diffs)
+		}
+
+		src, err := os.ReadFile(wireFilePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		missingPath := filepath.Join(t.TempDir(), "wire.go")
+		missing := strings.Replace(string(src), "\t\tservice.NewUserService,\n", "", 1)
+		if err := os.WriteFile(missingPath, []byte(missing), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		diffs, err = VerifyWireBuild(missingPath, analyzer, injectors)
+		if err != nil {
+			t.Fatalf("VerifyWireBuild failed: %v", err)
+		}
+		want := []Difference{{Injector: "InitializeApp", Kind: DifferenceMissing, Call: "service.NewUserService", Detail: "not in wire.Build"}}
+		if !reflect.DeepEqual(diffs, want) {
+			t.Errorf("diffs = %v, want %v", diffs, want)
+		}
+
+		// 書き換えてもプロバイダーセットは残し、セットが提供する要素は追加しない
+		sets, err := FillInjectorSets(missingPath, injectors)
+		if err != nil {
+			t.Fatalf("FillInjectorSets failed: %v", err)
+		}
+		if err := PreserveProviderSets(missingPath, analyzer, sets); err != nil {
+			t.Fatalf("PreserveProviderSets failed: %v", err)
+		}
+		if got, want := sets.Injectors[0].Args(), []string{"repo.ProviderSet", "service.NewUserService", `wire.Struct(new(App), "*")`}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Args() = %v, want %v", got, want)
+		}
+
+		// 非公開の提供関数を含むセットは展開できないため、報告も書き換えもしない
+		opaquePath := filepath.Join(t.TempDir(), "wire.go")
+		opaque := strings.Replace(missing, "\t\trepo.ProviderSet,\n", "\t\trepo.ProviderSet,\n\t\tnotify.ProviderSet,\n", 1)
+		opaque = strings.Replace(opaque, `"example.com/pkgsets/repo"`, "\"example.com/pkgsets/notify\"\n\t\"example.com/pkgsets/repo\"", 1)
+		if err := os.WriteFile(opaquePath, []byte(opaque), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		diffs, err = VerifyWireBuild(opaquePath, analyzer, injectors)
+		if err != nil {
+			t.Fatalf("VerifyWireBuild failed: %v", err)
+		}
+		if len(diffs) != 0 {
+			t.Errorf("diffs = %v, want none", diffs)
+		}
+		sets, err = FillInjectorSets(opaquePath, injectors)
+		if err != nil {
+			t.Fatalf("FillInjectorSets failed: %v", err)
+		}
+		if err := PreserveProviderSets(opaquePath, analyzer, sets); err != nil {
+			t.Fatalf("PreserveProviderSets failed: %v", err)
+		}
+		if len(sets.Injectors) != 0 {
+			t.Errorf("Injectors = %v, want none to rewrite", sets.Injectors)
+		}
+	})
+}
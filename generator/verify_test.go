@@ -0,0 +1,229 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+func TestVerifyWireGen(t *testing.T) {
+	dir := "../sample/basic"
+	analyzer, injectors := analyzeInjectors(t, dir)
+	wireFilePath := filepath.Join(dir, "wire.go")
+
+	committed, err := os.ReadFile(filepath.Join(dir, "wire_gen.go"))
+	if err != nil {
+		t.Fatalf("failed to read wire_gen.go: %v", err)
+	}
+
+	// writeWireGen はwire_gen.goを書き換えたファイルを一時ディレクトリに作成する
+	writeWireGen := func(t *testing.T, replacer *strings.Replacer) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), WireGenFileName)
+		if err := os.WriteFile(path, []byte(replacer.Replace(string(committed))), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	const base = "github.com/rmocchy/convinient_wire/sample/basic/"
+
+	tests := []struct {
+		name     string
+		replacer *strings.Replacer
+		want     []Difference
+	}{
+		{
+			// 変数名の違いは無視する
+			name:     "up to date",
+			replacer: strings.NewReplacer("userService", "svc"),
+			want:     nil,
+		},
+		{
+			name: "missing call",
+			replacer: strings.NewReplacer(
+				"\tuserService := service.NewUserService(userRepository)\n", "",
+				"NewUserHandler(userService)", "NewUserHandler(nil)",
+			),
+			want: []Difference{
+				{Injector: "InitializeUserHandler", Kind: DifferenceMissing, Call: base + "service.NewUserService"},
+				{Injector: "InitializeUserHandler", Kind: DifferenceArguments, Call: base + "handler.NewUserHandler",
+					Detail: "want (" + base + "service.NewUserService), got (nil)"},
+			},
+		},
+		{
+			name: "reordered call",
+			replacer: strings.NewReplacer(
+				"\tuserHandler := handler.NewUserHandler(userService)\n", "",
+				"\tcontrollerSet := &ControllerSet{", "\tuserHandler := handler.NewUserHandler(userService)\n\tcontrollerSet := &ControllerSet{",
+				"\tuserService := service.NewUserService(userRepository)\n", "",
+				"\tconfig := repository.NewConfig()\n", "\tuserService := service.NewUserService(userRepository)\n\tconfig := repository.NewConfig()\n",
+			),
+			want: []Difference{
+				{Injector: "InitializeUserHandler", Kind: DifferenceReordered, Call: base + "service.NewUserService"},
+			},
+		},
+		{
+			// wire.goから削除された注入関数が残っている
+			name: "stale injector",
+			replacer: strings.NewReplacer(
+				"// wire.go:", "func InitializeConfig() *repository.Config {\n\tconfig := repository.NewConfig()\n\treturn config\n}\n\n// wire.go:",
+			),
+			want: []Difference{
+				{Injector: "InitializeConfig", Kind: DifferenceExtraInjector, Detail: "not declared in wire.go"},
+			},
+		},
+		{
+			// 引数名の違いは無視し、引数と返り値の型の違いを報告する
+			name: "signature drift",
+			replacer: strings.NewReplacer(
+				"func InitializeUserHandler() (*ControllerSet, error) {", "func InitializeUserHandler(name string) *ControllerSet {",
+			),
+			want: []Difference{
+				{Injector: "InitializeUserHandler", Kind: DifferenceSignature,
+					Detail: "want func() (*ControllerSet, error), got func(string) *ControllerSet"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs, err := VerifyWireGen(wireFilePath, writeWireGen(t, tt.replacer), analyzer, injectors)
+			if err != nil {
+				t.Fatalf("VerifyWireGen failed: %v", err)
+			}
+			if !reflect.DeepEqual(diffs, tt.want) {
+				t.Errorf("VerifyWireGen() = %v\nwant %v", diffs, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyWireGen_WireNaming(t *testing.T) {
+	// wireが生成したwire_gen.goの変数名（string2, _wireClientValueなど）は、このツールの変数名と違っても同じ値として扱う
+	writeWireGen := func(t *testing.T, src string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), WireGenFileName)
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("FieldsOf", func(t *testing.T) {
+		dir := "../testdata/fieldsof"
+		analyzer, injectors := analyzeInjectors(t, dir)
+		wireGen := writeWireGen(t, `// Code generated by Wire. DO NOT EDIT.
+
+//go:generate go run -mod=mod github.com/google/wire/cmd/wire
+//go:build !wireinject
+// +build !wireinject
+
+package main
+
+import (
+	"example.com/fieldsof/config"
+	"example.com/fieldsof/db"
+	"example.com/fieldsof/repo"
+)
+
+// Injectors from wire.go:
+
+func InitializeApp() *App {
+	configConfig := config.NewConfig()
+	string2 := configConfig.DSN
+	int2 := configConfig.MaxPoolSize
+	dbDB := db.NewDB(string2, int2)
+	repository := repo.NewRepository(dbDB, configConfig)
+	app := &App{
+		repo: repository,
+	}
+	return app
+}
+
+// wire.go:
+
+type App struct {
+	repo *repo.Repository
+}
+`)
+
+		diffs, err := VerifyWireGen(filepath.Join(dir, "wire.go"), wireGen, analyzer, injectors)
+		if err != nil {
+			t.Fatalf("VerifyWireGen failed: %v", err)
+		}
+		if len(diffs) != 0 {
+			t.Errorf("VerifyWireGen() = %v, want no differences", diffs)
+		}
+	})
+
+	t.Run("Value", func(t *testing.T) {
+		dir := "../testdata/values"
+		var values []app.Value
+		for _, spec := range []string{"*net/http.Client=net/http.DefaultClient", "io.Writer=os.Stdout"} {
+			value, err := app.ParseValue(spec)
+			if err != nil {
+				t.Fatalf("ParseValue failed: %v", err)
+			}
+			values = append(values, value)
+		}
+		analyzer := app.NewWireAnalyzer(dir, "./...", app.WithValues(values...))
+		injectors, err := analyzer.AnalyzeInjectors(filepath.Join(dir, "wire.go"))
+		if err != nil {
+			t.Fatalf("AnalyzeInjectors failed: %v", err)
+		}
+		wireGen := writeWireGen(t, `// Code generated by Wire. DO NOT EDIT.
+
+//go:generate go run -mod=mod github.com/google/wire/cmd/wire
+//go:build !wireinject
+// +build !wireinject
+
+package main
+
+import (
+	"example.com/values/api"
+	"example.com/values/logger"
+	"net/http"
+	"os"
+)
+
+// Injectors from wire.go:
+
+func InitializeApp() *App {
+	writer := _wireFileValue
+	client := _wireClientValue
+	loggerLogger := logger.NewLogger(writer)
+	apiClient := api.NewClient(client)
+	app := &App{
+		logger: loggerLogger,
+		client: apiClient,
+	}
+	return app
+}
+
+var (
+	_wireFileValue   = os.Stdout
+	_wireClientValue = http.DefaultClient
+)
+
+// wire.go:
+
+type App struct {
+	logger *logger.Logger
+	client *api.Client
+}
+`)
+
+		diffs, err := VerifyWireGen(filepath.Join(dir, "wire.go"), wireGen, analyzer, injectors)
+		if err != nil {
+			t.Fatalf("VerifyWireGen failed: %v", err)
+		}
+		if len(diffs) != 0 {
+			t.Errorf("VerifyWireGen() = %v, want no differences", diffs)
+		}
+	})
+}
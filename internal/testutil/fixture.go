@@ -0,0 +1,26 @@
+// Package testutil はパッケージをまたいで使うテスト用のヘルパーを提供する
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// CopyFixture はtestdataのフィクスチャのモジュールを書き換えられるよう一時ディレクトリにコピーする
+// テストのパッケージの位置によらず、リポジトリのtestdataから探す
+func CopyFixture(t testing.TB, name string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	if err := os.CopyFS(dir, os.DirFS(fixtureDir(name))); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// fixtureDir はtestdataのフィクスチャのディレクトリを返す
+func fixtureDir(name string) string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "testdata", name)
+}
@@ -0,0 +1,221 @@
+package lsp
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+	"github.com/rmocchy/convinient_wire/generator"
+)
+
+// コードアクションのタイトル
+const (
+	titleFillBuild       = "Fill wire.Build from analysis"
+	titleMissingProvider = "Add missing provider for %s"
+	titleBind            = "Add wire.Bind for interface %s"
+)
+
+// codeActions は範囲にある注入関数に対するコードアクションを返す
+func (s *Server) codeActions(params CodeActionParams) ([]CodeAction, error) {
+	actions := []CodeAction{}
+
+	wd, err := s.wireDoc(params.TextDocument.URI)
+	if err != nil || wd == nil {
+		return actions, err
+	}
+	res, err := s.analyze(wd.path)
+	if err != nil {
+		return nil, err
+	}
+
+	decls := wd.injectorsIn(wd.offset(params.Range.Start), wd.offset(params.Range.End))
+	seen := make(map[string]bool)
+	for _, decl := range decls {
+		injector := res.injector(decl.decl.Name.Name)
+		if injector == nil || injector.Graph == nil {
+			continue
+		}
+
+		// 解析結果でwire.Buildの引数を置き換える
+		fill, err := s.fillAction(wd, injector)
+		if err != nil {
+			return nil, err
+		}
+		if len(decls) > 1 {
+			fill.Title += " (" + injector.Name + ")"
+		}
+		actions = append(actions, fill)
+
+		q := newQualifier(wd, res.analyzer)
+		args := wd.text[wd.offsetOf(decl.build.Lparen):wd.offsetOf(decl.build.Rparen)]
+
+		// 提供関数がない構造体はwire.Structでフィールドから組み立てる
+		for _, node := range missingProviders(injector.Root) {
+			ref := app.TypeRef{TypeName: node.StructName, PackagePath: node.PackagePath}
+			typeName := q.qualify(ref)
+			title := fmt.Sprintf(titleMissingProvider, typeName)
+			if seen[title] || strings.Contains(args, "new("+typeName+")") {
+				continue
+			}
+			seen[title] = true
+			actions = append(actions, wd.insertAction(title, decl, fmt.Sprintf("wire.Struct(new(%s), \"*\")", typeName), q))
+		}
+
+		// 実装型で満たすインターフェースはwire.Bindで結びつける
+		for _, binding := range injector.Graph.Bindings {
+			ifaceName := q.qualify(binding.Interface)
+			title := fmt.Sprintf(titleBind, ifaceName)
+			if seen[title] || strings.Contains(args, "new("+ifaceName+")") {
+				continue
+			}
+			seen[title] = true
+			expr := fmt.Sprintf("wire.Bind(new(%s), new(*%s))", ifaceName, q.qualify(binding.Impl))
+			actions = append(actions, wd.insertAction(title, decl, expr, q))
+		}
+	}
+
+	return actions, nil
+}
+
+// fillAction は注入関数のwire.Buildを解析結果の要素に置き換えるコードアクションを作る
+func (s *Server) fillAction(wd *wireDoc, injector *app.InjectorInfo) (CodeAction, error) {
+	sets, err := generator.FillInjectorSets(wd.path, []*app.InjectorInfo{injector})
+	if err != nil {
+		return CodeAction{}, err
+	}
+	src, err := generator.RewriteWireSource(wd.path, []byte(wd.text), sets)
+	if err != nil {
+		return CodeAction{}, err
+	}
+
+	return CodeAction{
+		Title: titleFillBuild,
+		Kind:  CodeActionRewrite,
+		Edit: &WorkspaceEdit{Changes: map[string][]TextEdit{
+			wd.uri: {{Range: wd.rangeOf(0, len(wd.text)), NewText: string(src)}},
+		}},
+	}, nil
+}
+
+// insertAction はwire.Buildの最後の引数の後に式を追加し、必要なimportを加えるコードアクションを作る
+func (wd *wireDoc) insertAction(title string, decl *injectorDecl, expr string, q *qualifier) CodeAction {
+	build := decl.build
+	var edit TextEdit
+	if len(build.Args) == 0 {
+		offset := wd.offsetOf(build.Lparen) + 1
+		edit = TextEdit{Range: wd.rangeOf(offset, offset), NewText: expr}
+	} else {
+		offset := wd.offsetOf(build.Args[len(build.Args)-1].End())
+		edit = TextEdit{Range: wd.rangeOf(offset, offset), NewText: ", " + expr}
+	}
+
+	edits := []TextEdit{edit}
+	for _, importPath := range q.missing {
+		edits = append(edits, wd.importEdit(importPath, q.names[importPath]))
+	}
+	q.missing = nil
+
+	return CodeAction{
+		Title: title,
+		Kind:  CodeActionQuickFix,
+		Edit:  &WorkspaceEdit{Changes: map[string][]TextEdit{wd.uri: edits}},
+	}
+}
+
+// importEdit はimportを追加する編集を作る
+func (wd *wireDoc) importEdit(importPath, name string) TextEdit {
+	spec := strconv.Quote(importPath)
+	if name != path.Base(importPath) {
+		spec = name + " " + spec
+	}
+
+	var last *ast.GenDecl
+	for _, decl := range wd.file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			last = gen
+		}
+	}
+
+	var offset int
+	var text string
+	switch {
+	case last == nil:
+		offset, text = wd.offsetOf(wd.file.Name.End()), "\n\nimport "+spec
+	case last.Lparen.IsValid():
+		offset, text = wd.offsetOf(last.Rparen), "\t"+spec+"\n"
+	default:
+		offset, text = wd.offsetOf(last.End()), "\nimport "+spec
+	}
+	return TextEdit{Range: wd.rangeOf(offset, offset), NewText: text}
+}
+
+// qualifier はwire.goのimport名で型を修飾し、追加が必要なimportを記録する
+type qualifier struct {
+	selfPath string
+	names    map[string]string // importパス -> 参照に使う名前
+	missing  []string          // 追加が必要なimport
+	lookup   generator.PackageLookup
+}
+
+// newQualifier はドキュメントのimportからqualifierを作成する
+func newQualifier(wd *wireDoc, lookup generator.PackageLookup) *qualifier {
+	q := &qualifier{names: make(map[string]string), lookup: lookup}
+	for importPath, name := range wd.imports {
+		q.names[importPath] = name
+	}
+	if _, pkgPath, err := app.ResolvePackage(path.Dir(wd.path)); err == nil {
+		q.selfPath = pkgPath
+	}
+	return q
+}
+
+// qualify はwire.goから参照する形の型名を返す
+func (q *qualifier) qualify(ref app.TypeRef) string {
+	if ref.PackagePath == "" || ref.PackagePath == q.selfPath {
+		return ref.TypeName
+	}
+	name, ok := q.names[ref.PackagePath]
+	if !ok {
+		name = path.Base(ref.PackagePath)
+		if pkgName, _, found := q.lookup.LookupPackage(ref.PackagePath); found {
+			name = pkgName
+		}
+		q.names[ref.PackagePath] = name
+		q.missing = append(q.missing, ref.PackagePath)
+	}
+	return name + "." + ref.TypeName
+}
+
+// missingProviders はツリーから提供関数のない構造体を探す（ルートはwire.Structで組み立てるため除く）
+func missingProviders(root *app.StructNode) []*app.StructNode {
+	var found []*app.StructNode
+	seen := map[*app.StructNode]bool{root: true}
+
+	var walk func(fields []app.FieldNode)
+	walk = func(fields []app.FieldNode) {
+		for _, field := range fields {
+			var node *app.StructNode
+			switch f := field.(type) {
+			case *app.StructNode:
+				node = f
+			case *app.InterfaceNode:
+				node = f.ResolvedStruct
+			}
+			if node == nil || seen[node] || node.Skipped {
+				continue
+			}
+			seen[node] = true
+			if len(node.InitFunctions) == 0 {
+				found = append(found, node)
+			}
+			walk(node.Fields)
+		}
+	}
+	walk(root.Fields)
+
+	return found
+}
@@ -0,0 +1,184 @@
+package lsp
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// wireImportPath はwireパッケージのimportパス
+const wireImportPath = "github.com/google/wire"
+
+// document はエディタで開かれているドキュメント
+type document struct {
+	uri   string
+	path  string
+	text  string
+	lines []int // 各行の先頭のバイトオフセット
+}
+
+// newDocument はURIとテキストからドキュメントを作成する
+func newDocument(uri, text string) (*document, error) {
+	filePath, err := uriToPath(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return &document{uri: uri, path: filePath, text: text, lines: lines}, nil
+}
+
+// position はバイトオフセットをLSPの位置（UTF-16のコード単位）に変換する
+func (d *document) position(offset int) Position {
+	line := 0
+	for line+1 < len(d.lines) && d.lines[line+1] <= offset {
+		line++
+	}
+
+	character := 0
+	for _, r := range d.text[d.lines[line]:offset] {
+		character += utf16.RuneLen(r)
+	}
+	return Position{Line: line, Character: character}
+}
+
+// offset はLSPの位置をバイトオフセットに変換する（範囲外の場合は行末や末尾に丸める）
+func (d *document) offset(pos Position) int {
+	if pos.Line >= len(d.lines) {
+		return len(d.text)
+	}
+
+	offset := d.lines[pos.Line]
+	for character := 0; character < pos.Character && offset < len(d.text); {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		if r == '\n' {
+			break
+		}
+		character += utf16.RuneLen(r)
+		offset += size
+	}
+	return offset
+}
+
+// rangeOf はバイトオフセットの範囲をLSPの範囲に変換する
+func (d *document) rangeOf(start, end int) Range {
+	return Range{Start: d.position(start), End: d.position(end)}
+}
+
+// wireDoc はwire.Buildを呼び出す注入関数を含むドキュメントの構文木
+type wireDoc struct {
+	*document
+	fset      *token.FileSet
+	file      *ast.File
+	imports   map[string]string // importパス -> 参照に使う名前
+	injectors []*injectorDecl
+}
+
+// injectorDecl はwire.Buildを呼び出す注入関数の宣言
+type injectorDecl struct {
+	decl  *ast.FuncDecl
+	build *ast.CallExpr
+}
+
+// parseWireDoc はドキュメントを解析し、wire.Buildを呼び出す関数を探す
+func parseWireDoc(doc *document) (*wireDoc, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, doc.path, doc.text, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", doc.path, err)
+	}
+
+	wd := &wireDoc{document: doc, fset: fset, file: file, imports: make(map[string]string)}
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		wd.imports[importPath] = name
+	}
+
+	wireName, ok := wd.imports[wireImportPath]
+	if !ok {
+		return wd, nil
+	}
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Body == nil {
+			continue
+		}
+		if build := findBuildCall(funcDecl.Body, wireName); build != nil {
+			wd.injectors = append(wd.injectors, &injectorDecl{decl: funcDecl, build: build})
+		}
+	}
+	return wd, nil
+}
+
+// offsetOf は構文木の位置をバイトオフセットに変換する
+func (wd *wireDoc) offsetOf(pos token.Pos) int {
+	return wd.fset.Position(pos).Offset
+}
+
+// injectorsIn は範囲と重なる注入関数を返す（重なるものがなければ全ての注入関数）
+func (wd *wireDoc) injectorsIn(start, end int) []*injectorDecl {
+	var found []*injectorDecl
+	for _, inj := range wd.injectors {
+		if wd.offsetOf(inj.decl.Pos()) <= end && start <= wd.offsetOf(inj.decl.End()) {
+			found = append(found, inj)
+		}
+	}
+	if len(found) == 0 {
+		return wd.injectors
+	}
+	return found
+}
+
+// findBuildCall はwire.Buildの呼び出しを探す
+func findBuildCall(body *ast.BlockStmt, wireName string) *ast.CallExpr {
+	var found *ast.CallExpr
+	ast.Inspect(body, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Build" {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == wireName {
+				found = call
+			}
+		}
+		return found == nil
+	})
+	return found
+}
+
+// uriToPath はfileスキームのURIをファイルパスに変換する
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid document URI %q: %w", uri, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported document URI %q", uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+// pathToURI はファイルパスをfileスキームのURIに変換する
+func pathToURI(filePath string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(filePath)}).String()
+}
@@ -0,0 +1,276 @@
+package lsp
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+// hoverTarget はホバーした識別子が指す型または提供関数
+type hoverTarget struct {
+	packagePath string
+	name        string
+	start, end  int // 識別子のバイトオフセットの範囲
+}
+
+// hover はカーソル位置の型について解析した依存関係のツリーを返す（見つからない場合はnil）
+func (s *Server) hover(params TextDocumentPositionParams) (*Hover, error) {
+	wd, err := s.wireDoc(params.TextDocument.URI)
+	if err != nil || wd == nil {
+		return nil, err
+	}
+
+	target := wd.targetAt(wd.offset(params.Position))
+	if target == nil {
+		return nil, nil
+	}
+
+	res, err := s.analyze(wd.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var node *app.StructNode
+	var iface *app.InterfaceNode
+	for _, injector := range res.injectors {
+		if injector.Root == nil {
+			continue
+		}
+		// 注入関数名の場合はルートのツリーを表示する
+		if target.packagePath == injector.PackagePath && target.name == injector.Name {
+			node = injector.Root
+			break
+		}
+		if node, iface = findNode(injector.Root, target); node != nil || iface != nil {
+			break
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("```text\n")
+	switch {
+	case iface != nil:
+		writeInterfaceTree(&b, iface, 0, make(map[*app.StructNode]bool))
+	case node != nil:
+		writeStructTree(&b, node, 0, make(map[*app.StructNode]bool))
+	default:
+		return nil, nil
+	}
+	b.WriteString("```\n")
+	writeDefinitions(&b, node, iface)
+
+	r := wd.rangeOf(target.start, target.end)
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: b.String()}, Range: &r}, nil
+}
+
+// targetAt はオフセットにある識別子が指すパッケージパスと名前を求める
+func (wd *wireDoc) targetAt(offset int) *hoverTarget {
+	tokFile := wd.fset.File(wd.file.Pos())
+	if offset < 0 || offset > tokFile.Size() {
+		return nil
+	}
+	pos := tokFile.Pos(offset)
+
+	pathNodes, _ := astutil.PathEnclosingInterval(wd.file, pos, pos)
+	if len(pathNodes) == 0 {
+		return nil
+	}
+	ident, ok := pathNodes[0].(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	// pkg.Name の形であればimportからパッケージパスを求める
+	var sel *ast.SelectorExpr
+	if len(pathNodes) > 1 {
+		sel, _ = pathNodes[1].(*ast.SelectorExpr)
+	}
+	if sel != nil {
+		pkgIdent, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		for importPath, name := range wd.imports {
+			if name == pkgIdent.Name {
+				return &hoverTarget{
+					packagePath: importPath,
+					name:        sel.Sel.Name,
+					start:       wd.offsetOf(sel.Pos()),
+					end:         wd.offsetOf(sel.End()),
+				}
+			}
+		}
+		return nil
+	}
+
+	// 修飾されていない識別子はwire.goのパッケージで宣言されたもの
+	_, pkgPath, err := app.ResolvePackage(path.Dir(wd.path))
+	if err != nil {
+		return nil
+	}
+	return &hoverTarget{
+		packagePath: pkgPath,
+		name:        ident.Name,
+		start:       wd.offsetOf(ident.Pos()),
+		end:         wd.offsetOf(ident.End()),
+	}
+}
+
+// findNode はツリーから型名または提供関数名が一致するノードを探す
+func findNode(root *app.StructNode, target *hoverTarget) (*app.StructNode, *app.InterfaceNode) {
+	seen := make(map[*app.StructNode]bool)
+
+	var walk func(node *app.StructNode) (*app.StructNode, *app.InterfaceNode)
+	walk = func(node *app.StructNode) (*app.StructNode, *app.InterfaceNode) {
+		if node == nil || seen[node] {
+			return nil, nil
+		}
+		seen[node] = true
+
+		if node.PackagePath == target.packagePath && node.StructName == target.name {
+			return node, nil
+		}
+		for _, fn := range node.InitFunctions {
+			if fn.PackagePath == target.packagePath && fn.Name == target.name {
+				return node, nil
+			}
+		}
+
+		for _, field := range node.Fields {
+			switch f := field.(type) {
+			case *app.StructNode:
+				if found, iface := walk(f); found != nil || iface != nil {
+					return found, iface
+				}
+			case *app.InterfaceNode:
+				if f.PackagePath == target.packagePath && f.TypeName == target.name {
+					return nil, f
+				}
+				if found, iface := walk(f.ResolvedStruct); found != nil || iface != nil {
+					return found, iface
+				}
+			}
+		}
+		return nil, nil
+	}
+
+	return walk(root)
+}
+
+// writeStructTree は構造体とそのフィールドの依存関係をインデント付きで書き出す
+func writeStructTree(b *strings.Builder, node *app.StructNode, depth int, visiting map[*app.StructNode]bool) {
+	b.WriteString(qualifiedName(node.PackagePath, node.StructName))
+	switch {
+	case node.Skipped:
+		fmt.Fprintf(b, " [skipped: %s]\n", skipReason(node.Diagnostic, node.SkipReason))
+		return
+	case len(node.InitFunctions) > 0:
+		names := make([]string, 0, len(node.InitFunctions))
+		for _, fn := range node.InitFunctions {
+			names = append(names, qualifiedName(fn.PackagePath, fn.Name))
+		}
+		fmt.Fprintf(b, " ← %s\n", strings.Join(names, ", "))
+	case depth > 0:
+		b.WriteString(" (no provider)\n")
+	default:
+		b.WriteString("\n")
+	}
+
+	// 循環している場合は同じ構造体を展開しない
+	if visiting[node] {
+		return
+	}
+	visiting[node] = true
+	defer delete(visiting, node)
+
+	for _, field := range node.Fields {
+		b.WriteString(strings.Repeat("  ", depth+1))
+		if name := field.GetFieldName(); name != "" {
+			b.WriteString(name + ": ")
+		}
+		switch f := field.(type) {
+		case *app.StructNode:
+			writeStructTree(b, f, depth+1, visiting)
+		case *app.InterfaceNode:
+			writeInterfaceTree(b, f, depth+1, visiting)
+		case *app.InputNode:
+			if f.Value != "" {
+				fmt.Fprintf(b, "%s = %s\n", f.TypeString, f.Value)
+			} else {
+				fmt.Fprintf(b, "%s (injector argument)\n", f.TypeString)
+			}
+		case *app.CollectionNode:
+			fmt.Fprintf(b, "%s (%s)\n", f.TypeString, f.ProvideHint)
+		case *app.FuncNode:
+			fmt.Fprintf(b, "%s (%s)\n", f.TypeString, f.ProvideHint)
+		case *app.ChanNode:
+			fmt.Fprintf(b, "%s (%s)\n", f.TypeString, f.ProvideHint)
+		}
+	}
+}
+
+// skipReason はスキップされた理由を診断のコード付きで返す（診断がない場合はreason）
+func skipReason(diagnostic *app.Diagnostic, reason string) string {
+	if diagnostic == nil {
+		return reason
+	}
+	return diagnostic.String()
+}
+
+// writeInterfaceTree はインターフェースと解決された実装の依存関係を書き出す
+func writeInterfaceTree(b *strings.Builder, node *app.InterfaceNode, depth int, visiting map[*app.StructNode]bool) {
+	b.WriteString(qualifiedName(node.PackagePath, node.TypeName))
+	switch {
+	case node.Value != "":
+		fmt.Fprintf(b, " = %s\n", node.Value)
+	case node.Skipped:
+		fmt.Fprintf(b, " [skipped: %s]\n", skipReason(node.Diagnostic, node.SkipReason))
+	case node.ResolvedStruct != nil:
+		b.WriteString(" → ")
+		writeStructTree(b, node.ResolvedStruct, depth, visiting)
+	default:
+		b.WriteString("\n")
+	}
+}
+
+// writeDefinitions はホバーした型の宣言・提供関数・実装型を返すreturn文へのリンクを書き出す
+func writeDefinitions(b *strings.Builder, node *app.StructNode, iface *app.InterfaceNode) {
+	var links []string
+	link := func(text string, pos token.Position) {
+		if pos.IsValid() && pos.Filename != "" {
+			links = append(links, fmt.Sprintf("- [%s](%s#L%d)", text, pathToURI(pos.Filename), pos.Line))
+		}
+	}
+
+	if iface != nil {
+		link(iface.FieldName+" "+qualifiedName(iface.PackagePath, iface.TypeName), iface.Position)
+		if impl := iface.Implementation; impl != nil && impl.Function != "" {
+			link("return in "+impl.Function, impl.ReturnPosition)
+		}
+		node = iface.ResolvedStruct
+	}
+	if node != nil {
+		link("type "+qualifiedName(node.PackagePath, node.StructName), node.Position)
+		for _, fn := range node.InitFunctions {
+			link(qualifiedName(fn.PackagePath, fn.Name), fn.Position)
+		}
+	}
+
+	if len(links) > 0 {
+		b.WriteString("\n" + strings.Join(links, "\n") + "\n")
+	}
+}
+
+// qualifiedName はパッケージ名で修飾した名前を返す
+func qualifiedName(pkgPath, name string) string {
+	if pkgPath == "" {
+		return name
+	}
+	return path.Base(pkgPath) + "." + name
+}
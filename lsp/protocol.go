@@ -0,0 +1,116 @@
+package lsp
+
+// LSPのメッセージのうち、このサーバーが使うものだけを定義する
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/
+
+// Position はドキュメント内の位置（文字位置はUTF-16のコード単位）
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range はドキュメント内の範囲
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// TextDocumentIdentifier はドキュメントのURI
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// TextDocumentItem は開かれたドキュメント
+type TextDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+// TextEdit はドキュメントの置き換え
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// WorkspaceEdit はドキュメントごとの置き換え
+type WorkspaceEdit struct {
+	Changes map[string][]TextEdit `json:"changes"`
+}
+
+// InitializeResult はinitializeの結果
+type InitializeResult struct {
+	Capabilities ServerCapabilities `json:"capabilities"`
+	ServerInfo   ServerInfo         `json:"serverInfo"`
+}
+
+// ServerCapabilities はサーバーが提供する機能
+type ServerCapabilities struct {
+	TextDocumentSync   int  `json:"textDocumentSync"` // 1: 変更時にドキュメント全体を送る
+	CodeActionProvider bool `json:"codeActionProvider"`
+	HoverProvider      bool `json:"hoverProvider"`
+}
+
+// ServerInfo はサーバーの名前
+type ServerInfo struct {
+	Name string `json:"name"`
+}
+
+// DidOpenTextDocumentParams はtextDocument/didOpenの引数
+type DidOpenTextDocumentParams struct {
+	TextDocument TextDocumentItem `json:"textDocument"`
+}
+
+// DidChangeTextDocumentParams はtextDocument/didChangeの引数
+type DidChangeTextDocumentParams struct {
+	TextDocument   TextDocumentIdentifier           `json:"textDocument"`
+	ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+// TextDocumentContentChangeEvent は変更後のドキュメント全体
+type TextDocumentContentChangeEvent struct {
+	Text string `json:"text"`
+}
+
+// DidCloseTextDocumentParams はtextDocument/didCloseとtextDocument/didSaveの引数
+type DidCloseTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// CodeActionParams はtextDocument/codeActionの引数
+type CodeActionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+}
+
+// CodeAction はエディタに提示する修正
+type CodeAction struct {
+	Title string         `json:"title"`
+	Kind  string         `json:"kind"`
+	Edit  *WorkspaceEdit `json:"edit,omitempty"`
+}
+
+// CodeActionKind の種類
+const (
+	CodeActionQuickFix = "quickfix"
+	CodeActionRewrite  = "refactor.rewrite"
+)
+
+// TextDocumentPositionParams はtextDocument/hoverの引数
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// Hover はホバーで表示する内容
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+// MarkupContent はマークダウンの文字列
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
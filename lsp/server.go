@@ -0,0 +1,220 @@
+// Package lsp はwire.goを編集するエディタ向けの小さなLanguage Server（標準入出力）を提供する
+// wire.Buildの補完や不足している提供関数・wire.Bindの追加をコードアクションとして提示し、
+// 型にホバーすると解析した依存関係のツリーを表示する
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+// ServerName はinitializeで返すサーバー名
+const ServerName = "convinient_wire"
+
+// Server はLSPのリクエストを処理する
+type Server struct {
+	searchPattern string
+	opts          []app.Option
+	conn          *conn
+	docs          map[string]*document // URI -> 開かれているドキュメント
+	results       map[string]*result   // wire.goのパス -> 解析結果
+	shutdown      bool
+}
+
+// result は1つのwire.goの解析結果
+type result struct {
+	analyzer  *app.WireAnalyzer
+	injectors []*app.InjectorInfo
+}
+
+// NewServer は提供関数を探すパッケージパターンを指定してServerを作成する
+// 解析はwire.goのディレクトリを作業ディレクトリとして行う
+func NewServer(searchPattern string, opts ...app.Option) *Server {
+	return &Server{
+		searchPattern: searchPattern,
+		opts:          opts,
+		docs:          make(map[string]*document),
+		results:       make(map[string]*result),
+	}
+}
+
+// Serve はexit通知を受け取るか入力が終わるまでリクエストを処理する
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+
+	for {
+		msg, err := s.conn.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			var rpcErr *responseError
+			if errors.As(err, &rpcErr) {
+				if err := s.conn.write(&message{ID: json.RawMessage("null"), Error: rpcErr}); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		res, err := s.handle(msg)
+		if msg.isNotification() {
+			continue
+		}
+
+		reply := &message{ID: msg.ID}
+		if err != nil {
+			var rpcErr *responseError
+			if !errors.As(err, &rpcErr) {
+				rpcErr = &responseError{Code: codeInternalError, Message: err.Error()}
+			}
+			reply.Error = rpcErr
+		} else {
+			body, err := json.Marshal(res)
+			if err != nil {
+				return fmt.Errorf("failed to encode result: %w", err)
+			}
+			reply.Result = body
+		}
+		if err := s.conn.write(reply); err != nil {
+			return err
+		}
+	}
+}
+
+// handle はメソッドごとの処理に振り分ける
+func (s *Server) handle(msg *message) (any, error) {
+	switch msg.Method {
+	case "initialize":
+		return InitializeResult{
+			Capabilities: ServerCapabilities{TextDocumentSync: 1, CodeActionProvider: true, HoverProvider: true},
+			ServerInfo:   ServerInfo{Name: ServerName},
+		}, nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.setDocument(params.TextDocument.URI, params.TextDocument.Text)
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		return nil, s.setDocument(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+
+	case "textDocument/didSave":
+		// 保存された提供関数の変更を反映するため、全ての解析結果を破棄する
+		clear(s.results)
+		return nil, nil
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, nil
+
+	case "textDocument/codeAction":
+		var params CodeActionParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.codeActions(params)
+
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params)
+	}
+
+	if msg.isNotification() {
+		// 未対応の通知（initializedなど）は無視する
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+// setDocument は開かれたドキュメントの内容を更新する
+func (s *Server) setDocument(uri, text string) error {
+	doc, err := newDocument(uri, text)
+	if err != nil {
+		return err
+	}
+	s.docs[uri] = doc
+	return nil
+}
+
+// wireDoc は開かれているドキュメントを構文解析する（wire.Buildを含まない場合はnil）
+func (s *Server) wireDoc(uri string) (*wireDoc, error) {
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil, &responseError{Code: codeInvalidParams, Message: "document not open: " + uri}
+	}
+	wd, err := parseWireDoc(doc)
+	if err != nil {
+		// 編集途中で構文が壊れている場合は何も提示しない
+		return nil, nil
+	}
+	if len(wd.injectors) == 0 {
+		return nil, nil
+	}
+	return wd, nil
+}
+
+// analyze はwire.goを解析する（保存されるまで結果を再利用する）
+func (s *Server) analyze(wireFilePath string) (*result, error) {
+	if res, ok := s.results[wireFilePath]; ok {
+		return res, nil
+	}
+
+	analyzer := app.NewWireAnalyzer(filepath.Dir(wireFilePath), s.searchPattern, s.opts...)
+	injectors, err := analyzer.AnalyzeInjectors(wireFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze %s: %w", wireFilePath, err)
+	}
+
+	res := &result{analyzer: analyzer, injectors: injectors}
+	s.results[wireFilePath] = res
+	return res, nil
+}
+
+// injector は解析結果から注入関数を探す
+func (res *result) injector(name string) *app.InjectorInfo {
+	for _, injector := range res.injectors {
+		if injector.Name == name {
+			return injector
+		}
+	}
+	return nil
+}
+
+// decodeParams はリクエストの引数を読み込む
+func decodeParams(msg *message, v any) error {
+	if err := json.Unmarshal(msg.Params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// client はテスト用にリクエストを書き出し、レスポンスを読み取る
+type client struct {
+	t      *testing.T
+	in     bytes.Buffer
+	nextID int
+}
+
+// request はリクエストを書き出し、そのIDを返す
+func (c *client) request(method string, params any) string {
+	c.nextID++
+	id, _ := json.Marshal(c.nextID)
+	c.send(&message{ID: id, Method: method}, params)
+	return string(id)
+}
+
+// notify は通知を書き出す
+func (c *client) notify(method string, params any) {
+	c.send(&message{Method: method}, params)
+}
+
+func (c *client) send(msg *message, params any) {
+	if params != nil {
+		body, err := json.Marshal(params)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		msg.Params = body
+	}
+	if err := newConn(nil, &c.in).write(msg); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// serve はサーバーにリクエストを渡し、IDごとのレスポンスを返す
+func (c *client) serve(s *Server) map[string]*message {
+	var out bytes.Buffer
+	if err := s.Serve(&c.in, &out); err != nil {
+		c.t.Fatalf("Serve() error = %v", err)
+	}
+
+	replies := make(map[string]*message)
+	r := newConn(&out, nil)
+	for {
+		msg, err := r.read()
+		if errors.Is(err, io.EOF) {
+			return replies
+		}
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		replies[string(msg.ID)] = msg
+	}
+}
+
+func TestServer(t *testing.T) {
+	wireFilePath, err := filepath.Abs("../testdata/lsp/wire.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := os.ReadFile(wireFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := pathToURI(wireFilePath)
+	doc, err := newDocument(uri, string(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// service.NewUserService の NewUserService にカーソルを置く
+	hoverPos := doc.position(strings.Index(doc.text, "NewUserService)"))
+
+	c := &client{t: t}
+	initID := c.request("initialize", map[string]any{})
+	c.notify("initialized", map[string]any{})
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "go", Version: 1, Text: string(text)},
+	})
+	actionID := c.request("textDocument/codeAction", CodeActionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Range:        Range{Start: hoverPos, End: hoverPos},
+	})
+	hoverID := c.request("textDocument/hover", TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     hoverPos,
+	})
+	unknownID := c.request("textDocument/definition", map[string]any{})
+	c.request("shutdown", nil)
+	c.notify("exit", nil)
+
+	replies := c.serve(NewServer("./..."))
+
+	var init InitializeResult
+	decodeReply(t, replies[initID], &init)
+	if init.ServerInfo.Name != ServerName || !init.Capabilities.CodeActionProvider || !init.Capabilities.HoverProvider {
+		t.Errorf("initialize = %+v", init)
+	}
+
+	var actions []CodeAction
+	decodeReply(t, replies[actionID], &actions)
+	titles := make(map[string]CodeAction)
+	for _, action := range actions {
+		titles[action.Title] = action
+	}
+	tests := []struct {
+		title    string
+		contains []string
+	}{
+		{title: titleFillBuild, contains: []string{"wire.Build(", "service.NewUserService", "repo.NewSQLUserRepository", `"example.com/lsp/repo"`}},
+		{title: "Add missing provider for db.DB", contains: []string{`, wire.Struct(new(db.DB), "*")`, `"example.com/lsp/db"`}},
+		{title: "Add wire.Bind for interface repo.UserRepository", contains: []string{", wire.Bind(new(repo.UserRepository), new(*repo.SQLUserRepository))", `"example.com/lsp/repo"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			action, ok := titles[tt.title]
+			if !ok {
+				t.Fatalf("code action not found in %v", actions)
+			}
+			var edits strings.Builder
+			for _, edit := range action.Edit.Changes[uri] {
+				edits.WriteString(edit.NewText)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(edits.String(), want) {
+					t.Errorf("edits do not contain %q:\n%s", want, edits.String())
+				}
+			}
+		})
+	}
+
+	var hover Hover
+	decodeReply(t, replies[hoverID], &hover)
+	for _, want := range []string{
+		"service.UserService ← service.NewUserService",
+		"  users: repo.UserRepository → repo.SQLUserRepository ← repo.NewSQLUserRepository",
+		"    db: db.DB (no provider)",
+		"      DSN: string (injector argument)",
+		"- [service.NewUserService](file://",
+	} {
+		if !strings.Contains(hover.Contents.Value, want) {
+			t.Errorf("hover does not contain %q:\n%s", want, hover.Contents.Value)
+		}
+	}
+
+	if reply := replies[unknownID]; reply == nil || reply.Error == nil || reply.Error.Code != codeMethodNotFound {
+		t.Errorf("unknown method reply = %+v", reply)
+	}
+}
+
+func TestDocument_Position(t *testing.T) {
+	doc, err := newDocument("file:///tmp/wire.go", "a\nあb\n𝑥c")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		offset int
+		want   Position
+	}{
+		{offset: 0, want: Position{Line: 0, Character: 0}},
+		{offset: 2, want: Position{Line: 1, Character: 0}},
+		{offset: 5, want: Position{Line: 1, Character: 1}},
+		{offset: 11, want: Position{Line: 2, Character: 2}},
+	}
+	for _, tt := range tests {
+		got := doc.position(tt.offset)
+		if got != tt.want {
+			t.Errorf("position(%d) = %+v, want %+v", tt.offset, got, tt.want)
+		}
+		if back := doc.offset(got); back != tt.offset {
+			t.Errorf("offset(%+v) = %d, want %d", got, back, tt.offset)
+		}
+	}
+}
+
+// decodeReply はレスポンスの結果を読み込む
+func decodeReply(t *testing.T, reply *message, v any) {
+	t.Helper()
+	if reply == nil {
+		t.Fatal("no reply")
+	}
+	if reply.Error != nil {
+		t.Fatalf("reply error = %v", reply.Error)
+	}
+	if err := json.Unmarshal(reply.Result, v); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1 +1,11 @@
 package main
+
+import (
+	"os"
+
+	"github.com/rmocchy/convinient_wire/cli"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -0,0 +1,201 @@
+package sarif
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+// ToolName はログに記録するツールの名前
+const ToolName = "convinient_wire"
+
+// InformationURI はツールの説明のURI
+const InformationURI = "https://github.com/rmocchy/convinient_wire"
+
+// levels は重要度に対応するSARIFのレベル
+var levels = map[app.Severity]string{
+	app.SeverityError:   "error",
+	app.SeverityWarning: "warning",
+	app.SeverityInfo:    "note",
+}
+
+// NewLog は注入関数ごとの診断からSARIFのログを作成する
+// baseDir: ファイルのURIの基準にするディレクトリ（通常はモジュールのルート。外にあるファイルは絶対URIにする）
+// 複数の注入関数から辿れる同じ問題は1つの結果にまとめ、関係する注入関数を全て関連する位置に含める
+func NewLog(injectors []*app.InjectorInfo, baseDir string) (*Log, error) {
+	absBase, err := filepath.Abs(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base directory: %w", err)
+	}
+	b := &builder{baseDir: absBase, indexes: make(map[string]int)}
+
+	ruleIndexes := make(map[app.DiagnosticCode]int)
+	var rules []ReportingDescriptor
+	for i, code := range app.DiagnosticCodes() {
+		ruleIndexes[code] = i
+		rules = append(rules, ReportingDescriptor{
+			ID:                   string(code),
+			Name:                 code.Name(),
+			ShortDescription:     Message{Text: code.Name()},
+			FullDescription:      Message{Text: code.Description()},
+			DefaultConfiguration: ReportingConfiguration{Level: levels[code.DefaultSeverity()]},
+		})
+	}
+
+	for _, injector := range injectors {
+		for _, diagnostic := range injector.Diagnostics {
+			b.add(injector, diagnostic, ruleIndexes[diagnostic.Code])
+		}
+	}
+
+	return &Log{
+		Schema:  SchemaURI,
+		Version: Version,
+		Runs: []Run{{
+			Tool: Tool{Driver: ToolComponent{
+				Name:           ToolName,
+				InformationURI: InformationURI,
+				Rules:          rules,
+			}},
+			OriginalURIBaseIDs: map[string]ArtifactLocation{
+				SrcRootBaseID: {URI: fileURI(absBase) + "/"},
+			},
+			Results: b.results,
+		}},
+	}, nil
+}
+
+// Write はログをJSONとして書き込む
+func Write(w io.Writer, log *Log) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("failed to write SARIF log: %w", err)
+	}
+	return nil
+}
+
+// builder は診断を結果に変換し、同じ問題の結果をまとめる
+type builder struct {
+	baseDir string
+	results []Result
+	indexes map[string]int // 問題ごとの結果の位置
+}
+
+// add は注入関数から辿った診断を結果に加える（同じ問題の結果があれば注入関数の位置だけを加える）
+func (b *builder) add(injector *app.InjectorInfo, diagnostic app.Diagnostic, ruleIndex int) {
+	injectorLocation, hasInjector := b.location(injector.Position, "injector "+injector.Name)
+
+	key := resultKey(diagnostic)
+	if i, ok := b.indexes[key]; ok {
+		if hasInjector {
+			b.appendRelated(&b.results[i], injectorLocation)
+		}
+		return
+	}
+
+	result := Result{
+		RuleID:    string(diagnostic.Code),
+		RuleIndex: ruleIndex,
+		Level:     levels[diagnostic.Severity],
+		Message:   Message{Text: resultMessage(diagnostic)},
+	}
+
+	// 位置が分からない診断は注入関数の位置で報告する
+	if primary, ok := b.location(diagnostic.Position, ""); ok {
+		result.Locations = []Location{primary}
+	} else if hasInjector {
+		result.Locations = []Location{{PhysicalLocation: injectorLocation.PhysicalLocation}}
+	}
+
+	for _, candidate := range diagnostic.Candidates {
+		text := "candidate " + candidate.Type.Key()
+		if candidate.Function != "" {
+			text += " via " + candidate.Function
+		}
+		if related, ok := b.location(candidate.Position, text); ok {
+			b.appendRelated(&result, related)
+		}
+	}
+	if hasInjector {
+		b.appendRelated(&result, injectorLocation)
+	}
+
+	b.indexes[key] = len(b.results)
+	b.results = append(b.results, result)
+}
+
+// appendRelated は同じ位置がまだなければ関連する位置を加える（IDは結果の中で1から振る）
+func (b *builder) appendRelated(result *Result, location Location) {
+	for _, related := range result.RelatedLocations {
+		if related.PhysicalLocation == location.PhysicalLocation && *related.Message == *location.Message {
+			return
+		}
+	}
+	location.ID = len(result.RelatedLocations) + 1
+	result.RelatedLocations = append(result.RelatedLocations, location)
+}
+
+// location はソースコードの位置をSARIFの位置に変換する（位置が不明な場合はfalse）
+func (b *builder) location(pos token.Position, text string) (Location, bool) {
+	if !pos.IsValid() || pos.Filename == "" {
+		return Location{}, false
+	}
+	location := Location{
+		PhysicalLocation: PhysicalLocation{
+			ArtifactLocation: b.artifactLocation(pos.Filename),
+			Region:           &Region{StartLine: pos.Line, StartColumn: pos.Column},
+		},
+	}
+	if text != "" {
+		location.Message = &Message{Text: text}
+	}
+	return location, true
+}
+
+// artifactLocation は基準のディレクトリ内のファイルを相対URI、それ以外を絶対URIで表す
+func (b *builder) artifactLocation(filename string) ArtifactLocation {
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		absPath = filename
+	}
+	rel, err := filepath.Rel(b.baseDir, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ArtifactLocation{URI: fileURI(absPath)}
+	}
+	return ArtifactLocation{URI: (&url.URL{Path: filepath.ToSlash(rel)}).String(), URIBaseID: SrcRootBaseID}
+}
+
+// fileURI は絶対パスをfileスキームのURIに変換する
+func fileURI(absPath string) string {
+	slashed := filepath.ToSlash(absPath)
+	if !strings.HasPrefix(slashed, "/") {
+		// Windowsのドライブ文字で始まるパス
+		slashed = "/" + slashed
+	}
+	return (&url.URL{Scheme: "file", Path: slashed}).String()
+}
+
+// resultMessage は診断の型とメッセージから結果のメッセージを作成する
+func resultMessage(diagnostic app.Diagnostic) string {
+	if len(diagnostic.Types) == 0 {
+		return diagnostic.Message
+	}
+	return diagnostic.Types[0].Key() + ": " + diagnostic.Message
+}
+
+// resultKey は同じ問題を同一視するためのキーを返す
+func resultKey(diagnostic app.Diagnostic) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s %s", diagnostic.Code, diagnostic.Position, diagnostic.Message)
+	for _, typeRef := range diagnostic.Types {
+		sb.WriteString(" " + typeRef.Key())
+	}
+	return sb.String()
+}
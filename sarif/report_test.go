@@ -0,0 +1,134 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+func TestNewLog(t *testing.T) {
+	baseDir := t.TempDir()
+	fieldPos := token.Position{Filename: filepath.Join(baseDir, "service", "service.go"), Line: 13, Column: 2}
+	ambiguous := app.Diagnostic{
+		Code:     app.CodeAmbiguousImplementation,
+		Severity: app.SeverityWarning,
+		Message:  "multiple implementing types found (2)",
+		Position: fieldPos,
+		Types:    []app.TypeRef{{TypeName: "Notifier", PackagePath: "example.com/app/notify"}},
+		Candidates: []app.Candidate{
+			{
+				Type:     app.TypeRef{TypeName: "EmailNotifier", PackagePath: "example.com/app/notify"},
+				Function: "NewEmailNotifier",
+				Position: token.Position{Filename: filepath.Join(baseDir, "notify", "email.go"), Line: 12, Column: 6},
+			},
+			{
+				// モジュールの外のファイルは絶対URIで表す
+				Type:     app.TypeRef{TypeName: "SlackNotifier", PackagePath: "example.com/lib/slack"},
+				Position: token.Position{Filename: filepath.Join(filepath.Dir(baseDir), "lib", "slack.go"), Line: 3, Column: 6},
+			},
+		},
+	}
+	loadError := app.Diagnostic{
+		Code:     app.CodeLoadError,
+		Severity: app.SeverityError,
+		Message:  "failed to analyze implementing type",
+	}
+
+	injectors := []*app.InjectorInfo{
+		{
+			Name:        "InitializeAPI",
+			Position:    token.Position{Filename: filepath.Join(baseDir, "wire.go"), Line: 10, Column: 6},
+			Diagnostics: []app.Diagnostic{ambiguous},
+		},
+		{
+			Name:        "InitializeWorker",
+			Position:    token.Position{Filename: filepath.Join(baseDir, "wire.go"), Line: 20, Column: 6},
+			Diagnostics: []app.Diagnostic{ambiguous, loadError},
+		},
+	}
+
+	log, err := NewLog(injectors, baseDir)
+	if err != nil {
+		t.Fatalf("NewLog failed: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("NewLog() = version %s with %d runs, want 2.1.0 with 1 run", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	if len(run.Tool.Driver.Rules) != len(app.DiagnosticCodes()) {
+		t.Errorf("len(Rules) = %d, want %d", len(run.Tool.Driver.Rules), len(app.DiagnosticCodes()))
+	}
+	for i, rule := range run.Tool.Driver.Rules {
+		if rule.FullDescription.Text == "" {
+			t.Errorf("rule %s has no description", rule.ID)
+		}
+		if i == 3 && rule.DefaultConfiguration.Level != "note" {
+			t.Errorf("rule %s level = %s, want note", rule.ID, rule.DefaultConfiguration.Level)
+		}
+	}
+
+	// 2つの注入関数から辿れる同じ問題は1つの結果にまとめる
+	if len(run.Results) != 2 {
+		t.Fatalf("len(Results) = %d, want 2", len(run.Results))
+	}
+
+	result := run.Results[0]
+	if result.RuleID != "CW002" || result.RuleIndex != 1 || result.Level != "warning" {
+		t.Errorf("result = %s (index %d, %s), want CW002 (index 1, warning)", result.RuleID, result.RuleIndex, result.Level)
+	}
+	if want := "example.com/app/notify.Notifier: multiple implementing types found (2)"; result.Message.Text != want {
+		t.Errorf("Message = %q, want %q", result.Message.Text, want)
+	}
+	if len(result.Locations) != 1 {
+		t.Fatalf("len(Locations) = %d, want 1", len(result.Locations))
+	}
+	if got := result.Locations[0].PhysicalLocation; got.ArtifactLocation != (ArtifactLocation{URI: "service/service.go", URIBaseID: SrcRootBaseID}) || *got.Region != (Region{StartLine: 13, StartColumn: 2}) {
+		t.Errorf("Locations[0] = %+v %+v, want service/service.go:13:2", got.ArtifactLocation, *got.Region)
+	}
+
+	wantRelated := []struct {
+		uri     string
+		baseID  string
+		line    int
+		message string
+	}{
+		{"notify/email.go", SrcRootBaseID, 12, "candidate example.com/app/notify.EmailNotifier via NewEmailNotifier"},
+		{fileURI(filepath.Join(filepath.Dir(baseDir), "lib", "slack.go")), "", 3, "candidate example.com/lib/slack.SlackNotifier"},
+		{"wire.go", SrcRootBaseID, 10, "injector InitializeAPI"},
+		{"wire.go", SrcRootBaseID, 20, "injector InitializeWorker"},
+	}
+	if len(result.RelatedLocations) != len(wantRelated) {
+		t.Fatalf("len(RelatedLocations) = %d, want %d", len(result.RelatedLocations), len(wantRelated))
+	}
+	for i, want := range wantRelated {
+		got := result.RelatedLocations[i]
+		if got.ID != i+1 || got.PhysicalLocation.ArtifactLocation.URI != want.uri || got.PhysicalLocation.ArtifactLocation.URIBaseID != want.baseID ||
+			got.PhysicalLocation.Region.StartLine != want.line || got.Message.Text != want.message {
+			t.Errorf("RelatedLocations[%d] = %d %+v line %d %q, want %d %s %s line %d %q", i, got.ID, got.PhysicalLocation.ArtifactLocation,
+				got.PhysicalLocation.Region.StartLine, got.Message.Text, i+1, want.uri, want.baseID, want.line, want.message)
+		}
+	}
+
+	// 位置が分からない診断は注入関数の位置で報告する
+	result = run.Results[1]
+	if result.RuleID != "CW003" || len(result.Locations) != 1 || result.Locations[0].PhysicalLocation.Region.StartLine != 20 {
+		t.Errorf("result = %+v, want CW003 at wire.go:20", result)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, log); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Write produced invalid JSON: %v", err)
+	}
+	if decoded["$schema"] != SchemaURI {
+		t.Errorf("$schema = %v, want %s", decoded["$schema"], SchemaURI)
+	}
+}
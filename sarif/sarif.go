@@ -0,0 +1,95 @@
+// Package sarif は解析で見つかった診断をSARIF 2.1.0のログに変換する
+// コードスキャンのダッシュボードなど、SARIFを取り込むツールにwireの依存関係の問題を表示するために使う
+package sarif
+
+// SARIFのうち、このパッケージが出力するものだけを定義する
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+// スキーマとバージョン
+const (
+	SchemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	Version   = "2.1.0"
+)
+
+// SrcRootBaseID はモジュールのルートを表すURIの基準の名前
+const SrcRootBaseID = "%SRCROOT%"
+
+// Log はSARIFのログ
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run は1回の解析の結果
+type Run struct {
+	Tool               Tool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]ArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []Result                    `json:"results"`
+}
+
+// Tool は解析したツール
+type Tool struct {
+	Driver ToolComponent `json:"driver"`
+}
+
+// ToolComponent はツールの名前と規則の一覧
+type ToolComponent struct {
+	Name           string                `json:"name"`
+	InformationURI string                `json:"informationUri,omitempty"`
+	Rules          []ReportingDescriptor `json:"rules"`
+}
+
+// ReportingDescriptor は規則（診断コード）のメタデータ
+type ReportingDescriptor struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name"`
+	ShortDescription     Message                `json:"shortDescription"`
+	FullDescription      Message                `json:"fullDescription"`
+	DefaultConfiguration ReportingConfiguration `json:"defaultConfiguration"`
+}
+
+// ReportingConfiguration は規則の既定の設定
+type ReportingConfiguration struct {
+	Level string `json:"level"`
+}
+
+// Message はテキストのメッセージ
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Result は1つの診断
+type Result struct {
+	RuleID           string     `json:"ruleId"`
+	RuleIndex        int        `json:"ruleIndex"`
+	Level            string     `json:"level"`
+	Message          Message    `json:"message"`
+	Locations        []Location `json:"locations,omitempty"`
+	RelatedLocations []Location `json:"relatedLocations,omitempty"`
+}
+
+// Location は診断に関係するソースコードの位置
+type Location struct {
+	ID               int              `json:"id,omitempty"`
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+	Message          *Message         `json:"message,omitempty"`
+}
+
+// PhysicalLocation はファイルとその中の範囲
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+// ArtifactLocation はファイルのURI（URIBaseIDがある場合はその基準からの相対URI）
+type ArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// Region はファイル内の位置（行・列は1始まり）
+type Region struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
@@ -0,0 +1,26 @@
+package app
+
+// Svc は2つの関数で作れるサービス
+type Svc struct {
+	name string
+}
+
+// NewSvc はSvcを作成する
+func NewSvc() *Svc {
+	return &Svc{name: "default"}
+}
+
+// NewSvcAlt もSvcを作成するため、どちらを使うか決められない
+func NewSvcAlt() *Svc {
+	return &Svc{name: "alt"}
+}
+
+// Handler はSvcを使うハンドラー
+type Handler struct {
+	svc *Svc
+}
+
+// NewHandler はHandlerを作成する
+func NewHandler(svc *Svc) *Handler {
+	return &Handler{svc: svc}
+}
@@ -0,0 +1,3 @@
+package main // want package:"wirecheck\\(2 packages, 0 sets\\)"
+
+func main() {}
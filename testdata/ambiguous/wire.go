@@ -0,0 +1,20 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"example.com/ambiguous/app"
+)
+
+type App struct {
+	handler *app.Handler
+}
+
+// InitializeApp はAppを初期化する
+func InitializeApp() *App {
+	wire.Build(wire.Struct(new(App), "*")) // want `multiple providers found for \*app.Svc, needed by app.NewHandler \(app.NewSvc, app.NewSvcAlt\)`
+	return nil
+}
@@ -0,0 +1,6 @@
+package codec
+
+// Encoder は値をバイト列に変換する
+type Encoder interface {
+	Encode(v any) ([]byte, error)
+}
@@ -0,0 +1,16 @@
+package jsoncodec
+
+import "encoding/json"
+
+// JSONEncoder はinterface{}で宣言したメソッドでcodec.Encoderを実装する（anyと同じ型）
+type JSONEncoder struct{}
+
+// NewJSONEncoder はJSONEncoderを作成する
+func NewJSONEncoder() *JSONEncoder {
+	return &JSONEncoder{}
+}
+
+// Encode は値をJSONに変換する
+func (e *JSONEncoder) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
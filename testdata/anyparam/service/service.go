@@ -0,0 +1,13 @@
+package service
+
+import "example.com/anyparam/codec"
+
+// Service はEncoderを使うサービス
+type Service struct {
+	enc codec.Encoder
+}
+
+// NewService はServiceを作成する
+func NewService(enc codec.Encoder) *Service {
+	return &Service{enc: enc}
+}
@@ -0,0 +1,20 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"example.com/anyparam/service"
+)
+
+type App struct {
+	service *service.Service
+}
+
+// InitializeApp はAppを初期化する
+func InitializeApp() *App {
+	wire.Build(wire.Struct(new(App), "*"))
+	return nil
+}
@@ -0,0 +1,14 @@
+package db
+
+import "fmt"
+
+// DB はデータベース接続
+type DB struct {
+	dsn string
+}
+
+// NewDB はDBを作成する
+func NewDB(dsn string) *DB {
+	fmt.Println("connecting to", dsn)
+	return &DB{dsn: dsn}
+}
@@ -0,0 +1,10 @@
+package db
+
+import "testing"
+
+func TestNewDB(t *testing.T) {
+	// リテラルを渡す呼び出しは引数の型を置き換えても変換なしでコンパイルできる
+	if NewDB("postgres://localhost") == nil {
+		t.Fatal("NewDB returned nil")
+	}
+}
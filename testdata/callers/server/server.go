@@ -0,0 +1,29 @@
+package server
+
+import (
+	database "example.com/callers/db"
+)
+
+// Config はサーバーの設定
+type Config struct {
+	DSN     string
+	Replica string
+}
+
+// Server はプライマリとレプリカのDBを使うサーバー
+type Server struct {
+	name    string
+	primary *database.DB
+	replica *database.DB
+}
+
+// NewServer はServerを作成する
+func NewServer(name string, primary *database.DB) *Server {
+	return &Server{name: name, primary: primary}
+}
+
+// WithReplica は設定からレプリカのDBを作成する（提供関数の呼び出しは置き換えた引数を変換する）
+func WithReplica(s *Server, cfg Config) *Server {
+	s.replica = database.NewDB(cfg.Replica)
+	return s
+}
@@ -0,0 +1,20 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"example.com/callers/server"
+)
+
+type App struct {
+	server *server.Server
+}
+
+// InitializeApp はAppを初期化する
+func InitializeApp() *App {
+	wire.Build(wire.Struct(new(App), "*"))
+	return nil
+}
@@ -0,0 +1,14 @@
+package client
+
+// Client は外部APIのクライアント
+type Client struct {
+	apiKey string
+}
+
+// NewClient はClientを作成する
+func NewClient(apiKey string) *Client {
+	if apiKey == "" {
+		apiKey = "anonymous"
+	}
+	return &Client{apiKey: apiKey}
+}
@@ -0,0 +1,22 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"example.com/conflicts/client"
+	"example.com/conflicts/db"
+)
+
+type App struct {
+	db     *db.DB
+	client *client.Client
+}
+
+// InitializeApp はAppを初期化する
+func InitializeApp() *App {
+	wire.Build(wire.Struct(new(App), "*"))
+	return nil
+}
@@ -0,0 +1,7 @@
+module example.com/externals
+
+go 1.25.1
+
+require example.com/vendorlib v0.0.0
+
+replace example.com/vendorlib => ./vendorlib
@@ -0,0 +1,57 @@
+package externals
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"time"
+
+	"example.com/vendorlib"
+)
+
+// DSN はデータソース名を表すモジュール内のNamed型
+type DSN string
+
+// Settings はモジュール内の設定構造体
+type Settings struct {
+	Timeout time.Duration
+}
+
+// Service は標準ライブラリやサードパーティの型に依存する構造体
+type Service struct {
+	ctx      context.Context
+	db       *sql.DB
+	client   *http.Client
+	out      io.Writer
+	timeout  time.Duration
+	name     string
+	err      error
+	dsn      DSN
+	vendor   *vendorlib.Client
+	settings *Settings
+}
+
+// NewHTTPClient はモジュール内で*http.Clientを提供する
+func NewHTTPClient() *http.Client {
+	return &http.Client{}
+}
+
+// NewSettings はSettingsを提供する
+func NewSettings() *Settings {
+	return &Settings{Timeout: time.Second}
+}
+
+// NewDB はクリーンアップ関数とエラーを返すプロバイダー
+func NewDB(ctx context.Context, dsn DSN) (*sql.DB, func(), error) {
+	db, err := sql.Open("postgres", string(dsn))
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, func() { db.Close() }, nil
+}
+
+// NewSettingsAndClient は複数の値を返すため、どちらの型の提供関数にもならない
+func NewSettingsAndClient() (*Settings, *http.Client) {
+	return NewSettings(), NewHTTPClient()
+}
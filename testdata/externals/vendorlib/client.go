@@ -0,0 +1,6 @@
+package vendorlib
+
+// Client はサードパーティのクライアントを模した構造体
+type Client struct {
+	Endpoint string
+}
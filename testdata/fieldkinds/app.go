@@ -0,0 +1,49 @@
+package fieldkinds
+
+import "time"
+
+// Plugin はプラグインのインターフェース
+type Plugin interface {
+	Name() string
+}
+
+// Handler はハンドラーのインターフェース
+type Handler interface {
+	Handle()
+}
+
+// Event はイベントを表す構造体
+type Event struct {
+	Name string
+}
+
+// Plugins はプラグインのスライスに名前をつけた型
+type Plugins []Plugin
+
+// Clock は現在時刻を返す関数型
+type Clock func() time.Time
+
+// App はスライス・マップ・関数・チャネルの依存を持つ構造体
+type App struct {
+	plugins  []Plugin
+	handlers map[string]Handler
+	now      func() time.Time
+	events   chan Event
+	named    Plugins
+	clock    Clock
+	fixed    [2]Plugin
+	outbox   chan<- Event
+}
+
+// NewApp はAppの新しいインスタンスを作成
+func NewApp(plugins []Plugin, handlers map[string]Handler, now func() time.Time, events chan Event, named Plugins, clock Clock) *App {
+	return &App{
+		plugins:  plugins,
+		handlers: handlers,
+		now:      now,
+		events:   events,
+		named:    named,
+		clock:    clock,
+		outbox:   events,
+	}
+}
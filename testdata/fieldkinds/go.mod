@@ -0,0 +1,3 @@
+module example.com/fieldkinds
+
+go 1.25.1
@@ -0,0 +1,13 @@
+package config
+
+// Config はアプリケーションの設定
+type Config struct {
+	DSN         string // データソース名
+	MaxPoolSize int    // 最大接続プール数
+	Debug       bool   // どの提供関数も使わないフィールド
+}
+
+// NewConfig はConfigを作成する
+func NewConfig() *Config {
+	return &Config{DSN: "user:password@/mydb", MaxPoolSize: 10}
+}
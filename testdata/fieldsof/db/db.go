@@ -0,0 +1,12 @@
+package db
+
+// DB はデータベース接続
+type DB struct {
+	dsn         string
+	maxPoolSize int
+}
+
+// NewDB は設定の個々の値からDBを作成する
+func NewDB(dsn string, maxPoolSize int) *DB {
+	return &DB{dsn: dsn, maxPoolSize: maxPoolSize}
+}
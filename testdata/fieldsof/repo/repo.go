@@ -0,0 +1,17 @@
+package repo
+
+import (
+	"example.com/fieldsof/config"
+	"example.com/fieldsof/db"
+)
+
+// Repository は設定とDBを使うリポジトリ
+type Repository struct {
+	db     *db.DB
+	config *config.Config
+}
+
+// NewRepository はRepositoryを作成する
+func NewRepository(db *db.DB, cfg *config.Config) *Repository {
+	return &Repository{db: db, config: cfg}
+}
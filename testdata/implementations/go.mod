@@ -0,0 +1,3 @@
+module example.com/implementations
+
+go 1.25.1
@@ -0,0 +1,33 @@
+package implementations
+
+import "fmt"
+
+// Notifier は通知を送るインターフェース
+type Notifier interface {
+	Notify(msg string) error
+}
+
+// emailNotifier はコンストラクタを持つ実装（ポインタレシーバ）
+type emailNotifier struct {
+	from string
+}
+
+// NewEmailNotifier はNotifierを返すコンストラクタ
+func NewEmailNotifier() Notifier {
+	return &emailNotifier{from: "noreply@example.com"}
+}
+
+func (n *emailNotifier) Notify(msg string) error {
+	fmt.Println(n.from, msg)
+	return nil
+}
+
+// SlackNotifier はコンストラクタを持たない実装（値レシーバ）
+type SlackNotifier struct {
+	Channel string
+}
+
+func (n SlackNotifier) Notify(msg string) error {
+	fmt.Println(n.Channel, msg)
+	return nil
+}
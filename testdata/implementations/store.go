@@ -0,0 +1,51 @@
+package implementations
+
+// Store はキーバリューストアのインターフェース
+type Store interface {
+	Get(key string) string
+}
+
+type memoryStore struct{}
+
+func (s *memoryStore) Get(key string) string { return "memory:" + key }
+
+type fileStore struct{}
+
+func (s *fileStore) Get(key string) string { return "file:" + key }
+
+// NewStoreViaLocal はローカル変数経由で実装を返す
+func NewStoreViaLocal() (Store, error) {
+	s := &memoryStore{}
+	return s, nil
+}
+
+// NewStoreViaHelper はヘルパー関数経由で実装を返す（ヘルパーは2種類の実装を返しうる）
+func NewStoreViaHelper(persistent bool) Store {
+	return buildStore(persistent)
+}
+
+func buildStore(persistent bool) Store {
+	var s Store
+	if persistent {
+		s = &fileStore{}
+	} else {
+		s = &memoryStore{}
+	}
+	return s
+}
+
+// NewStoreViaClosure は呼び出されたクロージャの返り値を返す
+func NewStoreViaClosure() Store {
+	build := func() Store {
+		return &fileStore{}
+	}
+	return build()
+}
+
+// NewStoreIgnoringClosure は呼び出されない関数リテラルを含むが、自身はmemoryStoreを返す
+func NewStoreIgnoringClosure() (Store, func() Store) {
+	fallback := func() Store {
+		return &fileStore{}
+	}
+	return &memoryStore{}, fallback
+}
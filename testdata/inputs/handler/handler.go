@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+
+	"example.com/inputs/notify"
+	"example.com/inputs/repo"
+)
+
+// Handler はリポジトリとHTTPクライアントに依存するハンドラー
+type Handler struct {
+	repo     repo.UserRepository
+	client   *http.Client
+	notifier notify.Notifier
+}
+
+// NewHandler はHandlerを返す
+func NewHandler(r repo.UserRepository, client *http.Client, notifier notify.Notifier) *Handler {
+	return &Handler{repo: r, client: client, notifier: notifier}
+}
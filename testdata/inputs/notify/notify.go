@@ -0,0 +1,16 @@
+package notify
+
+// Notifier は通知を送るインターフェース
+type Notifier interface {
+	Notify(msg string)
+}
+
+// EmailNotifier はNotifierの実装（コンストラクタは具象型を返す）
+type EmailNotifier struct{}
+
+// NewEmailNotifier はEmailNotifierを返す
+func NewEmailNotifier() *EmailNotifier {
+	return &EmailNotifier{}
+}
+
+func (n *EmailNotifier) Notify(msg string) {}
@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+// DB はデータベース接続を表す構造体
+type DB struct {
+	dsn string
+}
+
+// NewDB はコンテキストとDSNを受け取り、クリーンアップ関数とエラーを返す
+func NewDB(ctx context.Context, dsn string) (*DB, func(), error) {
+	return &DB{dsn: dsn}, func() {}, nil
+}
+
+// UserRepository はユーザーリポジトリのインターフェース
+type UserRepository interface {
+	Find(id int) string
+}
+
+type userRepository struct {
+	db      *DB
+	timeout time.Duration
+}
+
+// NewUserRepository はUserRepositoryを返す
+func NewUserRepository(db *DB, timeout time.Duration) UserRepository {
+	return &userRepository{db: db, timeout: timeout}
+}
+
+func (r *userRepository) Find(id int) string {
+	return r.db.dsn
+}
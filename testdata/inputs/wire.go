@@ -0,0 +1,33 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/wire"
+
+	"example.com/inputs/handler"
+	"example.com/inputs/notify"
+	"example.com/inputs/repo"
+)
+
+type App struct {
+	handler *handler.Handler
+}
+
+// InitializeApp はAppを初期化する
+func InitializeApp(ctx context.Context, dsn string, timeout time.Duration, client *http.Client) (*App, func(), error) {
+	wire.Build(
+		repo.NewDB,
+		repo.NewUserRepository,
+		handler.NewHandler,
+		notify.NewEmailNotifier,
+		wire.Bind(new(notify.Notifier), new(*notify.EmailNotifier)),
+		wire.Struct(new(App), "*"),
+	)
+	return nil, nil, nil
+}
@@ -0,0 +1,6 @@
+package db
+
+// DB は提供関数のないデータベース接続
+type DB struct {
+	DSN string
+}
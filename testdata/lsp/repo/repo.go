@@ -0,0 +1,20 @@
+package repo
+
+import "example.com/lsp/db"
+
+// UserRepository はユーザーの永続化を行う
+type UserRepository interface {
+	Find(id int) string
+}
+
+// SQLUserRepository はデータベースを使うUserRepositoryの実装
+type SQLUserRepository struct {
+	db *db.DB
+}
+
+// NewSQLUserRepository はSQLUserRepositoryを作成する
+func NewSQLUserRepository(db *db.DB) *SQLUserRepository {
+	return &SQLUserRepository{db: db}
+}
+
+func (r *SQLUserRepository) Find(id int) string { return "" }
@@ -0,0 +1,13 @@
+package service
+
+import "example.com/lsp/repo"
+
+// UserService はユーザーに関する処理を行う
+type UserService struct {
+	users repo.UserRepository
+}
+
+// NewUserService はUserServiceを作成する
+func NewUserService(users repo.UserRepository) *UserService {
+	return &UserService{users: users}
+}
@@ -0,0 +1,20 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"example.com/lsp/service"
+)
+
+type App struct {
+	service *service.UserService
+}
+
+// InitializeApp はAppを初期化する
+func InitializeApp() *App {
+	wire.Build(service.NewUserService)
+	return nil
+}
@@ -0,0 +1,13 @@
+package api
+
+import "example.com/multi/repo"
+
+// Server はAPIサーバー
+type Server struct {
+	users *repo.UserRepository
+}
+
+// NewServer はServerを作成する
+func NewServer(users *repo.UserRepository) *Server {
+	return &Server{users: users}
+}
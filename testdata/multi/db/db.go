@@ -0,0 +1,9 @@
+package db
+
+// DB はデータベース接続
+type DB struct{}
+
+// NewDB はDBを作成する
+func NewDB() *DB {
+	return &DB{}
+}
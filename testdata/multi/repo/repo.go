@@ -0,0 +1,13 @@
+package repo
+
+import "example.com/multi/db"
+
+// UserRepository はユーザーの永続化を行う
+type UserRepository struct {
+	db *db.DB
+}
+
+// NewUserRepository はUserRepositoryを作成する
+func NewUserRepository(db *db.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
@@ -0,0 +1,33 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"example.com/multi/api"
+	"example.com/multi/db"
+	"example.com/multi/repo"
+	"example.com/multi/worker"
+)
+
+type API struct {
+	server *api.Server
+}
+
+type Worker struct {
+	worker *worker.Worker
+}
+
+// InitializeAPI はAPIを初期化する
+func InitializeAPI() *API {
+	wire.Build(db.NewDB, repo.NewUserRepository, api.NewServer, wire.Struct(new(API), "*"))
+	return nil
+}
+
+// InitializeWorker はWorkerを初期化する
+func InitializeWorker() *Worker {
+	wire.Build(db.NewDB, repo.NewUserRepository, worker.NewWorker, wire.Struct(new(Worker), "*"))
+	return nil
+}
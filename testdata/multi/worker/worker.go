@@ -0,0 +1,13 @@
+package worker
+
+import "example.com/multi/repo"
+
+// Worker はバックグラウンド処理を行う
+type Worker struct {
+	users *repo.UserRepository
+}
+
+// NewWorker はWorkerを作成する
+func NewWorker(users *repo.UserRepository) *Worker {
+	return &Worker{users: users}
+}
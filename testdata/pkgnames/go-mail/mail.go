@@ -0,0 +1,8 @@
+// Package mail はディレクトリ名（go-mail）と異なるパッケージ名のパッケージ
+package mail
+
+type Mailer struct{}
+
+func NewMailer() *Mailer {
+	return &Mailer{}
+}
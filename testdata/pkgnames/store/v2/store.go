@@ -0,0 +1,12 @@
+// Package store はメジャーバージョンのサフィックス（/v2）を持つパッケージ
+package store
+
+import mail "example.com/pkgnames/go-mail"
+
+type Store struct {
+	mailer *mail.Mailer
+}
+
+func NewStore(mailer *mail.Mailer) *Store {
+	return &Store{mailer: mailer}
+}
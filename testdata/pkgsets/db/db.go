@@ -0,0 +1,14 @@
+package db
+
+import "github.com/google/wire"
+
+// ProviderSet は db パッケージの提供関数をまとめたプロバイダーセット
+var ProviderSet = wire.NewSet(NewDB)
+
+// DB はデータベース接続
+type DB struct{}
+
+// NewDB はDBを作成する
+func NewDB() *DB {
+	return &DB{}
+}
@@ -0,0 +1,13 @@
+package notify
+
+import "github.com/google/wire"
+
+// ProviderSet は非公開の提供関数を含むため、他のパッケージからは要素を参照できない
+var ProviderSet = wire.NewSet(newNotifier)
+
+// Notifier は通知を送る
+type Notifier struct{}
+
+func newNotifier() *Notifier {
+	return &Notifier{}
+}
@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"github.com/google/wire"
+
+	"example.com/pkgsets/db"
+)
+
+// ProviderSet は repo パッケージの提供関数をまとめたプロバイダーセット（db パッケージのセットを含む）
+var ProviderSet = wire.NewSet(
+	db.ProviderSet,
+	NewSQLUserRepository,
+	wire.Bind(new(UserRepository), new(*SQLUserRepository)),
+)
+
+// UserRepository はユーザーの永続化を行う
+type UserRepository interface {
+	Find(id int) string
+}
+
+// SQLUserRepository はデータベースを使うUserRepositoryの実装
+type SQLUserRepository struct {
+	db *db.DB
+}
+
+// NewSQLUserRepository はSQLUserRepositoryを作成する
+func NewSQLUserRepository(db *db.DB) *SQLUserRepository {
+	return &SQLUserRepository{db: db}
+}
+
+func (r *SQLUserRepository) Find(id int) string { return "" }
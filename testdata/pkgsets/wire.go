@@ -0,0 +1,25 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"example.com/pkgsets/repo"
+	"example.com/pkgsets/service"
+)
+
+type App struct {
+	service *service.UserService
+}
+
+// InitializeApp はAppを初期化する
+func InitializeApp() *App {
+	wire.Build(
+		repo.ProviderSet,
+		service.NewUserService,
+		wire.Struct(new(App), "*"),
+	)
+	return nil
+}
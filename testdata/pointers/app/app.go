@@ -0,0 +1,21 @@
+package app
+
+// Config はアプリケーションの設定
+type Config struct {
+	DSN string
+}
+
+// NewConfig はポインタではなく値のConfigを返す
+func NewConfig() Config {
+	return Config{DSN: "user:password@/mydb"}
+}
+
+// Repo は設定のポインタを受け取るリポジトリ
+type Repo struct {
+	cfg *Config
+}
+
+// NewRepo は*Configを必要とするため、NewConfigでは満たせない
+func NewRepo(cfg *Config) *Repo {
+	return &Repo{cfg: cfg}
+}
@@ -0,0 +1,12 @@
+package db
+
+import "database/sql"
+
+// Store は自身のパッケージと同じ名前の引数を受け取る提供関数で作る
+type Store struct {
+	conn *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{conn: db}
+}
@@ -0,0 +1,9 @@
+package main
+
+import (
+	"github.com/google/wire"
+
+	"example.com/shadow/db"
+)
+
+var storeSet = wire.NewSet(db.NewStore)
@@ -0,0 +1,25 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"database/sql"
+
+	"github.com/google/wire"
+
+	"example.com/shadow/db"
+)
+
+// InitializeStore はStoreを初期化する
+func InitializeStore(conn *sql.DB) *db.Store {
+	wire.Build(db.NewStore)
+	return nil
+}
+
+// InitializeStoreFromSet は引数名がパッケージ名と同じ注入関数
+// 引数のスコープは関数本体なので、シグネチャのdb.Storeはパッケージを指す
+func InitializeStoreFromSet(db *sql.DB) *db.Store {
+	wire.Build(storeSet)
+	return nil
+}
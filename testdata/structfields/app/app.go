@@ -0,0 +1,19 @@
+package app
+
+import r "example.com/structfields/route"
+
+// Config はwire.Structで埋める設定
+type Config struct {
+	DSN         string
+	APIKey      string
+	AdminRoutes map[string]*r.Handler
+	UserRoutes  map[string]*r.Handler
+}
+
+// Describe は設定の概要を返す
+func (c *Config) Describe() string {
+	if c.APIKey == "" {
+		c.APIKey = "anonymous"
+	}
+	return c.DSN + " " + c.APIKey
+}
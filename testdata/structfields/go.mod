@@ -0,0 +1,5 @@
+module example.com/structfields
+
+go 1.25.1
+
+require github.com/google/wire v0.6.0
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/structfields/app"
+)
+
+func main() {
+	cfg := app.Config{DSN: "postgres://localhost"}
+	fmt.Println(cfg.Describe())
+}
@@ -0,0 +1,6 @@
+package route
+
+// Handler はパスごとの処理
+type Handler struct {
+	Path string
+}
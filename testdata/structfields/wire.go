@@ -0,0 +1,16 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"example.com/structfields/app"
+)
+
+// InitializeConfig はConfigを初期化する
+func InitializeConfig() *app.Config {
+	wire.Build(wire.Struct(new(app.Config), "*"))
+	return nil
+}
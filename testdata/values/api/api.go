@@ -0,0 +1,13 @@
+package api
+
+import "net/http"
+
+// Client は外部APIのクライアント
+type Client struct {
+	http *http.Client
+}
+
+// NewClient はHTTPクライアントを指定してClientを作成する
+func NewClient(c *http.Client) *Client {
+	return &Client{http: c}
+}
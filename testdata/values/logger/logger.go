@@ -0,0 +1,13 @@
+package logger
+
+import "io"
+
+// Logger は書き込み先にログを出力する
+type Logger struct {
+	w io.Writer
+}
+
+// NewLogger は書き込み先を指定してLoggerを作成する
+func NewLogger(w io.Writer) *Logger {
+	return &Logger{w: w}
+}
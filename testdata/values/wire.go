@@ -0,0 +1,22 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"example.com/values/api"
+	"example.com/values/logger"
+)
+
+type App struct {
+	logger *logger.Logger
+	client *api.Client
+}
+
+// InitializeApp はAppを初期化する
+func InitializeApp() *App {
+	wire.Build(wire.Struct(new(App), "*"))
+	return nil
+}
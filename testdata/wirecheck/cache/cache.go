@@ -0,0 +1,6 @@
+package cache
+
+// Cache は実装のないインターフェース
+type Cache interface {
+	Purge()
+}
@@ -0,0 +1,2 @@
+// Package fixed はwirecheckの修正を適用済みの注入関数を持つ // want package:"wirecheck\\(6 packages, 1 sets\\)"
+package fixed
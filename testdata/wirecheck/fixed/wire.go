@@ -0,0 +1,23 @@
+//go:build wireinject
+// +build wireinject
+
+package fixed
+
+import (
+	"github.com/google/wire"
+
+	"example.com/wirecheck/cache"
+	"example.com/wirecheck/metrics"
+	"example.com/wirecheck/notify"
+	"example.com/wirecheck/service"
+	"example.com/wirecheck/store"
+)
+
+var serviceSet = wire.NewSet(service.NewService, notify.ProviderSet)
+
+// InitializeService はServiceを初期化する
+// 実装型の選べないインターフェースは引数とwire.Bindで、提供関数のない構造体はwire.Structで解決済み
+func InitializeService(s store.Store, c cache.Cache) *service.Service {
+	wire.Build(serviceSet, wire.NewSet(wire.Struct(new(metrics.Metrics), "*")))
+	return nil
+}
@@ -0,0 +1,6 @@
+package metrics
+
+// Metrics は提供関数のない構造体
+type Metrics struct {
+	Name string
+}
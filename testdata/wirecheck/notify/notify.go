@@ -0,0 +1,31 @@
+package notify
+
+import "github.com/google/wire"
+
+// ProviderSet はEmailNotifierで通知するプロバイダセット
+var ProviderSet = wire.NewSet(NewEmailNotifier, wire.Bind(new(Notifier), new(*EmailNotifier)))
+
+// Notifier は通知を送る
+type Notifier interface {
+	Notify()
+}
+
+// EmailNotifier はメールで通知する
+type EmailNotifier struct{}
+
+func (*EmailNotifier) Notify() {}
+
+// NewEmailNotifier はEmailNotifierを作成する
+func NewEmailNotifier() *EmailNotifier {
+	return &EmailNotifier{}
+}
+
+// SlackNotifier はSlackで通知する
+type SlackNotifier struct{}
+
+func (*SlackNotifier) Notify() {}
+
+// NewSlackNotifier はSlackNotifierを作成する
+func NewSlackNotifier() *SlackNotifier {
+	return &SlackNotifier{}
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"example.com/wirecheck/cache"
+	"example.com/wirecheck/metrics"
+	"example.com/wirecheck/notify"
+	"example.com/wirecheck/store"
+)
+
+// Service は依存関係に問題のある型を受け取る
+type Service struct {
+	store    store.Store
+	notifier notify.Notifier
+	cache    cache.Cache
+	metrics  *metrics.Metrics
+}
+
+// NewService はServiceを作成する
+func NewService(s store.Store, n notify.Notifier, c cache.Cache, m *metrics.Metrics) *Service {
+	return &Service{store: s, notifier: n, cache: c, metrics: m}
+}
@@ -0,0 +1,11 @@
+package store
+
+// Store は値を読み出す
+type Store interface {
+	Get() string
+}
+
+// memoryStore はパッケージ外から参照できない実装
+type memoryStore struct{}
+
+func (memoryStore) Get() string { return "" }
@@ -0,0 +1,21 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"example.com/wirecheck/notify"
+	"example.com/wirecheck/service"
+)
+
+type App struct {
+	service *service.Service
+}
+
+// InitializeApp はAppを初期化する
+func InitializeApp() *App {
+	wire.Build(notify.NewEmailNotifier, notify.NewSlackNotifier, service.NewService, wire.Struct(new(App), "*")) // want "unexported store.memoryStore" "has 2 implementations" "no implementation of cache.Cache" "no provider for metrics.Metrics"
+	return nil
+}
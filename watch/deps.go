@@ -0,0 +1,144 @@
+package watch
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+)
+
+// dependencies は注入関数の解析結果が依存しているパッケージと型
+type dependencies struct {
+	packages  map[string]bool // 依存ツリーと依存グラフに現れるパッケージパス
+	typeNames map[string]bool // 依存ツリーと依存グラフに現れる型名（新しい提供関数の検出に使う）
+}
+
+// collectDependencies は注入関数の依存ツリーと依存グラフからパッケージと型を集める
+func collectDependencies(injector *app.InjectorInfo) *dependencies {
+	deps := &dependencies{
+		packages:  map[string]bool{injector.PackagePath: true},
+		typeNames: make(map[string]bool),
+	}
+
+	seen := make(map[*app.StructNode]bool)
+	var walkStruct func(node *app.StructNode)
+	walkStruct = func(node *app.StructNode) {
+		if node == nil || seen[node] {
+			return
+		}
+		seen[node] = true
+		deps.add(node.PackagePath, node.StructName)
+		deps.addFunctions(node.InitFunctions)
+
+		for _, field := range node.Fields {
+			switch f := field.(type) {
+			case *app.StructNode:
+				walkStruct(f)
+			case *app.InterfaceNode:
+				deps.add(f.PackagePath, f.TypeName)
+				walkStruct(f.ResolvedStruct)
+			case *app.InputNode:
+				deps.add(f.PackagePath, f.TypeName)
+				deps.addFunctions(f.InitFunctions)
+			case *app.CollectionNode:
+				deps.add(f.PackagePath, f.TypeName)
+			case *app.FuncNode:
+				deps.add(f.PackagePath, f.TypeName)
+			case *app.ChanNode:
+				deps.add(f.PackagePath, f.TypeName)
+			}
+		}
+	}
+	walkStruct(injector.Root)
+
+	if graph := injector.Graph; graph != nil {
+		for _, provider := range graph.Providers {
+			deps.addFunctions([]app.InitFunctionInfo{provider.Function})
+			deps.addType(provider.Provides)
+			for _, arg := range provider.Args {
+				deps.addType(arg)
+			}
+		}
+		for _, binding := range graph.Bindings {
+			deps.addType(binding.Interface)
+			deps.addType(binding.Impl)
+		}
+		for _, input := range graph.Inputs {
+			deps.addType(input.Type)
+		}
+		for _, field := range graph.StructFields {
+			deps.addType(field.Type)
+		}
+		for _, fieldsOf := range graph.FieldsOf {
+			deps.addType(fieldsOf.Struct)
+		}
+		for _, value := range graph.Values {
+			deps.addType(value.Type)
+		}
+	}
+
+	return deps
+}
+
+// add はパッケージと型名を追加する（名前のない型は除く）
+func (d *dependencies) add(pkgPath, typeName string) {
+	if pkgPath == "" {
+		return
+	}
+	d.packages[pkgPath] = true
+	if typeName != "" {
+		d.typeNames[typeName] = true
+	}
+}
+
+// addType はTypeRefのパッケージと型名を追加する
+func (d *dependencies) addType(ref app.TypeRef) {
+	d.add(ref.PackagePath, ref.TypeName)
+}
+
+// addFunctions は提供関数とその引数の型のパッケージを追加する
+func (d *dependencies) addFunctions(functions []app.InitFunctionInfo) {
+	for _, fn := range functions {
+		d.packages[fn.PackagePath] = true
+		for _, param := range fn.Params {
+			d.add(param.PackagePath, param.TypeName)
+		}
+	}
+}
+
+// declaresProviderFor は変更されたファイルが依存している型を返す関数を宣言しているかを判定する
+// 依存していないパッケージに追加された提供関数も解析結果を変えうるため、関数の返り値の型名で判定する
+func (d *dependencies) declaresProviderFor(filePath string) bool {
+	file, err := parser.ParseFile(token.NewFileSet(), filePath, nil, parser.SkipObjectResolution)
+	if err != nil {
+		// 削除されたファイルや編集途中のファイルは判定できない
+		return false
+	}
+
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Recv != nil || funcDecl.Type.Results == nil {
+			continue
+		}
+		for _, result := range funcDecl.Type.Results.List {
+			if name := baseTypeName(result.Type); name != "" && d.typeNames[name] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// baseTypeName はポインタやパッケージ名を除いた型名を返す（例: *repo.UserRepository -> UserRepository）
+func baseTypeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return baseTypeName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package watch
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fileState はポーリングで変更を検出するためのファイルの状態
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshot はモジュール内のGoファイルの状態（パス -> 状態）
+type snapshot map[string]fileState
+
+// scan はモジュールのディレクトリからGoファイルの状態を集める
+// テストファイル、testdata・vendor・隠しディレクトリ、入れ子のモジュールは依存関係に影響しないため除く
+func scan(moduleDir string) (snapshot, error) {
+	files := make(snapshot)
+	err := filepath.WalkDir(moduleDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 走査中に削除されたファイルは無視する
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		name := d.Name()
+		if d.IsDir() {
+			if filePath == moduleDir {
+				return nil
+			}
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor" {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(filePath, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		files[filePath] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// changes は前回の状態から追加・変更・削除されたファイルをパス順に返す
+func (s snapshot) changes(next snapshot) []string {
+	var changed []string
+	for filePath, state := range next {
+		if prev, ok := s[filePath]; !ok || prev != state {
+			changed = append(changed, filePath)
+		}
+	}
+	for filePath := range s {
+		if _, ok := next[filePath]; !ok {
+			changed = append(changed, filePath)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,415 @@
+// Package watch はモジュールのGoファイルの変更を監視し、影響を受けた注入関数だけを解析し直して
+// wire.goやwire_gen.goの更新が必要かどうかを報告する（必要に応じて書き換える）
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+	"github.com/rmocchy/convinient_wire/ast_analyzer/packages"
+	"github.com/rmocchy/convinient_wire/generator"
+	gopkgs "golang.org/x/tools/go/packages"
+)
+
+// DefaultInterval はファイルの変更を確認する間隔の既定値
+const DefaultInterval = 500 * time.Millisecond
+
+// Watcher はファイルの変更を監視して解析結果を更新する
+type Watcher struct {
+	workDir       string
+	wireFilePath  string
+	searchPattern string
+	out           io.Writer
+	interval      time.Duration
+	fix           bool         // wire.goとwire_gen.goを書き換えるかどうか
+	analyzerOpts  []app.Option // WireAnalyzerに渡すオプション
+
+	moduleDir  string
+	modulePath string
+	files      snapshot
+	analyzer   *app.WireAnalyzer
+	injectors  []*app.InjectorInfo      // wire.goでの宣言順
+	deps       map[string]*dependencies // 注入関数名 -> 依存しているパッケージと型
+}
+
+// Option はWatcherの設定を変更する
+type Option func(*Watcher)
+
+// WithInterval はファイルの変更を確認する間隔を設定する
+func WithInterval(interval time.Duration) Option {
+	return func(w *Watcher) {
+		if interval > 0 {
+			w.interval = interval
+		}
+	}
+}
+
+// WithFix は更新が必要な場合にwire.goのwire.Buildとwire_gen.goを書き換えるようにする
+func WithFix(fix bool) Option {
+	return func(w *Watcher) {
+		w.fix = fix
+	}
+}
+
+// WithAnalyzerOptions は解析に使うWireAnalyzerのオプションを設定する
+func WithAnalyzerOptions(opts ...app.Option) Option {
+	return func(w *Watcher) {
+		w.analyzerOpts = append(w.analyzerOpts, opts...)
+	}
+}
+
+// New はwire.goと提供関数を探すパッケージパターンを指定してWatcherを作成する
+func New(workDir, wireFilePath, searchPattern string, out io.Writer, opts ...Option) *Watcher {
+	w := &Watcher{
+		workDir:       workDir,
+		wireFilePath:  wireFilePath,
+		searchPattern: searchPattern,
+		out:           out,
+		interval:      DefaultInterval,
+		deps:          make(map[string]*dependencies),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Run は最初に全ての注入関数を解析して報告し、その後はcontextがキャンセルされるまで変更を監視する
+// 解析の途中でキャンセルされた場合は解析を中断して終了する
+func (w *Watcher) Run(ctx context.Context) error {
+	if err := w.start(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := w.poll(ctx); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				// 編集途中で解析できない場合も監視は続ける
+				fmt.Fprintf(w.out, "error: %v\n", err)
+			}
+		}
+	}
+}
+
+// Start はモジュールを特定し、全ての注入関数を解析して報告する
+func (w *Watcher) Start() error {
+	return w.start(context.Background())
+}
+
+// start はctxが取り消されると解析を中断するStart
+func (w *Watcher) start(ctx context.Context) error {
+	moduleDir, modulePath, err := resolveModule(filepath.Dir(w.wireFilePath))
+	if err != nil {
+		return err
+	}
+	w.moduleDir, w.modulePath = moduleDir, modulePath
+
+	if w.files, err = scan(moduleDir); err != nil {
+		return fmt.Errorf("failed to scan %s: %w", moduleDir, err)
+	}
+
+	names, err := w.reanalyze(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return w.report(names)
+}
+
+// Poll は前回から変更されたファイルを調べ、影響を受けた注入関数だけを解析し直して報告する
+func (w *Watcher) Poll() error {
+	return w.poll(context.Background())
+}
+
+// poll はctxが取り消されると解析を中断するPoll
+func (w *Watcher) poll(ctx context.Context) error {
+	files, err := scan(w.moduleDir)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", w.moduleDir, err)
+	}
+	changed := w.files.changes(files)
+	if len(changed) == 0 {
+		return nil
+	}
+	w.files = files
+
+	rel := make([]string, 0, len(changed))
+	for _, filePath := range changed {
+		rel = append(rel, w.relPath(filePath))
+	}
+	fmt.Fprintf(w.out, "changed: %s\n", strings.Join(rel, ", "))
+
+	affected, reanalyze := w.affected(changed)
+	if len(affected) == 0 && !reanalyze {
+		fmt.Fprintln(w.out, "no injectors affected")
+		return nil
+	}
+
+	if reanalyze {
+		// wire.goが変わった場合は注入関数の増減もあるため全て解析し直す
+		affected = nil
+	}
+	names, err := w.reanalyze(ctx, affected)
+	if err != nil {
+		return err
+	}
+	return w.report(names)
+}
+
+// affected は変更されたファイルの影響を受けた注入関数を返す
+// wire.goが変更された場合は全ての注入関数を解析し直す必要があるためreanalyzeをtrueにする
+func (w *Watcher) affected(changed []string) (names []string, reanalyze bool) {
+	wireFilePath, _ := filepath.Abs(w.wireFilePath)
+	wireGenPath := filepath.Join(filepath.Dir(wireFilePath), generator.WireGenFileName)
+
+	affected := make(map[string]bool)
+	for _, filePath := range changed {
+		switch filePath {
+		case wireFilePath:
+			return nil, true
+		case wireGenPath:
+			// wire_gen.goは解析に使わないため、報告だけやり直す
+			for _, injector := range w.injectors {
+				affected[injector.Name] = true
+			}
+			continue
+		}
+
+		pkgPath := w.packagePath(filePath)
+		for _, injector := range w.injectors {
+			deps := w.deps[injector.Name]
+			if deps.packages[pkgPath] || deps.declaresProviderFor(filePath) {
+				affected[injector.Name] = true
+			}
+		}
+	}
+
+	for _, injector := range w.injectors {
+		if affected[injector.Name] {
+			names = append(names, injector.Name)
+		}
+	}
+	return names, false
+}
+
+// reanalyze は注入関数を解析し直す（namesがnilの場合は全ての注入関数）
+// 解析し直した注入関数の名前を返す
+func (w *Watcher) reanalyze(ctx context.Context, names []string) ([]string, error) {
+	// 提供関数の変更を反映するため、パッケージを読み込み直す
+	analyzer := app.NewWireAnalyzer(w.workDir, w.searchPattern, w.analyzerOpts...)
+
+	var injectors []*app.InjectorInfo
+	var err error
+	if names == nil {
+		injectors, err = analyzer.AnalyzeInjectorsContext(ctx, w.wireFilePath)
+	} else {
+		injectors, err = analyzer.AnalyzeInjectorsByNameContext(ctx, w.wireFilePath, names...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.analyzer = analyzer
+
+	if names == nil {
+		w.injectors = injectors
+		clear(w.deps)
+	} else {
+		// 影響を受けなかった注入関数は前回の解析結果を使う
+		byName := make(map[string]*app.InjectorInfo, len(injectors))
+		for _, injector := range injectors {
+			byName[injector.Name] = injector
+		}
+		for i, injector := range w.injectors {
+			if updated, ok := byName[injector.Name]; ok {
+				w.injectors[i] = updated
+			}
+		}
+	}
+
+	analyzed := make([]string, 0, len(injectors))
+	for _, injector := range injectors {
+		w.deps[injector.Name] = collectDependencies(injector)
+		analyzed = append(analyzed, injector.Name)
+	}
+	return analyzed, nil
+}
+
+// report は解析し直した注入関数について、wire.goとwire_gen.goの更新が必要かどうかを報告する
+func (w *Watcher) report(names []string) error {
+	targets := make(map[string]bool, len(names))
+	for _, name := range names {
+		targets[name] = true
+	}
+
+	// 解析できなかった注入関数はwire.goやwire_gen.goと比較できない
+	var injectors []*app.InjectorInfo
+	for _, injector := range w.injectors {
+		if injector.Graph != nil {
+			injectors = append(injectors, injector)
+			continue
+		}
+		if targets[injector.Name] {
+			fmt.Fprintf(w.out, "%s: skipped: %s\n", injector.Name, injector.SkipReason())
+		}
+	}
+	if len(injectors) == 0 {
+		return nil
+	}
+
+	// wire_gen.goは全ての注入関数をまとめて比較・生成するため、解析できなかった注入関数があれば触らない
+	wireGenPath := filepath.Join(filepath.Dir(w.wireFilePath), generator.WireGenFileName)
+	_, err := os.Stat(wireGenPath)
+	hasWireGen := err == nil
+	if hasWireGen && len(injectors) < len(w.injectors) {
+		fmt.Fprintf(w.out, "%s: skipped: %d injectors could not be analyzed\n",
+			generator.WireGenFileName, len(w.injectors)-len(injectors))
+		hasWireGen = false
+	}
+
+	buildDiffs, err := generator.VerifyWireBuild(w.wireFilePath, w.analyzer, injectors)
+	if err != nil {
+		return err
+	}
+	var genDiffs []generator.Difference
+	if hasWireGen {
+		if genDiffs, err = generator.VerifyWireGen(w.wireFilePath, wireGenPath, w.analyzer, injectors); err != nil {
+			return err
+		}
+	}
+
+	outdated := make(map[string]bool)
+	for _, diff := range buildDiffs {
+		if targets[diff.Injector] {
+			fmt.Fprintf(w.out, "%s: %s\n", filepath.Base(w.wireFilePath), diff)
+			outdated[diff.Injector] = true
+		}
+	}
+	for _, diff := range genDiffs {
+		if targets[diff.Injector] {
+			fmt.Fprintf(w.out, "%s: %s\n", generator.WireGenFileName, diff)
+			outdated[diff.Injector] = true
+		}
+	}
+	for _, injector := range injectors {
+		if targets[injector.Name] && !outdated[injector.Name] {
+			fmt.Fprintf(w.out, "%s: up to date\n", injector.Name)
+		}
+	}
+
+	if !w.fix || len(outdated) == 0 {
+		return nil
+	}
+	return w.write(injectors, buildDiffs, hasWireGen)
+}
+
+// write はwire.goのwire.Buildを解析結果で書き換え、wire_gen.goを生成し直す
+func (w *Watcher) write(injectors []*app.InjectorInfo, buildDiffs []generator.Difference, hasWireGen bool) error {
+	if len(buildDiffs) > 0 {
+		outdated := make(map[string]bool)
+		for _, diff := range buildDiffs {
+			outdated[diff.Injector] = true
+		}
+		var rewrite []*app.InjectorInfo
+		for _, injector := range injectors {
+			if outdated[injector.Name] {
+				rewrite = append(rewrite, injector)
+			}
+		}
+
+		sets, err := generator.FillInjectorSets(w.wireFilePath, rewrite)
+		if err != nil {
+			return err
+		}
+		// 他のパッケージのプロバイダーセットは展開せずに残す
+		if err := generator.PreserveProviderSets(w.wireFilePath, w.analyzer, sets); err != nil {
+			return err
+		}
+		if len(sets.Injectors) > 0 {
+			src, err := generator.RewriteWireFile(w.wireFilePath, sets)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(w.wireFilePath, src, 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", w.wireFilePath, err)
+			}
+			fmt.Fprintf(w.out, "wrote %s\n", w.wireFilePath)
+		}
+	}
+
+	if hasWireGen {
+		wireGenPath := filepath.Join(filepath.Dir(w.wireFilePath), generator.WireGenFileName)
+		src, err := generator.GenerateWireGen(w.wireFilePath, w.analyzer, injectors)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(wireGenPath, src, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", wireGenPath, err)
+		}
+		fmt.Fprintf(w.out, "wrote %s\n", wireGenPath)
+	}
+
+	// 書き換えたファイルを次の変更として検出しない
+	files, err := scan(w.moduleDir)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", w.moduleDir, err)
+	}
+	w.files = files
+	return nil
+}
+
+// packagePath はファイルが属するパッケージのパスをモジュール内の位置から求める
+func (w *Watcher) packagePath(filePath string) string {
+	rel, err := filepath.Rel(w.moduleDir, filepath.Dir(filePath))
+	if err != nil || rel == "." {
+		return w.modulePath
+	}
+	return w.modulePath + "/" + filepath.ToSlash(rel)
+}
+
+// relPath はモジュールのディレクトリからの相対パスを返す
+func (w *Watcher) relPath(filePath string) string {
+	rel, err := filepath.Rel(w.moduleDir, filePath)
+	if err != nil {
+		return filePath
+	}
+	return filepath.ToSlash(rel)
+}
+
+// resolveModule はディレクトリが属するモジュールのディレクトリとモジュールパスを求める
+func resolveModule(dir string) (moduleDir, modulePath string, err error) {
+	cfg := &gopkgs.Config{
+		Mode:       gopkgs.NeedName | gopkgs.NeedModule,
+		Dir:        dir,
+		BuildFlags: packages.BuildFlags(),
+	}
+	pkgs, err := gopkgs.Load(cfg, ".")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load package in %s: %w", dir, err)
+	}
+	if len(pkgs) == 0 || pkgs[0].Module == nil {
+		return "", "", fmt.Errorf("no module found for %s", dir)
+	}
+
+	// 変更されたファイルのパスと比較できるよう絶対パスにする
+	moduleDir, err = filepath.Abs(pkgs[0].Module.Dir)
+	if err != nil {
+		return "", "", err
+	}
+	return moduleDir, pkgs[0].Module.Path, nil
+}
@@ -0,0 +1,200 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rmocchy/convinient_wire/ast_analyzer/app"
+	"github.com/rmocchy/convinient_wire/generator"
+	"github.com/rmocchy/convinient_wire/internal/testutil"
+)
+
+// writeFile はモジュール内のファイルを書き込む
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	filePath := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// assertOutput は出力に期待する行が含まれているかを確認し、出力を空にする
+func assertOutput(t *testing.T, out *bytes.Buffer, want ...string) {
+	t.Helper()
+	for _, line := range want {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("output does not contain %q:\n%s", line, out.String())
+		}
+	}
+	out.Reset()
+}
+
+func TestWatcher(t *testing.T) {
+	dir := testutil.CopyFixture(t, "lsp")
+
+	var out bytes.Buffer
+	w := New(dir, filepath.Join(dir, "wire.go"), "./...", &out)
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	assertOutput(t, &out,
+		"wire.go: InitializeApp: missing repo.NewSQLUserRepository (not in wire.Build)",
+		`wire.go: InitializeApp: missing wire.Struct(new(App), "*") (not in wire.Build)`,
+	)
+
+	// 変更がなければ何も出力しない
+	if err := w.Poll(); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("output without changes = %q", out.String())
+	}
+
+	// 依存していないパッケージの変更では解析し直さない
+	writeFile(t, dir, "other/other.go", "package other\n\nfunc Hello() string { return \"hello\" }\n")
+	if err := w.Poll(); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	assertOutput(t, &out, "changed: other/other.go", "no injectors affected")
+
+	// 提供関数に引数を追加すると、wire.goに新しい提供関数が必要になる
+	writeFile(t, dir, "service/service.go", `package service
+
+import "example.com/lsp/repo"
+
+// Clock は現在時刻を返す
+type Clock struct{}
+
+// NewClock はClockを作成する
+func NewClock() *Clock {
+	return &Clock{}
+}
+
+// UserService はユーザーに関する処理を行う
+type UserService struct {
+	users repo.UserRepository
+	clock *Clock
+}
+
+// NewUserService はUserServiceを作成する
+func NewUserService(users repo.UserRepository, clock *Clock) *UserService {
+	return &UserService{users: users, clock: clock}
+}
+`)
+	if err := w.Poll(); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	assertOutput(t, &out,
+		"changed: service/service.go",
+		"wire.go: InitializeApp: missing service.NewClock (not in wire.Build)",
+	)
+
+	// 依存していないパッケージに依存している型の提供関数が追加された場合は解析し直す
+	writeFile(t, dir, "other/other.go", "package other\n\nimport \"example.com/lsp/service\"\n\nfunc NewClock() *service.Clock { return nil }\n")
+	if err := w.Poll(); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if strings.Contains(out.String(), "no injectors affected") {
+		t.Errorf("provider added in other package was ignored:\n%s", out.String())
+	}
+}
+
+func TestWatcher_Fix(t *testing.T) {
+	dir := testutil.CopyFixture(t, "lsp")
+	wireFilePath := filepath.Join(dir, "wire.go")
+
+	var out bytes.Buffer
+	w := New(dir, wireFilePath, "./...", &out, WithFix(true))
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	assertOutput(t, &out, "wrote "+wireFilePath)
+
+	src, err := os.ReadFile(wireFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "repo.NewSQLUserRepository,") {
+		t.Errorf("wire.go was not rewritten:\n%s", src)
+	}
+
+	// 書き換えたwire.goは変更として検出しない
+	if err := w.Poll(); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("output after fix = %q", out.String())
+	}
+
+	// 書き換えた後は最新の状態になる
+	if err := New(dir, wireFilePath, "./...", &out).Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	assertOutput(t, &out, "InitializeApp: up to date")
+}
+
+func TestWatcher_Fix_UnanalyzableInjector(t *testing.T) {
+	dir := testutil.CopyFixture(t, "multi")
+	wireFilePath := filepath.Join(dir, "wire.go")
+	wireGenPath := filepath.Join(dir, generator.WireGenFileName)
+
+	analyzer := app.NewWireAnalyzer(dir, "./...")
+	injectors, err := analyzer.AnalyzeInjectors(wireFilePath)
+	if err != nil {
+		t.Fatalf("AnalyzeInjectors failed: %v", err)
+	}
+	generated, err := generator.GenerateWireGen(wireFilePath, analyzer, injectors)
+	if err != nil {
+		t.Fatalf("GenerateWireGen failed: %v", err)
+	}
+	writeFile(t, dir, generator.WireGenFileName, string(generated))
+
+	// 型検査に失敗するパッケージを使う注入関数は解析できない
+	src, err := os.ReadFile(filepath.Join(dir, "worker", "worker.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "worker/worker.go", string(src)+"\nfunc broken() int { return \"x\" }\n")
+
+	var out bytes.Buffer
+	w := New(dir, wireFilePath, "./...", &out, WithFix(true))
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// 解析できた注入関数だけでwire_gen.goを比較・生成しない
+	if !strings.Contains(out.String(), "InitializeWorker: skipped: ") {
+		t.Errorf("output does not report InitializeWorker as skipped:\n%s", out.String())
+	}
+	assertOutput(t, &out, generator.WireGenFileName+": skipped: 1 injectors could not be analyzed")
+	got, err := os.ReadFile(wireGenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(generated) {
+		t.Errorf("wire_gen.go was rewritten:\n%s", got)
+	}
+}
+
+func TestWatcher_Run_Cancelled(t *testing.T) {
+	dir := testutil.CopyFixture(t, "lsp")
+
+	// 最初の解析の前にキャンセルされた場合は何も報告せずに終了する
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out bytes.Buffer
+	if err := New(dir, filepath.Join(dir, "wire.go"), "./...", &out).Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("output after cancellation = %q", out.String())
+	}
+}